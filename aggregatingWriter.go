@@ -0,0 +1,272 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// AggregateSummaryFormat is the format of summaries written by
+	// AggregatingWriter, with count, interval and the message repeated
+	AggregateSummaryFormat = "[x%d in last %s] %s"
+
+	// DefaultAggregateInterval is the default interval summaries are written
+	DefaultAggregateInterval = 5 * time.Second
+)
+
+// aggregateKey identifies repeated messages, by format for formatted ones
+type aggregateKey struct {
+	level  LevelType
+	format string
+}
+
+// aggregated is a message repeated in the current interval
+type aggregated struct {
+	key aggregateKey
+	// the first message written
+	message string
+	count   int
+}
+
+// AggregatingWriter wraps a writer, the first message of the same level and
+// format in every interval is written at once, repeats are counted and a
+// summary like "[x42 in last 5s] connection refused" is written at the end
+// of the interval instead. Messages written with args only are identified
+// by the message.
+type AggregatingWriter struct {
+	writerWrapper
+
+	interval time.Duration
+
+	// messages of the current interval in order they are first written
+	messages []*aggregated
+	index    map[aggregateKey]*aggregated
+	closed   bool
+
+	// closed to stop daemon
+	stop chan struct{}
+	// closed when daemon exits
+	done chan struct{}
+
+	lock *sync.Mutex
+}
+
+// NewAggregatingWriter create an AggregatingWriter wrapping writer,
+// summaries are written every flushInterval, DefaultAggregateInterval if
+// not positive
+func NewAggregatingWriter(writer Writer, flushInterval time.Duration) *AggregatingWriter {
+	if flushInterval <= 0 {
+		flushInterval = DefaultAggregateInterval
+	}
+
+	aggregating := new(AggregatingWriter)
+	aggregating.Writer = writer
+	aggregating.self = aggregating
+	aggregating.interval = flushInterval
+	aggregating.index = make(map[aggregateKey]*aggregated)
+	aggregating.stop = make(chan struct{})
+	aggregating.done = make(chan struct{})
+	aggregating.lock = new(sync.Mutex)
+
+	go aggregating.daemon()
+	return aggregating
+}
+
+// daemon writes summaries every interval until Close
+func (aggregating *AggregatingWriter) daemon() {
+	defer close(aggregating.done)
+
+	ticker := time.NewTicker(aggregating.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			aggregating.summarize()
+		case <-aggregating.stop:
+			aggregating.summarize()
+			return
+		}
+	}
+}
+
+// summarize writes a summary for every message repeated in the current
+// interval, and starts a new interval
+func (aggregating *AggregatingWriter) summarize() {
+	aggregating.lock.Lock()
+	messages := aggregating.messages
+	aggregating.messages = nil
+	aggregating.index = make(map[aggregateKey]*aggregated)
+	aggregating.lock.Unlock()
+
+	for _, message := range messages {
+		if message.count > 1 {
+			aggregating.Writer.write(message.key.level, fmt.Sprintf(AggregateSummaryFormat, message.count, aggregating.interval, message.message))
+		}
+	}
+}
+
+// first counts message, true is returned if it is the first one of key in
+// the current interval
+func (aggregating *AggregatingWriter) first(key aggregateKey, message func() string) bool {
+	aggregating.lock.Lock()
+	defer aggregating.lock.Unlock()
+
+	if aggregating.closed {
+		return false
+	}
+
+	if repeated, ok := aggregating.index[key]; ok {
+		repeated.count++
+		return false
+	}
+
+	added := &aggregated{key: key, message: message(), count: 1}
+	aggregating.index[key] = added
+	aggregating.messages = append(aggregating.messages, added)
+	return true
+}
+
+// Close writes summaries of the current interval, then closes the writer
+// wrapped
+func (aggregating *AggregatingWriter) Close() {
+	aggregating.lock.Lock()
+	if aggregating.closed {
+		aggregating.lock.Unlock()
+		return
+	}
+	aggregating.closed = true
+	aggregating.lock.Unlock()
+
+	close(aggregating.stop)
+	<-aggregating.done
+	aggregating.Writer.Close()
+}
+
+func (aggregating *AggregatingWriter) write(level LevelType, args ...interface{}) {
+	message := fmt.Sprint(args...)
+	if aggregating.first(aggregateKey{level, message}, func() string { return message }) {
+		aggregating.Writer.write(level, message)
+	}
+}
+
+func (aggregating *AggregatingWriter) writef(level LevelType, format string, args ...interface{}) {
+	var message string
+	if aggregating.first(aggregateKey{level, format}, func() string {
+		message = fmt.Sprintf(format, args...)
+		return message
+	}) {
+		aggregating.Writer.write(level, message)
+	}
+}
+
+// Trace trace
+func (aggregating *AggregatingWriter) Trace(args ...interface{}) {
+	if TRACE < aggregating.Level() {
+		return
+	}
+
+	aggregating.write(TRACE, args...)
+}
+
+// Tracef tracef
+func (aggregating *AggregatingWriter) Tracef(format string, args ...interface{}) {
+	if TRACE < aggregating.Level() {
+		return
+	}
+
+	aggregating.writef(TRACE, format, args...)
+}
+
+// Debug debug
+func (aggregating *AggregatingWriter) Debug(args ...interface{}) {
+	if DEBUG < aggregating.Level() {
+		return
+	}
+
+	aggregating.write(DEBUG, args...)
+}
+
+// Debugf debugf
+func (aggregating *AggregatingWriter) Debugf(format string, args ...interface{}) {
+	if DEBUG < aggregating.Level() {
+		return
+	}
+
+	aggregating.writef(DEBUG, format, args...)
+}
+
+// Info info
+func (aggregating *AggregatingWriter) Info(args ...interface{}) {
+	if INFO < aggregating.Level() {
+		return
+	}
+
+	aggregating.write(INFO, args...)
+}
+
+// Infof infof
+func (aggregating *AggregatingWriter) Infof(format string, args ...interface{}) {
+	if INFO < aggregating.Level() {
+		return
+	}
+
+	aggregating.writef(INFO, format, args...)
+}
+
+// Warn warn
+func (aggregating *AggregatingWriter) Warn(args ...interface{}) {
+	if WARNING < aggregating.Level() {
+		return
+	}
+
+	aggregating.write(WARNING, args...)
+}
+
+// Warnf warnf
+func (aggregating *AggregatingWriter) Warnf(format string, args ...interface{}) {
+	if WARNING < aggregating.Level() {
+		return
+	}
+
+	aggregating.writef(WARNING, format, args...)
+}
+
+// Error error
+func (aggregating *AggregatingWriter) Error(args ...interface{}) {
+	if ERROR < aggregating.Level() {
+		return
+	}
+
+	aggregating.write(ERROR, args...)
+}
+
+// Errorf errorf
+func (aggregating *AggregatingWriter) Errorf(format string, args ...interface{}) {
+	if ERROR < aggregating.Level() {
+		return
+	}
+
+	aggregating.writef(ERROR, format, args...)
+}
+
+// Critical critical
+func (aggregating *AggregatingWriter) Critical(args ...interface{}) {
+	if CRITICAL < aggregating.Level() {
+		return
+	}
+
+	aggregating.write(CRITICAL, args...)
+}
+
+// Criticalf criticalf
+func (aggregating *AggregatingWriter) Criticalf(format string, args ...interface{}) {
+	if CRITICAL < aggregating.Level() {
+		return
+	}
+
+	aggregating.writef(CRITICAL, format, args...)
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAggregatingWriter(t *testing.T) {
+	writer, err := newRingBufferWriter(10)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	aggregating := NewAggregatingWriter(writer, time.Hour)
+	for i := 0; i < 42; i++ {
+		aggregating.Errorf("connection refused, retry %d", i)
+	}
+	aggregating.Error("once")
+	aggregating.Warnf("connection refused, retry %d", 0)
+	aggregating.Warn("twice")
+	aggregating.Warn("twice")
+
+	if 4 != len(writer.Entries()) {
+		t.Fatalf("repeated messages should be written once. entries: %v", writer.Lines())
+	}
+
+	aggregating.Close()
+	if !writer.Closed() {
+		t.Error("writer wrapped should be closed")
+	}
+
+	entries := writer.Entries()
+	expected := []string{
+		"connection refused, retry 0",
+		"once",
+		"connection refused, retry 0",
+		"twice",
+		"[x42 in last 1h0m0s] connection refused, retry 0",
+		"[x2 in last 1h0m0s] twice",
+	}
+	if len(expected) != len(entries) {
+		t.Fatalf("entries written wrong. entries: %v", writer.Lines())
+	}
+	for i, entry := range entries {
+		if expected[i] != entry.Message {
+			t.Errorf("entry written wrong. expected: %s, message: %s", expected[i], entry.Message)
+		}
+	}
+	if ERROR != entries[4].Level || WARNING != entries[5].Level {
+		t.Errorf("level of summaries wrong. entries: %v", writer.Lines())
+	}
+
+	aggregating.Error("closed")
+	if 6 != len(writer.Entries()) {
+		t.Error("messages should be dropped after closed")
+	}
+}
+
+func TestAggregatingWriterInterval(t *testing.T) {
+	writer, err := newRingBufferWriter(10)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	aggregating := NewAggregatingWriter(writer, 50*time.Millisecond)
+	defer aggregating.Close()
+
+	aggregating.Info("tick")
+	aggregating.Info("tick")
+	time.Sleep(80 * time.Millisecond)
+
+	// a new interval started
+	aggregating.Info("tick")
+	entries := writer.Entries()
+	if 3 != len(entries) || "[x2 in last 50ms] tick" != entries[1].Message || "tick" != entries[2].Message {
+		t.Errorf("summary of interval wrong. entries: %v", writer.Lines())
+	}
+}
+
+func TestAggregatingWriterDefaultInterval(t *testing.T) {
+	writer, err := newRingBufferWriter(10)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	aggregating := NewAggregatingWriter(writer, 0)
+	defer aggregating.Close()
+	if DefaultAggregateInterval != aggregating.interval {
+		t.Errorf("interval not positive should be default. interval: %s", aggregating.interval)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"sync"
+	"time"
+)
+
+// AlertFunc is called when count messages exceed level are written within
+// window
+type AlertFunc func(level LevelType, count int)
+
+// alerter keeps time of the last count messages exceed level in a sliding
+// window. Alert is raised once the oldest one is within window, then
+// suppressed for another window.
+type alerter struct {
+	level  LevelType
+	count  int
+	window time.Duration
+	fn     AlertFunc
+
+	// circular buffer of time messages written
+	times []time.Time
+	// position of the oldest time
+	next int
+	// number of times kept
+	n int
+
+	// alert is suppressed until
+	suppressed time.Time
+
+	lock *sync.Mutex
+}
+
+// newAlerter create an alerter
+func newAlerter(level LevelType, count int, window time.Duration, fn AlertFunc) *alerter {
+	alert := new(alerter)
+	alert.level = level
+	alert.count = count
+	alert.window = window
+	alert.fn = fn
+	alert.times = make([]time.Time, count)
+	alert.lock = new(sync.Mutex)
+	return alert
+}
+
+// add records a message with level written at t
+func (alert *alerter) add(level LevelType, t time.Time) {
+	if level < alert.level {
+		return
+	}
+
+	alert.lock.Lock()
+	defer alert.lock.Unlock()
+
+	alert.times[alert.next] = t
+	alert.next = (alert.next + 1) % len(alert.times)
+	if alert.n < len(alert.times) {
+		alert.n++
+	}
+}
+
+// check calls fn if count messages are written within window before now
+// and alert is not suppressed
+func (alert *alerter) check(now time.Time) {
+	alert.lock.Lock()
+	if alert.n < len(alert.times) || now.Before(alert.suppressed) ||
+		now.Sub(alert.times[alert.next]) > alert.window {
+		alert.lock.Unlock()
+		return
+	}
+
+	alert.suppressed = now.Add(alert.window)
+	alert.n = 0
+	alert.lock.Unlock()
+
+	alert.fn(alert.level, alert.count)
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestAlerter(t *testing.T) {
+	var alerts int
+	alert := newAlerter(ERROR, 3, time.Minute, func(level LevelType, count int) {
+		if ERROR != level || 3 != count {
+			t.Errorf("alert parameters wrong. level: %s, count: %d", level.String(), count)
+		}
+		alerts++
+	})
+
+	now := time.Now()
+	alert.add(ERROR, now)
+	alert.add(INFO, now)
+	alert.add(CRITICAL, now.Add(30*time.Second))
+	alert.check(now.Add(30 * time.Second))
+	if 0 != alerts {
+		t.Error("alert should not be raised below count")
+	}
+
+	// the oldest one slides out of window
+	alert.add(ERROR, now.Add(90*time.Second))
+	alert.check(now.Add(90 * time.Second))
+	if 0 != alerts {
+		t.Error("alert should not be raised when messages spread over window")
+	}
+
+	alert.add(ERROR, now.Add(100*time.Second))
+	alert.add(ERROR, now.Add(100*time.Second))
+	alert.check(now.Add(100 * time.Second))
+	if 1 != alerts {
+		t.Errorf("alert should be raised. alerts: %d", alerts)
+	}
+
+	// suppressed for another window
+	for i := 0; i < 3; i++ {
+		alert.add(ERROR, now.Add(110*time.Second))
+	}
+	alert.check(now.Add(110 * time.Second))
+	if 1 != alerts {
+		t.Errorf("alert should be suppressed. alerts: %d", alerts)
+	}
+
+	for i := 0; i < 3; i++ {
+		alert.add(ERROR, now.Add(170*time.Second))
+	}
+	alert.check(now.Add(170 * time.Second))
+	if 2 != alerts {
+		t.Errorf("alert should be raised after suppressed. alerts: %d", alerts)
+	}
+}
+
+func TestBaseFileWriterAlertThreshold(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/alert.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/alert.log")
+	}()
+
+	alerted := make(chan int, 10)
+	writer.SetAlertThreshold(ERROR, 2, time.Minute, func(level LevelType, count int) {
+		alerted <- count
+	})
+	writer.SetAlertThreshold(INFO, 0, time.Minute, nil)
+	if 1 != len(writer.alerters()) {
+		t.Fatalf("alerters wrong. alerters: %d", len(writer.alerters()))
+	}
+
+	writer.Error("first")
+	writer.Errorf("%s", "second")
+
+	select {
+	case count := <-alerted:
+		if 2 != count {
+			t.Errorf("alert count wrong. count: %d", count)
+		}
+	case <-time.After(3 * time.Second):
+		t.Error("alert should be raised by daemon")
+	}
+
+	writer.SetAlertThreshold(ERROR, 0, time.Minute, nil)
+	if 0 != len(writer.alerters()) {
+		t.Error("alerter should be removed")
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SetArchiveDir set directory files rotated are moved into after every
+// logrotate, it is created if not exists. Files rotated are never expired
+// by retentions then. Empty dir disables archiving.
+func (writer *baseFileWriter) SetArchiveDir(dir string) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.archiveDir = dir
+}
+
+// archiveDirectory get directory files rotated are moved into, empty if
+// archiving disabled
+func (writer *baseFileWriter) archiveDirectory() string {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.archiveDir
+}
+
+// archive moves the file rotated into archive directory with the same name,
+// time is appended to the name if the file archived exists. Nothing is done
+// if archiving disabled.
+func (writer *baseFileWriter) archive(name string) {
+	dir := writer.archiveDirectory()
+	if "" == dir {
+		return
+	}
+	if err := os.MkdirAll(dir, os.FileMode(0755)); nil != err {
+		return
+	}
+
+	archived := filepath.Join(dir, filepath.Base(name))
+	if _, err := os.Stat(archived); nil == err {
+		archived = archived + "." + time.Now().Format("20060102150405.000000000")
+	}
+
+	if nil == moveFile(name, archived) && nil != writer.integrity {
+		moveFile(name+IntegritySuffix, archived+IntegritySuffix)
+	}
+}
+
+// moveFile renames src to dst, src is copied and removed if rename fails,
+// like dst on another filesystem
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); nil == err {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if nil != err {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, os.FileMode(0644))
+	if nil != err {
+		return err
+	}
+	if _, err = io.Copy(out, in); nil != err {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err = out.Close(); nil != err {
+		os.Remove(dst)
+		return err
+	}
+	return os.Remove(src)
+}
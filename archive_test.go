@@ -0,0 +1,49 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBaseFileWriterArchiveDir(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/archived.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/archived.log")
+		os.RemoveAll("/tmp/archive")
+	}()
+
+	writer.SetRetentions(2)
+	writer.SetArchiveDir("/tmp/archive/blog4go")
+	writer.Info("first")
+	writer.Rotate()
+	writer.Info("second")
+	writer.Rotate()
+	writer.Info("third")
+	writer.flush()
+
+	if _, err = os.Stat("/tmp/archived.log.1"); !os.IsNotExist(err) {
+		t.Error("file rotated should be moved into archive directory")
+	}
+
+	archived, _ := filepath.Glob("/tmp/archive/blog4go/archived.log.1*")
+	if 2 != len(archived) {
+		t.Fatalf("files rotated should be archived without overwritten. archived: %v", archived)
+	}
+	for i, message := range []string{"] first\n", "] second\n"} {
+		if content, err := ioutil.ReadFile(archived[i]); nil != err || !strings.HasSuffix(string(content), message) {
+			t.Errorf("file archived wrong. file: %s, content: %q", archived[i], content)
+		}
+	}
+	if content, err := ioutil.ReadFile("/tmp/archived.log"); nil != err || !strings.HasSuffix(string(content), "] third\n") {
+		t.Errorf("message should be written to the new file. content: %q", content)
+	}
+}
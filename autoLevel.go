@@ -0,0 +1,119 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultAutoLevelCooldown is the default duration level kept lowered
+	// after auto level adjustment activated
+	DefaultAutoLevelCooldown = 1 * time.Minute
+)
+
+// autoLevel counts messages exceed trigger level within window. When count
+// exceeds threshold, level should be lowered to lowerTo for cooldown to
+// capture more context, then restored.
+type autoLevel struct {
+	trigger   LevelType
+	threshold int
+	window    time.Duration
+	lowerTo   LevelType
+	cooldown  time.Duration
+
+	// messages exceed trigger level from windowStart
+	count       int
+	windowStart time.Time
+
+	// sign of level lowered
+	active bool
+	// time when level should be restored
+	until time.Time
+	// level before lowered
+	original LevelType
+
+	lock *sync.Mutex
+}
+
+// newAutoLevel create an autoLevel
+func newAutoLevel(trigger LevelType, threshold int, window time.Duration, lowerTo LevelType, cooldown time.Duration) *autoLevel {
+	auto := new(autoLevel)
+	auto.trigger = trigger
+	auto.threshold = threshold
+	auto.window = window
+	auto.lowerTo = lowerTo
+	auto.cooldown = cooldown
+	auto.windowStart = time.Now()
+	auto.active = false
+	auto.lock = new(sync.Mutex)
+	return auto
+}
+
+// add counts a message with level
+func (auto *autoLevel) add(level LevelType) {
+	if level < auto.trigger {
+		return
+	}
+
+	auto.lock.Lock()
+	defer auto.lock.Unlock()
+	auto.count++
+}
+
+// activate determines whether level should be lowered at now, current is
+// the level in use which will be restored later. It returns message
+// describing the adjustment.
+func (auto *autoLevel) activate(now time.Time, current LevelType) (bool, string) {
+	auto.lock.Lock()
+	defer auto.lock.Unlock()
+
+	if auto.active {
+		return false, ""
+	}
+
+	count := auto.count
+	if now.Sub(auto.windowStart) >= auto.window || count > auto.threshold {
+		auto.count = 0
+		auto.windowStart = now
+	}
+	if count <= auto.threshold {
+		return false, ""
+	}
+
+	auto.active = true
+	auto.until = now.Add(auto.cooldown)
+	auto.original = current
+	return true, fmt.Sprintf("blog4go: %d messages exceed %s within %s, level lowered to %s for %s",
+		count, auto.trigger.String(), auto.window, auto.lowerTo.String(), auto.cooldown)
+}
+
+// restore determines whether level should be restored at now, it returns
+// the level to restore
+func (auto *autoLevel) restore(now time.Time) (bool, LevelType) {
+	auto.lock.Lock()
+	defer auto.lock.Unlock()
+
+	if !auto.active || now.Before(auto.until) {
+		return false, auto.original
+	}
+
+	auto.active = false
+	auto.count = 0
+	auto.windowStart = now
+	return true, auto.original
+}
+
+// lowered get the level lowered to
+func (auto *autoLevel) lowered() LevelType {
+	return auto.lowerTo
+}
+
+// setCooldown set duration level kept lowered
+func (auto *autoLevel) setCooldown(cooldown time.Duration) {
+	auto.lock.Lock()
+	defer auto.lock.Unlock()
+	auto.cooldown = cooldown
+}
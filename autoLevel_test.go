@@ -0,0 +1,93 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAutoLevel(t *testing.T) {
+	auto := newAutoLevel(ERROR, 2, 10*time.Second, DEBUG, time.Minute)
+	now := auto.windowStart
+
+	auto.add(INFO)
+	auto.add(ERROR)
+	auto.add(CRITICAL)
+	if ok, _ := auto.activate(now, INFO); ok {
+		t.Error("auto level should not activate without exceeding threshold")
+	}
+
+	// window passed, counter reset
+	if ok, _ := auto.activate(now.Add(10*time.Second), INFO); ok {
+		t.Error("auto level should not activate after window passed")
+	}
+	auto.add(ERROR)
+	auto.add(ERROR)
+	if ok, _ := auto.activate(now.Add(11*time.Second), INFO); ok {
+		t.Error("counter should be reset after window passed")
+	}
+
+	auto.add(ERROR)
+	ok, message := auto.activate(now.Add(12*time.Second), INFO)
+	if !ok || !strings.Contains(message, "3 messages exceed ERROR") {
+		t.Errorf("auto level should activate exceeding threshold. message: %s", message)
+	}
+	if ok, _ = auto.activate(now.Add(12*time.Second), DEBUG); ok {
+		t.Error("auto level should not activate again while active")
+	}
+
+	if ok, _ := auto.restore(now.Add(30 * time.Second)); ok {
+		t.Error("level should not be restored before cooldown")
+	}
+	if ok, level := auto.restore(now.Add(72 * time.Second)); !ok || INFO != level {
+		t.Errorf("level should be restored after cooldown. level: %s", level.String())
+	}
+}
+
+func TestBaseFileWriterAutoLevel(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/auto_level.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/auto_level.log")
+	}()
+
+	writer.SetLevel(INFO)
+	writer.SetAutoLevelCooldown(time.Minute)
+	writer.SetAutoLevel(ERROR, 1, time.Hour, DEBUG)
+
+	writer.Error("error")
+	writer.Errorf("%s", "error")
+	now := time.Now()
+	writer.adjustLevel(now)
+	if DEBUG != writer.Level() {
+		t.Errorf("level should be lowered. level: %s", writer.Level().String())
+	}
+
+	writer.Debug("context")
+	writer.adjustLevel(now.Add(2 * time.Minute))
+	if INFO != writer.Level() {
+		t.Errorf("level should be restored. level: %s", writer.Level().String())
+	}
+	writer.Debug("filtered")
+	writer.flush()
+
+	content, err := ioutil.ReadFile("/tmp/auto_level.log")
+	if nil != err {
+		t.Fatalf("read log failed. err: %s", err.Error())
+	}
+	if !strings.Contains(string(content), "] blog4go: 2 messages exceed ERROR") || !strings.HasSuffix(string(content), "] context\n") {
+		t.Errorf("log content wrong. content: %q", content)
+	}
+
+	writer.SetAutoLevel(ERROR, 0, time.Hour, DEBUG)
+	if nil != writer.autoLevelAdjust() {
+		t.Error("auto level should be disabled")
+	}
+}
@@ -3,9 +3,15 @@
 package blog4go
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
 	"os"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -29,13 +35,78 @@ const (
 
 	// DefaultLogRetentionCount is the default days of logs to be keeped
 	DefaultLogRetentionCount = 7
+
+	// DefaultQueueCapacity is the default capacity of the queue of sizes
+	// written waiting for daemon to sum up
+	DefaultQueueCapacity = 8192
+
+	// SizeSyncInterval is the interval size counted for size base logrotate
+	// is synced with the actual size of the file
+	SizeSyncInterval = 1 * time.Minute
+
+	// DefaultDaemonInterval is the default interval of daemon ticks
+	DefaultDaemonInterval = 1 * time.Second
+	// MinDaemonInterval is the min interval of daemon ticks, to prevent
+	// busy looping
+	MinDaemonInterval = 1 * time.Millisecond
+
+	// DefaultFileFlag is the flag used when opening log files
+	DefaultFileFlag = os.O_WRONLY | os.O_APPEND | os.O_CREATE
 )
 
+var (
+	// ResetRetryAttempts is the number of attempts opening the new file
+	// while logrotate, messages are discarded until the next successful
+	// reset if all attempts failed
+	ResetRetryAttempts = 10
+	// ResetRetryInitialBackoff is the interval before the second attempt,
+	// doubled for every attempt after
+	ResetRetryInitialBackoff = 10 * time.Millisecond
+	// ResetRetryMaxBackoff is the max interval between attempts
+	ResetRetryMaxBackoff = 5 * time.Second
+
+	// DaemonExitTimeout is the max time Close waits for the daemon of writer
+	// to exit
+	DaemonExitTimeout = 1 * time.Second
+
+	// files of inherited file descriptors are kept referenced here, or the
+	// finalizer of os.File may close the file descriptor after writer closed
+	inheritedFiles     []*os.File
+	inheritedFilesLock sync.Mutex
+)
+
+// RotateNamer return the full path of the file rotated by size or line base
+// logrotate, with base name of the file, count of logrotate starting from 1
+// and time of logrotate
+type RotateNamer func(baseName string, rotateCount int, t time.Time) string
+
+// LogEntry is a message written by WriteBatch
+type LogEntry struct {
+	Level   LevelType
+	Message string
+}
+
+// WriteObserver is called after every write with level, time taken to
+// acquire lock and write, and size written
+type WriteObserver func(level LevelType, latency time.Duration, size int)
+
+// Stats is a snapshot of status of a file writer
+type Stats struct {
+	// full path of the file
+	FileName string
+	// number of writes waiting for daemon to sum up
+	QueueDepth int
+	// capacity of the queue, writes block when the queue is full
+	QueueCapacity int
+}
+
 // baseFileWriter defines a writer for single file.
 // It suppurts partially write while formatting message, logging level filtering,
 // logrotate, user defined hook for every logging action, change configuration
 // on the fly and logging with colors.
 type baseFileWriter struct {
+	writerBase
+
 	// configuration about file
 	// full path of the file, the same as configuration
 	fileName string
@@ -58,10 +129,14 @@ type baseFileWriter struct {
 	hookLevel LevelType
 	// it determines whether hook is called async, default true
 	hookAsync bool
+	// hooks added or removed on the fly, called with hook
+	hooks *HookManager
 
 	// configuration about logrotate
 	// exclusive lock use in logrotate
 	lock *sync.RWMutex
+	// exclusive lock while renaming files in size && line base logrotate
+	rotateLock *sync.Mutex
 
 	// configuration about time base logrotate
 	// sign of time base logrotate, default false
@@ -70,6 +145,12 @@ type baseFileWriter struct {
 	// signal send when time base rotate needed
 	timeRotateSig chan bool
 
+	// interval of daemon ticks refreshing time cache and doing periodic
+	// work like time base logrotate
+	daemonInterval time.Duration
+	// signal send when daemonInterval changed
+	daemonIntervalSig chan struct{}
+
 	// configuration about size && line base logrotate
 	// sign of line base logrotate, default false
 	// set this tag true if logrotate in line base mode
@@ -88,14 +169,146 @@ type baseFileWriter struct {
 	rotateSize int64
 	// total size written after last size && line logrotate
 	currentSize int64
+	// name of file rotated by size && line base logrotate, xxx.1, xxx.2 are
+	// used and shifted if nil
+	rotateNamer RotateNamer
+	// number of size && line base logrotate with rotateNamer, or logrotate
+	// with policy
+	rotateCount int
+	// policy of logrotate, size, line and time base logrotate are replaced
+	// if not nil
+	policy RotationPolicy
+	// time the current file opened
+	opened time.Time
+	// time cache of timestamps written and date of time base logrotate,
+	// freshed by daemon
+	timeCache *timeFormatCacheType
+	// wall clock time of daily logrotate, disabled if nil
+	rotateAt *wallClock
+	// time of the last daily logrotate, zero if never
+	lastRotateAt time.Time
 	// channel used to sum up sizes written from last logrotate
 	logSizeChan chan int
+	// held by writes queueing sizes, exclusively when the queue is swapped
+	queueLock *sync.RWMutex
+	// serializes SetQueueCapacity
+	resizeLock *sync.Mutex
+	// queue swapped, handed to daemon after sizes queued before
+	queueSig chan chan int
+	// closed when daemon exits
+	done chan struct{}
 
 	// number of logs retention when time base logrotate or size base logrotate
 	retentions int64
+	// directory files rotated are moved into, archiving is disabled if empty
+	archiveDir string
+
+	// configuration about snapshot
+	// lines written are collected if not nil
+	snapshot *snapshotBuffer
+	// number of lines kept by snapshot, DefaultSnapshotCapacity if not positive
+	snapshotCapacity int
 
 	// sign decided logging with colors or not, default false
 	colored bool
+
+	// sign of graceful shutdown, default false
+	// buffer is flushed after every write when shutting down
+	shutdown bool
+	// sign of draining, 1 if draining, accessed atomically
+	// new messages are dropped when draining
+	draining int32
+
+	// configuration about suspension
+	// writes hold it shared, Suspend holds it exclusively
+	suspension *sync.RWMutex
+	// exclusive lock of suspended
+	suspendLock *sync.Mutex
+	// sign of suspended, default false
+	suspended bool
+
+	// writes of lines piped hold it shared, Close holds it exclusively
+	pipeLock *sync.RWMutex
+
+	// configuration about integrity check
+	// hmac key, integrity check is enabled if not nil
+	integrityKey []byte
+	// writer signs every line written to the file
+	integrity *integrityWriter
+
+	// configuration about sampling
+	// probability of a message also written to sampler
+	sampleRate float64
+	// writer of the sample file
+	sampler *baseFileWriter
+
+	// configuration about burst capture
+	// burst capture is enabled if not nil
+	burst *burstCapture
+	// interval between resets of burst counters
+	burstInterval time.Duration
+
+	// configuration about time bucket stats
+	// time bucket stats is enabled if not nil
+	bucketStats *timeBucketStats
+	// duration time buckets kept
+	bucketRetention time.Duration
+
+	// level histogram, disabled if nil
+	histogram *LevelHistogram
+
+	// alerters by level, replaced as a whole when changed
+	alerts []*alerter
+
+	// called if the new file can not be opened while logrotate
+	resetErrorHandler func(err error)
+
+	// counters of messages written, exposed by ExposeExpvars
+	counters *writeCounters
+
+	// observer called after every write, nil if not set
+	observer WriteObserver
+
+	// latest samples of write latency, latency tracking is disabled if nil
+	latency *latencyTracker
+
+	// logging level overrides by caller's file, replaced as a whole when
+	// changed
+	callerLevels []callerLevel
+
+	// configuration about auto level adjustment
+	// auto level adjustment is enabled if not nil
+	autoLevel *autoLevel
+	// duration level kept lowered
+	autoLevelCooldown time.Duration
+
+	// configuration about inherited file descriptor
+	// sign of file descriptor passed by supervisor, logrotate is disabled
+	inherited bool
+	// it determines whether file is closed when writer closed, default true,
+	// false if file descriptor inherited
+	closeOnExit bool
+
+	// format of annotation lines, %s is replaced with the annotation
+	annotationFormat string
+
+	// version written in the header line of every new file, header is
+	// disabled if not positive
+	schemaVersion int
+
+	// owner of log files, -1 if not changed
+	uid int
+	gid int
+
+	// sign of append-only log files, default false
+	// every file opened is set append-only if true
+	immutable bool
+
+	// configuration about WriteTo
+	// file copied by the last WriteTo
+	copiedFileName string
+	// position in the file copied by the last WriteTo
+	copiedOffset int64
 }
 
 // NewBaseFileWriter initialize a base file writer
@@ -116,97 +329,282 @@ func NewBaseFileWriter(fileName string, timeRotated bool) (err error) {
 	return err
 }
 
+// NewBaseFileWriterWithFlags initialize a base file writer which opens the
+// log file with given flags, like os.O_TRUNC or os.O_EXCL.
+// Files created by logrotate are always opened with DefaultFileFlag.
+func NewBaseFileWriterWithFlags(fileName string, timeRotated bool, flags int) (err error) {
+	singltonLock.Lock()
+	defer singltonLock.Unlock()
+
+	if nil != blog {
+		return ErrAlreadyInit
+	}
+
+	baseFileWriter, err := newBaseFileWriterWithFlags(fileName, timeRotated, flags)
+	if nil != err {
+		return err
+	}
+
+	blog = baseFileWriter
+	return err
+}
+
+// NewBaseFileWriterFromFD initialize a base file writer writing to the file
+// descriptor already opened, like the one passed by s6 or runit.
+// Logrotate is disabled since the supervisor manages log files, and the file
+// descriptor is not closed when writer closed unless SetCloseOnExit(true).
+func NewBaseFileWriterFromFD(fd int) (err error) {
+	singltonLock.Lock()
+	defer singltonLock.Unlock()
+
+	if nil != blog {
+		return ErrAlreadyInit
+	}
+
+	baseFileWriter, err := newBaseFileWriterFromFD(fd)
+	if nil != err {
+		return err
+	}
+
+	blog = baseFileWriter
+	return err
+}
+
 // newbaseFileWriter create a single file writer instance and return the poionter
 // of it. When any errors happened during creation, a null writer and appropriate
 // will be returned.
 // fileName must be an absolute path to the destination log file
 // rotate determine if it will logrotate
 func newBaseFileWriter(fileName string, timeRotated bool) (fileWriter *baseFileWriter, err error) {
+	return newBaseFileWriterWithFlags(fileName, timeRotated, DefaultFileFlag)
+}
+
+// newBaseFileWriterWithFlags create a single file writer instance opening
+// the log file with given flags
+func newBaseFileWriterWithFlags(fileName string, timeRotated bool, flags int) (fileWriter *baseFileWriter, err error) {
+	fileWriter, err = openBaseFileWriter(fileName, timeRotated, flags)
+	if nil != err {
+		return nil, err
+	}
+
+	fileWriter.start()
+	return fileWriter, nil
+}
+
+// openBaseFileWriter create a single file writer instance without starting
+// its daemon, so that it can be set up before
+func openBaseFileWriter(fileName string, timeRotated bool, flags int) (fileWriter *baseFileWriter, err error) {
 	fileWriter = new(baseFileWriter)
 	fileWriter.fileName = fileName
 	// open file target file
 	if timeRotated {
 		fileName = fmt.Sprintf("%s.%s", fileName, timeCache.Date())
 	}
-	file, err := os.OpenFile(fileName, os.O_WRONLY|os.O_APPEND|os.O_CREATE, os.FileMode(0644))
-	fileWriter.file = file
-	fileWriter.currentFileName = fileName
+	file, err := os.OpenFile(fileName, flags, os.FileMode(0644))
 	if nil != err {
 		return nil, err
 	}
-	fileWriter.blog = NewBLog(file)
 
-	fileWriter.closed = false
+	fileWriter.init(file, fileName, timeRotated)
+	return fileWriter, nil
+}
+
+// newBaseFileWriterFromFD create a single file writer instance writing to
+// the file descriptor inherited
+func newBaseFileWriterFromFD(fd int) (fileWriter *baseFileWriter, err error) {
+	if fd < 0 {
+		return nil, ErrInvalidFD
+	}
+
+	file := os.NewFile(uintptr(fd), "")
+	if nil == file {
+		return nil, ErrInvalidFD
+	}
+	if _, err = file.Stat(); nil != err {
+		return nil, err
+	}
+
+	inheritedFilesLock.Lock()
+	inheritedFiles = append(inheritedFiles, file)
+	inheritedFilesLock.Unlock()
 
-	// about logrotate
-	fileWriter.lock = new(sync.RWMutex)
-	fileWriter.timeRotated = timeRotated
-	fileWriter.timeRotateSig = make(chan bool)
-	fileWriter.sizeRotateSig = make(chan bool)
-	fileWriter.logSizeChan = make(chan int, 8192)
+	fileWriter = new(baseFileWriter)
+	fileWriter.init(file, "", false)
+	fileWriter.inherited = true
+	fileWriter.closeOnExit = false
+	fileWriter.start()
+	return fileWriter, nil
+}
+
+// init initialize the writer writing to file opened
+func (writer *baseFileWriter) init(file *os.File, currentFileName string, timeRotated bool) {
+	writer.self = writer
+	writer.file = file
+	writer.currentFileName = currentFileName
+	writer.opened = time.Now()
+	writer.blog = NewBLog(file)
+	writer.timeCache = newTimeFormatCache()
+	writer.blog.timeCache = writer.timeCache
+	writer.closeOnExit = true
+
+	writer.closed = false
 
-	fileWriter.lineRotated = false
-	fileWriter.rotateSize = DefaultRotateSize
-	fileWriter.currentSize = 0
+	// about logrotate
+	writer.lock = new(sync.RWMutex)
+	writer.rotateLock = new(sync.Mutex)
+	writer.suspension = new(sync.RWMutex)
+	writer.suspendLock = new(sync.Mutex)
+	writer.pipeLock = new(sync.RWMutex)
+	writer.timeRotated = timeRotated
+	writer.timeRotateSig = make(chan bool)
+	writer.sizeRotateSig = make(chan bool)
+	writer.logSizeChan = make(chan int, DefaultQueueCapacity)
+	writer.queueLock = new(sync.RWMutex)
+	writer.resizeLock = new(sync.Mutex)
+	writer.queueSig = make(chan chan int)
+	writer.daemonInterval = DefaultDaemonInterval
+	writer.daemonIntervalSig = make(chan struct{}, 1)
+
+	writer.lineRotated = false
+	writer.rotateSize = DefaultRotateSize
+	writer.currentSize = 0
 
-	fileWriter.sizeRotated = false
-	fileWriter.rotateLines = DefaultRotateLines
-	fileWriter.currentLines = 0
-	fileWriter.retentions = DefaultLogRetentionCount
+	writer.sizeRotated = false
+	writer.rotateLines = DefaultRotateLines
+	writer.currentLines = 0
+	writer.retentions = DefaultLogRetentionCount
 
-	fileWriter.colored = false
+	writer.colored = false
 
 	// log hook
-	fileWriter.hook = nil
-	fileWriter.hookLevel = DEBUG
-	fileWriter.hookAsync = true
+	writer.hook = nil
+	writer.hookLevel = DEBUG
+	writer.hookAsync = true
+	writer.hooks = NewHookManager()
 
-	go fileWriter.daemon()
+	writer.burst = nil
+	writer.burstInterval = DefaultBurstInterval
 
-	return fileWriter, nil
+	writer.bucketStats = nil
+	writer.bucketRetention = DefaultTimeBucketRetention
+
+	writer.histogram = nil
+	writer.alerts = nil
+	writer.counters = new(writeCounters)
+
+	writer.autoLevel = nil
+	writer.autoLevelCooldown = DefaultAutoLevelCooldown
+
+	writer.annotationFormat = DefaultAnnotationFormat
+
+	writer.uid = -1
+	writer.gid = -1
+
+	writer.done = make(chan struct{})
+}
+
+// start starts daemon of writer
+func (writer *baseFileWriter) start() {
+	go writer.daemon(writer.daemonInterval)
 }
 
 // daemon run in background as NewbaseFileWriter called.
-// It flushes writer buffer every 1 second.
+// It flushes writer buffer every interval, 1 second by default.
 // It decides whether a time base when logrotate is needed.
 // It sums up lines && sizes already written. Also it supports the lines &&
 // size base logrotate
-func (writer *baseFileWriter) daemon() {
-	// tick every seconds
-	// time base logrotate
-	t := time.Tick(1 * time.Second)
-	// tick every second
-	// auto flush writer buffer
-	f := time.Tick(1 * time.Second)
+func (writer *baseFileWriter) daemon(interval time.Duration) {
+	defer close(writer.done)
+
+	// tick every daemon interval
+	// auto flush writer buffer && time base logrotate
+	t := time.NewTicker(interval)
+	defer func() {
+		t.Stop()
+	}()
+	// time size counted synced last
+	sizeSynced := time.Now()
+	// queue of sizes written, swapped by SetQueueCapacity
+	queue := writer.sizeQueue()
 
 DaemonLoop:
 	for {
 		select {
-		case <-f:
+		case resized := <-writer.queueSig:
+			// nothing is queued to the old queue any more, sum up what left
+			for 0 != len(queue) {
+				size := <-queue
+				writer.lock.Lock()
+				writer.currentSize += int64(size)
+				writer.currentLines++
+				writer.lock.Unlock()
+			}
+			queue = resized
+		case <-writer.daemonIntervalSig:
+			t.Stop()
+			t = time.NewTicker(writer.DaemonInterval())
+		case <-t.C:
 			if writer.Closed() {
 				break DaemonLoop
 			}
 
 			writer.blog.flush()
-		case <-t:
-			if writer.Closed() {
-				break DaemonLoop
+			writer.timeCache.fresh()
+
+			// reset burst counters
+			if burst := writer.burstCapture(); nil != burst {
+				burst.sweep(time.Now())
+			}
+
+			// prune expired time buckets
+			if stats := writer.timeBucketStats(); nil != stats {
+				stats.sweep(time.Now())
+			}
+
+			// correct size counted drifting from the file
+			if writer.rotationCounted() && time.Since(sizeSynced) >= SizeSyncInterval {
+				writer.syncSize()
+				sizeSynced = time.Now()
+			}
+
+			// raise alerts
+			for _, alert := range writer.alerters() {
+				alert.check(time.Now())
+			}
+
+			// advance level histogram
+			if histogram := writer.levelHistogram(); nil != histogram {
+				histogram.advance(time.Now())
+			}
+
+			writer.adjustLevel(time.Now())
+
+			// daily logrotate at wall clock time
+			if writer.rotateAtDue(time.Now()) {
+				writer.rotate()
 			}
 
-			if writer.timeRotated {
+			if policy := writer.rotationPolicy(); nil != policy {
+				if policy.ShouldRotate(writer.rotationInfo()) {
+					writer.rotate()
+				}
+			} else if writer.timeRotated {
 				// if fileName not equal to currentFileName, it needs a time base logrotate
-				if fileName := fmt.Sprintf("%s.%s", writer.fileName, timeCache.Date()); writer.currentFileName != fileName {
-					writer.resetFile()
-					writer.currentFileName = fileName
-
-					// when it needs to expire logs
-					if writer.retentions > 0 {
-						// format the expired log file name
-						date := timeCache.Now().Add(time.Duration(-24*(writer.retentions+1)) * time.Hour).Format(DateFormat)
-						expiredFileName := fmt.Sprintf("%s.%s", writer.fileName, date)
-						// check if expired log exists
-						if _, err := os.Stat(expiredFileName); nil == err {
-							os.Remove(expiredFileName)
+				if fileName := fmt.Sprintf("%s.%s", writer.fileName, writer.timeCache.Date()); writer.currentFileName != fileName {
+					// currentFileName is kept to retry next tick if failed
+					rotated := writer.currentFileName
+					if nil == writer.resetFile() {
+						writer.archive(rotated)
+
+						// when it needs to expire logs
+						if writer.retentions > 0 {
+							// format the expired log file name
+							date := writer.timeCache.Now().Add(time.Duration(-24*(writer.retentions+1)) * time.Hour).Format(DateFormat)
+							expiredFileName := fmt.Sprintf("%s.%s", writer.fileName, date)
+							// check if expired log exists
+							if _, err := os.Stat(expiredFileName); nil == err {
+								os.Remove(expiredFileName)
+							}
 						}
 					}
 				}
@@ -214,12 +612,12 @@ DaemonLoop:
 
 		// analyse lines && size written
 		// do lines && size base logrotate
-		case size := <-writer.logSizeChan:
+		case size := <-queue:
 			if writer.Closed() {
 				break DaemonLoop
 			}
 
-			if !writer.sizeRotated && !writer.lineRotated {
+			if !writer.rotationCounted() {
 				continue
 			}
 
@@ -230,80 +628,303 @@ DaemonLoop:
 			writer.currentLines++
 			writer.lock.Unlock()
 
-			if (writer.sizeRotated && writer.currentSize >= writer.rotateSize) || (writer.lineRotated && writer.currentLines >= writer.rotateLines) {
-				// need lines && size base logrotate
-				var oldName, newName string
-				oldName = fmt.Sprintf("%s.%d", writer.currentFileName, writer.retentions)
-				// check if expired log exists
-				if _, err := os.Stat(oldName); os.IsNotExist(err) {
-					os.Remove(oldName)
+			if policy := writer.rotationPolicy(); nil != policy {
+				if policy.ShouldRotate(writer.rotationInfo()) {
+					writer.rotate()
 				}
-				if writer.retentions > 0 {
+			} else if (writer.sizeRotated && writer.currentSize >= writer.rotateSize) || (writer.lineRotated && writer.currentLines >= writer.rotateLines) {
+				writer.rotate()
+			}
+		}
+	}
+}
 
-					for i := writer.retentions - 1; i > 0; i-- {
-						oldName = fmt.Sprintf("%s.%d", writer.currentFileName, i)
-						newName = fmt.Sprintf("%s.%d", writer.currentFileName, i+1)
-						os.Rename(oldName, newName)
-					}
-					os.Rename(writer.currentFileName, oldName)
+// rotate do a size && line base logrotate, like xxx renamed to xxx.1 and
+// xxx.1 renamed to xxx.2
+func (writer *baseFileWriter) rotate() {
+	writer.rotateLock.Lock()
+	defer writer.rotateLock.Unlock()
 
-					writer.resetFile()
-				}
+	if policy := writer.rotationPolicy(); nil != policy {
+		writer.rotateByPolicy(policy)
+		return
+	}
+
+	var oldName, newName string
+	oldName = fmt.Sprintf("%s.%d", writer.currentFileName, writer.retentions)
+	// check if expired log exists
+	if _, err := os.Stat(oldName); os.IsNotExist(err) {
+		os.Remove(oldName)
+	}
+	if name, ok := writer.nextRotateName(); ok {
+		newName = name
+		os.Rename(writer.currentFileName, newName)
+		if nil != writer.integrity {
+			os.Rename(writer.currentFileName+IntegritySuffix, newName+IntegritySuffix)
+		}
+
+		writer.resetFile()
+		writer.archive(newName)
+	} else if writer.retentions > 0 {
+
+		for i := writer.retentions - 1; i > 0; i-- {
+			oldName = fmt.Sprintf("%s.%d", writer.currentFileName, i)
+			newName = fmt.Sprintf("%s.%d", writer.currentFileName, i+1)
+			os.Rename(oldName, newName)
+			if nil != writer.integrity {
+				os.Rename(oldName+IntegritySuffix, newName+IntegritySuffix)
 			}
 		}
+		os.Rename(writer.currentFileName, oldName)
+		if nil != writer.integrity {
+			os.Rename(writer.currentFileName+IntegritySuffix, oldName+IntegritySuffix)
+		}
+
+		writer.resetFile()
+		writer.archive(oldName)
 	}
 }
 
-// resetFile reset current writing file
-func (writer *baseFileWriter) resetFile() {
+// rotateByPolicy rename the current file to the name given by policy and
+// open a new one
+func (writer *baseFileWriter) rotateByPolicy(policy RotationPolicy) {
 	writer.lock.Lock()
-	defer writer.lock.Unlock()
+	writer.rotateCount++
+	count, opened := writer.rotateCount, writer.opened
+	writer.lock.Unlock()
+
+	name := policy.RotatedName(writer.currentFileName, opened, count)
+	os.Rename(writer.currentFileName, name)
+	if nil != writer.integrity {
+		os.Rename(writer.currentFileName+IntegritySuffix, name+IntegritySuffix)
+	}
+
+	writer.resetFile()
+	writer.archive(name)
+}
 
+// Rotate do a size base logrotate at once. It is not supported if file
+// descriptor inherited.
+func (writer *baseFileWriter) Rotate() error {
+	if writer.Closed() {
+		return ErrWriterClosed
+	}
+	if writer.inherited {
+		return ErrNotSupported
+	}
+
+	writer.rotate()
+	return nil
+}
+
+// resetFile reset current writing file. Opening is retried with exponential
+// backoff if failed, messages are written to the current file meanwhile.
+// If all attempts failed, messages are discarded until the next successful
+// reset and handler set by SetResetErrorHandler is called.
+func (writer *baseFileWriter) resetFile() error {
 	fileName := writer.fileName
 	if writer.timeRotated {
-		fileName = fmt.Sprintf("%s.%s", fileName, timeCache.Date())
+		fileName = fmt.Sprintf("%s.%s", fileName, writer.timeCache.Date())
+	}
+
+	var err error
+	backoff := ResetRetryInitialBackoff
+	for i := 0; i < ResetRetryAttempts; i++ {
+		if i > 0 {
+			time.Sleep(backoff)
+			if backoff *= 2; backoff > ResetRetryMaxBackoff {
+				backoff = ResetRetryMaxBackoff
+			}
+		}
+
+		writer.lock.Lock()
+		if err = writer.openFile(fileName); nil == err {
+			writer.currentFileName = fileName
+		}
+		writer.lock.Unlock()
+		if nil == err {
+			writer.counters.rotated()
+			return nil
+		}
+	}
+
+	// the current file may be renamed already, discard messages instead
+	writer.lock.Lock()
+	writer.blog.resetFile(ioutil.Discard)
+	handler := writer.resetErrorHandler
+	writer.lock.Unlock()
+	if nil != handler {
+		handler(err)
+	}
+	return err
+}
+
+// DaemonInterval get interval of daemon ticks
+func (writer *baseFileWriter) DaemonInterval() time.Duration {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.daemonInterval
+}
+
+// SetDaemonInterval set interval of daemon ticks, default
+// DefaultDaemonInterval. Buffer is flushed and time cache is refreshed every
+// tick, so timestamps are accurate to the interval. Periodic work like time base logrotate,
+// alerts and SetRotateAt is done every tick as well, so long intervals delay
+// them and SetRotateAt needs an interval within RotateAtWindow. Intervals
+// shorter than MinDaemonInterval are raised to it.
+func (writer *baseFileWriter) SetDaemonInterval(d time.Duration) {
+	if d < MinDaemonInterval {
+		d = MinDaemonInterval
+	}
+
+	writer.lock.Lock()
+	writer.daemonInterval = d
+	writer.lock.Unlock()
+
+	// daemon reads the latest interval, a pending signal is enough
+	select {
+	case writer.daemonIntervalSig <- struct{}{}:
+	default:
+	}
+}
+
+// SetResetErrorHandler set handler called with the error if the new file
+// can not be opened while logrotate
+func (writer *baseFileWriter) SetResetErrorHandler(handler func(err error)) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.resetErrorHandler = handler
+}
+
+// Reopen close the current file and reopen the file at the same path,
+// created if missing. It is used when the file is renamed by external tools
+// like logrotate, messages are written to the renamed file until reopened.
+// It is not supported if file descriptor inherited.
+func (writer *baseFileWriter) Reopen() error {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	if writer.closed {
+		return ErrWriterClosed
+	}
+	if writer.inherited {
+		return ErrNotSupported
+	}
+	return writer.openFile(writer.currentFileName)
+}
+
+// openFile switches writing to fileName, the current file is kept if failed
+func (writer *baseFileWriter) openFile(fileName string) error {
+	file, err := os.OpenFile(fileName, DefaultFileFlag, os.FileMode(0644))
+	if nil != err {
+		return err
+	}
+	if -1 != writer.uid || -1 != writer.gid {
+		file.Chown(writer.uid, writer.gid)
+	}
+	if writer.immutable {
+		setAppendOnly(file)
+	}
+
+	if nil != writer.integrity {
+		// sign the new file with a new chain, keep writing to the old file
+		// signed if it fails
+		integrity, err := newIntegrityWriter(file, fileName, writer.integrityKey)
+		if nil != err {
+			file.Close()
+			return err
+		}
+		writer.blog.flush()
+		writer.integrity.Close()
+		writer.integrity = integrity
+	}
+
+	if nil != writer.integrity {
+		writer.blog.resetFile(writer.integrity)
+	} else {
+		writer.blog.resetFile(file)
 	}
-	file, _ := os.OpenFile(fileName, os.O_WRONLY|os.O_APPEND|os.O_CREATE, os.FileMode(0644))
-	writer.blog.resetFile(file)
 	writer.file.Close()
 	writer.file = file
 
 	writer.currentSize = 0
 	writer.currentLines = 0
+	writer.opened = time.Now()
+	writer.writeSchemaHeader(file)
+	return nil
 }
 
 // write writes pure message with specific level
 func (writer *baseFileWriter) write(level LevelType, args ...interface{}) {
+	writer.writeFrom(writer, level, args...)
+}
+
+// writeFrom writes pure message with specific level, calling hooks of source
+func (writer *baseFileWriter) writeFrom(source hookSource, level LevelType, args ...interface{}) {
 	var size = 0
 
-	if writer.closed {
+	if writer.closed || writer.isDraining() {
+		writer.counters.dropped()
+		return
+	}
+
+	if burst := writer.burstCapture(); nil != burst && !burst.allow(fmt.Sprint(args...)) {
+		writer.counters.dropped()
 		return
 	}
 
 	defer func() {
-		// 异步调用log hook
-		if nil != writer.hook && !(level < writer.hookLevel) {
-			if writer.hookAsync {
-				go func(level LevelType, args ...interface{}) {
-					writer.hook.Fire(level, args...)
-				}(level, args...)
-
-			} else {
-				writer.hook.Fire(level, args...)
-			}
-		}
+		writer.written(source, level, size, func() []interface{} { return args })
+	}()
 
-		// logrotate
-		if writer.sizeRotated || writer.lineRotated {
-			writer.logSizeChan <- size
-		}
+	size = writer.timedWrite(level, func() int { return writer.blog.write(level, args...) })
+
+	if nil != writer.sampler && rand.Float64() < writer.sampleRate {
+		writer.sampler.write(level, args...)
+	}
+}
+
+// writeBytes writes message of bytes with specific level. message is not
+// kept after it returns.
+func (writer *baseFileWriter) writeBytes(level LevelType, message []byte) {
+	writer.writeBytesFrom(writer, level, message)
+}
+
+// writeBytesFrom writes message of bytes with specific level, calling hooks
+// of source. Nothing is allocated unless message is needed as a string,
+// like for hooks, burst capture or middlewares.
+func (writer *baseFileWriter) writeBytesFrom(source hookSource, level LevelType, message []byte) {
+	var size = 0
+
+	if writer.closed || writer.isDraining() {
+		writer.counters.dropped()
+		return
+	}
+
+	if burst := writer.burstCapture(); nil != burst && !burst.allow(string(message)) {
+		writer.counters.dropped()
+		return
+	}
+
+	defer func() {
+		writer.written(source, level, size, func() []interface{} { return []interface{}{string(message)} })
 	}()
 
-	size = writer.blog.write(level, args...)
+	size = writer.timedWrite(level, func() int { return writer.blog.writeBytes(level, message) })
+
+	if nil != writer.sampler && rand.Float64() < writer.sampleRate {
+		writer.sampler.writeBytes(level, message)
+	}
 }
 
 // write formats message with specific level and write it
 func (writer *baseFileWriter) writef(level LevelType, format string, args ...interface{}) {
+	writer.writefFrom(writer, level, format, args...)
+}
+
+// writefFrom formats message with specific level and write it, calling hooks
+// of source
+func (writer *baseFileWriter) writefFrom(source hookSource, level LevelType, format string, args ...interface{}) {
 	// 格式化构造message
 	// 边解析边输出
 	// 使用 % 作占位符
@@ -311,70 +932,338 @@ func (writer *baseFileWriter) writef(level LevelType, format string, args ...int
 	// 统计日志size
 	var size = 0
 
-	if writer.closed {
+	if writer.closed || writer.isDraining() {
+		writer.counters.dropped()
 		return
 	}
 
-	defer func() {
-		// 异步调用log hook
-		if nil != writer.hook && !(level < writer.hookLevel) {
-			if writer.hookAsync {
-				go func(level LevelType, format string, args ...interface{}) {
-					writer.hook.Fire(level, fmt.Sprintf(format, args...))
-				}(level, format, args...)
-
-			} else {
-				writer.hook.Fire(level, fmt.Sprintf(format, args...))
-			}
-		}
+	if burst := writer.burstCapture(); nil != burst && !burst.allow(format) {
+		writer.counters.dropped()
+		return
+	}
 
-		// logrotate
-		if writer.sizeRotated || writer.lineRotated {
-			writer.logSizeChan <- size
-		}
+	defer func() {
+		writer.written(source, level, size, func() []interface{} {
+			return []interface{}{fmt.Sprintf(format, args...)}
+		})
 	}()
 
-	size = writer.blog.writef(level, format, args...)
-}
+	size = writer.timedWrite(level, func() int { return writer.blog.writef(level, format, args...) })
 
-// Closed get writer status
-func (writer *baseFileWriter) Closed() bool {
-	writer.lock.RLock()
-	defer writer.lock.RUnlock()
-	return writer.closed
+	if nil != writer.sampler && rand.Float64() < writer.sampleRate {
+		writer.sampler.writef(level, format, args...)
+	}
 }
 
-// Close close file writer
-func (writer *baseFileWriter) Close() {
-	if writer.Closed() {
-		return
+// timedWrite calls write, which writes a message of level to blog and
+// returns size written, blocked while suspended. Time taken is passed to
+// the latency tracker and the write observer.
+func (writer *baseFileWriter) timedWrite(level LevelType, write func() int) (size int) {
+	// blocked while suspended
+	writer.suspension.RLock()
+	defer writer.suspension.RUnlock()
+
+	observer := writer.writeObserver()
+	latency := writer.latencyTracker()
+	var begin time.Time
+	if nil != observer || nil != latency {
+		begin = time.Now()
 	}
 
-	writer.lock.Lock()
-	defer writer.lock.Unlock()
+	size = write()
+	if writer.shutdown {
+		writer.blog.flush()
+	}
 
-	writer.closed = true
-	writer.blog.flush()
-	writer.blog.Close()
-	writer.blog = nil
-	writer.file.Close()
-	close(writer.logSizeChan)
-	close(writer.timeRotateSig)
-	close(writer.sizeRotateSig)
+	if nil != latency {
+		latency.record(time.Since(begin))
+	}
+	if nil != observer {
+		observer(level, time.Since(begin), size)
+	}
+	return size
 }
 
-// TimeRotated get timeRotated
-func (writer *baseFileWriter) TimeRotated() bool {
-	writer.lock.RLock()
-	defer writer.lock.RUnlock()
-	return writer.timeRotated
+// written does the bookkeeping after a message of level written with size,
+// like counters, hooks of source, statistics, alerters and logrotate.
+// hookArgs is only called when there are hooks to fire.
+func (writer *baseFileWriter) written(source hookSource, level LevelType, size int, hookArgs func() []interface{}) {
+	writer.counters.written(size)
+
+	// 异步调用log hook
+	if hooks := source.hooksFired(level); len(hooks) > 0 {
+		if source.hooksAsync() {
+			go fireHooks(hooks, level, hookArgs()...)
+		} else {
+			fireHooks(hooks, level, hookArgs()...)
+		}
+	}
+
+	now := writer.timeCache.Now()
+	if stats := writer.timeBucketStats(); nil != stats {
+		stats.add(level, now)
+	}
+
+	if auto := writer.autoLevelAdjust(); nil != auto {
+		auto.add(level)
+	}
+
+	if histogram := writer.levelHistogram(); nil != histogram {
+		histogram.add(level)
+	}
+
+	for _, alert := range writer.alerters() {
+		alert.add(level, now)
+	}
+
+	// logrotate, every message is a line for line base logrotate
+	if writer.rotationCounted() {
+		writer.queueSize(size)
+	}
 }
 
-// SetTimeRotated toggle time base logrotate on the fly
-func (writer *baseFileWriter) SetTimeRotated(timeRotated bool) {
+// syncSize resets size counted for size base logrotate to the actual size
+// of the file and size buffered, as size counted may drift from the file
+func (writer *baseFileWriter) syncSize() {
 	writer.lock.Lock()
 	defer writer.lock.Unlock()
-	writer.timeRotated = timeRotated
+
+	info, err := writer.file.Stat()
+	if nil != err {
+		return
+	}
+	writer.currentSize = info.Size() + int64(writer.blog.buffered())
+}
+
+// WriteRaw writes p to the file without any prefix, suffix or level check,
+// like pre-formatted entries replayed from another source
+func (writer *baseFileWriter) WriteRaw(p []byte) (n int, err error) {
+	if writer.closed || writer.isDraining() {
+		return 0, ErrWriterClosed
+	}
+
+	defer func() {
+		// logrotate
+		if writer.rotationCounted() {
+			writer.queueSize(n)
+		}
+	}()
+
+	// blocked while suspended
+	writer.suspension.RLock()
+	defer writer.suspension.RUnlock()
+
+	n, err = writer.blog.writeRaw(p)
+	if writer.shutdown {
+		writer.blog.flush()
+	}
+	return n, err
+}
+
+// WriteLocked calls fn with the buffer of the file held exclusively, so that
+// a multi-part message, like binary data or a large stack trace, is written
+// without interleaving with other goroutines. Nothing like prefix or EOL is
+// added. fn must not call any method of the writer, or it deadlocks.
+func (writer *baseFileWriter) WriteLocked(fn func(w io.Writer)) error {
+	if writer.closed || writer.isDraining() {
+		return ErrWriterClosed
+	}
+
+	// blocked while suspended
+	writer.suspension.RLock()
+	defer writer.suspension.RUnlock()
+
+	n, err := writer.blog.writeLocked(fn)
+	if nil != err {
+		return err
+	}
+	if writer.shutdown {
+		writer.blog.flush()
+	}
+
+	// logrotate
+	if writer.rotationCounted() {
+		writer.queueSize(n)
+	}
+	return nil
+}
+
+// WriteBatch writes entries back-to-back holding the lock once, so that
+// they are never interleaved with messages of other goroutines. Entries
+// lower than logging level are dropped. Hooks are called for every entry
+// after the batch written.
+func (writer *baseFileWriter) WriteBatch(entries []LogEntry) error {
+	if writer.closed || writer.isDraining() {
+		return ErrWriterClosed
+	}
+
+	level := writer.Level()
+	batch := make([]LogEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !(entry.Level < level) {
+			batch = append(batch, entry)
+		}
+	}
+
+	// blocked while suspended
+	writer.suspension.RLock()
+	sizes := writer.blog.writeBatch(batch)
+	if writer.shutdown {
+		writer.blog.flush()
+	}
+	writer.suspension.RUnlock()
+
+	for i, entry := range batch {
+		message := entry.Message
+		writer.written(writer, entry.Level, sizes[i], func() []interface{} { return []interface{}{message} })
+	}
+	return nil
+}
+
+// Annotate writes an annotation line regardless of logging level, like
+// ">>>> starting TestFoo <<<<" after timestamp. Parser recognizes it as an
+// entry with Annotation set.
+func (writer *baseFileWriter) Annotate(annotation string) {
+	if writer.closed || writer.isDraining() {
+		return
+	}
+
+	writer.lock.RLock()
+	format := writer.annotationFormat
+	writer.lock.RUnlock()
+
+	// blocked while suspended
+	writer.suspension.RLock()
+	defer writer.suspension.RUnlock()
+
+	size := writer.blog.annotate(format, annotation)
+	if writer.shutdown {
+		writer.blog.flush()
+	}
+
+	// logrotate
+	if writer.rotationCounted() {
+		writer.queueSize(size)
+	}
+}
+
+// SetAnnotationFormat set format of annotation lines, format must have
+// exactly one %s, default DefaultAnnotationFormat
+func (writer *baseFileWriter) SetAnnotationFormat(format string) error {
+	if !validAnnotationFormat(format) {
+		return ErrInvalidFormat
+	}
+
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.annotationFormat = format
+	return nil
+}
+
+// WriteTo flushes buffer, and copies data written to the file since the
+// last call to dst. Data is copied from the beginning of the file after
+// logrotate, data written to the file rotated is not copied.
+// It implements io.WriterTo, so sendfile may be used if dst supports.
+func (writer *baseFileWriter) WriteTo(dst io.Writer) (n int64, err error) {
+	if writer.inherited {
+		return 0, ErrNotSupported
+	}
+	if writer.Closed() {
+		return 0, ErrWriterClosed
+	}
+
+	writer.flush()
+
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	file, err := os.Open(writer.currentFileName)
+	if nil != err {
+		return 0, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if nil != err {
+		return 0, err
+	}
+
+	// file rotated or truncated
+	if writer.currentFileName != writer.copiedFileName || info.Size() < writer.copiedOffset {
+		writer.copiedFileName = writer.currentFileName
+		writer.copiedOffset = 0
+	}
+
+	if _, err = file.Seek(writer.copiedOffset, io.SeekStart); nil != err {
+		return 0, err
+	}
+
+	n, err = io.Copy(dst, file)
+	writer.copiedOffset += n
+	return n, err
+}
+
+// Closed get writer status
+func (writer *baseFileWriter) Closed() bool {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.closed
+}
+
+// Close close file writer
+func (writer *baseFileWriter) Close() {
+	if writer.Closed() {
+		return
+	}
+
+	writer.Drain(0)
+	queue := writer.sizeQueue()
+
+	// wait for lines piped being written
+	writer.pipeLock.Lock()
+	defer writer.pipeLock.Unlock()
+
+	writer.lock.Lock()
+	writer.closed = true
+	writer.blog.flush()
+	writer.blog.Close()
+	writer.blog = nil
+	if writer.closeOnExit {
+		writer.file.Close()
+	}
+	if nil != writer.integrity {
+		writer.integrity.Close()
+	}
+	if nil != writer.sampler {
+		writer.sampler.Close()
+	}
+	close(queue)
+	close(writer.timeRotateSig)
+	close(writer.sizeRotateSig)
+	writer.lock.Unlock()
+
+	// daemon may be waiting for writer.lock, wait for it after released
+	select {
+	case <-writer.done:
+	case <-time.After(DaemonExitTimeout):
+	}
+}
+
+// TimeRotated get timeRotated
+func (writer *baseFileWriter) TimeRotated() bool {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.timeRotated
+}
+
+// SetTimeRotated toggle time base logrotate on the fly
+func (writer *baseFileWriter) SetTimeRotated(timeRotated bool) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if writer.inherited {
+		return
+	}
+	writer.timeRotated = timeRotated
 }
 
 // Retentions get log retention days
@@ -384,6 +1273,68 @@ func (writer *baseFileWriter) Retentions() int64 {
 	return writer.retentions
 }
 
+// SetRotateNamer set namer of files rotated by size or line base logrotate,
+// instead of shifting xxx.1, xxx.2. Files rotated are never expired by
+// retentions then. nil restores the default naming.
+func (writer *baseFileWriter) SetRotateNamer(namer RotateNamer) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.rotateNamer = namer
+	writer.rotateCount = 0
+}
+
+// SetRotationPolicy set policy of logrotate, size, line and time base
+// logrotate are replaced by the policy. Files rotated are never expired by
+// retentions then. nil restores them.
+func (writer *baseFileWriter) SetRotationPolicy(policy RotationPolicy) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.policy = policy
+	writer.rotateCount = 0
+}
+
+// rotationPolicy get policy of logrotate, nil if not set
+func (writer *baseFileWriter) rotationPolicy() RotationPolicy {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.policy
+}
+
+// rotationCounted return true if size && lines written are summed up by
+// daemon for logrotate
+func (writer *baseFileWriter) rotationCounted() bool {
+	return writer.sizeRotated || writer.lineRotated || nil != writer.rotationPolicy()
+}
+
+// rotationInfo get state of the current file checked by policy of logrotate
+func (writer *baseFileWriter) rotationInfo() RotationInfo {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return RotationInfo{
+		FileName: writer.currentFileName,
+		Size:     writer.currentSize,
+		Lines:    writer.currentLines,
+		Opened:   writer.opened,
+		Now:      time.Now(),
+	}
+}
+
+// nextRotateName counts a size or line base logrotate and return the name
+// of file rotated given by namer, false if namer not set
+func (writer *baseFileWriter) nextRotateName() (string, bool) {
+	writer.lock.Lock()
+	namer := writer.rotateNamer
+	if nil == namer {
+		writer.lock.Unlock()
+		return "", false
+	}
+	writer.rotateCount++
+	count := writer.rotateCount
+	writer.lock.Unlock()
+
+	return namer(writer.currentFileName, count, time.Now()), true
+}
+
 // SetExpiredDays set how many days of logs will keep
 func (writer *baseFileWriter) SetRetentions(retentions int64) {
 	writer.lock.Lock()
@@ -405,6 +1356,9 @@ func (writer *baseFileWriter) RotateSize() int64 {
 func (writer *baseFileWriter) SetRotateSize(rotateSize int64) {
 	writer.lock.Lock()
 	defer writer.lock.Unlock()
+	if writer.inherited {
+		return
+	}
 	if rotateSize > 0 {
 		writer.sizeRotated = true
 		writer.rotateSize = rotateSize
@@ -424,6 +1378,9 @@ func (writer *baseFileWriter) RotateLines() int {
 func (writer *baseFileWriter) SetRotateLines(rotateLines int) {
 	writer.lock.Lock()
 	defer writer.lock.Unlock()
+	if writer.inherited {
+		return
+	}
 	if rotateLines > 0 {
 		writer.lineRotated = true
 		writer.rotateLines = rotateLines
@@ -451,6 +1408,60 @@ func (writer *baseFileWriter) SetColored(colored bool) {
 	initPrefix(colored)
 }
 
+// SetEOL set end of every line
+func (writer *baseFileWriter) SetEOL(eol []byte) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.blog.SetEOL(eol)
+}
+
+// SetLineWrap split messages longer than width bytes across multiple lines,
+// timestamp and level prefix appear only on the first line
+func (writer *baseFileWriter) SetLineWrap(width int) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.blog.SetLineWrap(width)
+}
+
+// SetMaxLineWidth set max bytes of a line without EOL, messages of longer
+// lines are truncated with LineTruncateMarker appended
+func (writer *baseFileWriter) SetMaxLineWidth(width int) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.blog.SetMaxLineWidth(width)
+}
+
+// SetMaxMessageSize set max bytes of a message excluding prefix, longer
+// messages are truncated with MessageTruncateMarker appended, see
+// BLog.SetMaxMessageSize
+func (writer *baseFileWriter) SetMaxMessageSize(size int) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.blog.SetMaxMessageSize(size)
+}
+
+// SetFullLineColor toggle coloring the whole line in the color of level
+// when colored, only the level prefix is colored by default
+func (writer *baseFileWriter) SetFullLineColor(enabled bool) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.blog.SetFullLineColor(enabled)
+}
+
+// SetLineWrapMarker set prefix of continuation lines, default "  "
+func (writer *baseFileWriter) SetLineWrapMarker(marker string) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.blog.SetLineWrapMarker(marker)
+}
+
+// AddMiddleware add a middleware applied to every message before written
+func (writer *baseFileWriter) AddMiddleware(middleware Middleware) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.blog.AddMiddleware(middleware)
+}
+
 // Level get log level
 func (writer *baseFileWriter) Level() LevelType {
 	writer.lock.RLock()
@@ -472,6 +1483,39 @@ func (writer *baseFileWriter) SetHook(hook Hook) {
 	writer.hook = hook
 }
 
+// AddHook add hook with id, called along with hook set by SetHook
+func (writer *baseFileWriter) AddHook(id string, hook Hook) {
+	writer.hooks.AddHook(id, hook)
+}
+
+// RemoveHook remove hook with id
+func (writer *baseFileWriter) RemoveHook(id string) {
+	writer.hooks.RemoveHook(id)
+}
+
+// ListHooks return sorted ids of hooks added
+func (writer *baseFileWriter) ListHooks() []string {
+	return writer.hooks.ListHooks()
+}
+
+// hooksFired snapshots hooks to be called for logging action with level
+func (writer *baseFileWriter) hooksFired(level LevelType) []Hook {
+	if level < writer.hookLevel {
+		return nil
+	}
+
+	hooks := writer.hooks.snapshot()
+	if nil != writer.hook {
+		hooks = append(hooks, writer.hook)
+	}
+	return hooks
+}
+
+// hooksAsync determines whether hooks are called async
+func (writer *baseFileWriter) hooksAsync() bool {
+	return writer.hookAsync
+}
+
 // SetHookAsync set hook async for base file writer
 func (writer *baseFileWriter) SetHookAsync(async bool) {
 	writer.lock.Lock()
@@ -486,6 +1530,603 @@ func (writer *baseFileWriter) SetHookLevel(level LevelType) {
 	writer.hookLevel = level
 }
 
+// PipeFromWithContext logs every line read from r with given level until r
+// reaches EOF or ctx is done
+func (writer *baseFileWriter) PipeFromWithContext(ctx context.Context, r io.Reader, level LevelType) error {
+	if writer.Closed() {
+		return ErrWriterClosed
+	}
+
+	// stop piping once the writer closed
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-writer.done:
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+
+	go func() {
+		pipe(ctx, writer, r, level)
+		cancel()
+	}()
+	return nil
+}
+
+// lockPipe holds pipeLock shared while a line piped is written, false is
+// returned if closed
+func (writer *baseFileWriter) lockPipe() bool {
+	writer.pipeLock.RLock()
+	if writer.Closed() {
+		writer.pipeLock.RUnlock()
+		return false
+	}
+	return true
+}
+
+// unlockPipe releases pipeLock held by lockPipe
+func (writer *baseFileWriter) unlockPipe() {
+	writer.pipeLock.RUnlock()
+}
+
+// SetCloseOnExit set whether file is closed when writer closed
+func (writer *baseFileWriter) SetCloseOnExit(closeOnExit bool) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.closeOnExit = closeOnExit
+}
+
+// SetOwner changes owner of the log file, and every file created by
+// logrotate later, so that log files are still writable after privileges
+// dropped. -1 uid or gid is not changed. It is not supported on windows.
+func (writer *baseFileWriter) SetOwner(uid, gid int) error {
+	if "windows" == runtime.GOOS {
+		return ErrNotSupported
+	}
+
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	if err := writer.file.Chown(uid, gid); nil != err {
+		return err
+	}
+
+	writer.uid = uid
+	writer.gid = gid
+	return nil
+}
+
+// SetImmutable set whether log files are append-only, which can not be
+// modified, truncated, renamed or deleted, even by root. On linux the
+// append-only attribute is set like chattr +a, which requires root or
+// CAP_LINUX_IMMUTABLE, and also requires root to remove by MakeFileMutable.
+// On other unix platforms writes are only ensured to be appended.
+// Notice that logrotate can not rename append-only files.
+func (writer *baseFileWriter) SetImmutable(enabled bool) error {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	var err error
+	if enabled {
+		err = setAppendOnly(writer.file)
+	} else {
+		err = clearAppendOnly(writer.file)
+	}
+	if nil != err {
+		return err
+	}
+
+	writer.immutable = enabled
+	return nil
+}
+
+// SetIntegrityCheck enables tamper-evident logging with key. Chained
+// HMAC-SHA256 of every line is appended to the sidecar file named with
+// IntegritySuffix, which can be verified by VerifyIntegrity.
+// nil key disables integrity check. It is not supported if file descriptor
+// inherited.
+func (writer *baseFileWriter) SetIntegrityCheck(key []byte) (err error) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	if writer.inherited {
+		// no file name for the sidecar file
+		return ErrNotSupported
+	}
+
+	// flush lines to the writer in use before switching
+	writer.blog.flush()
+	if nil != writer.integrity {
+		writer.integrity.Close()
+		writer.integrity = nil
+	}
+
+	writer.integrityKey = key
+	if nil == key {
+		writer.blog.resetFile(writer.file)
+		return nil
+	}
+
+	writer.integrity, err = newIntegrityWriter(writer.file, writer.currentFileName, key)
+	if nil != err {
+		writer.integrityKey = nil
+		writer.blog.resetFile(writer.file)
+		return err
+	}
+
+	writer.blog.resetFile(writer.integrity)
+	return nil
+}
+
+// SetSamplingWriter makes every message also written to dest with probability
+// rate, which is between 0.0 and 1.0. The sample file is written with the
+// same logrotate and color settings as the writer when it is set.
+// 0 rate or empty dest disables sampling.
+func (writer *baseFileWriter) SetSamplingWriter(rate float64, dest string) error {
+	if rate < 0 || rate > 1 {
+		return ErrInvalidSampleRate
+	}
+
+	var sampler *baseFileWriter
+	if rate > 0 && "" != dest {
+		var err error
+		if sampler, err = writer.newSampler(dest); nil != err {
+			return err
+		}
+	}
+
+	writer.setSampler(rate, sampler)
+	return nil
+}
+
+// SetBurstCapture makes the first burstCount occurrences of every format
+// string always written, and the following ones written with probability
+// sampleRate. Counters are reset every interval set by
+// SetBurstCaptureInterval. Not positive burstCount disables burst capture.
+func (writer *baseFileWriter) SetBurstCapture(burstCount int, sampleRate float64) error {
+	if sampleRate < 0 || sampleRate > 1 {
+		return ErrInvalidSampleRate
+	}
+
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	writer.burst = nil
+	if burstCount > 0 {
+		writer.burst = newBurstCapture(burstCount, sampleRate, writer.burstInterval)
+	}
+	return nil
+}
+
+// SetBurstCaptureInterval set interval between resets of burst counters,
+// default DefaultBurstInterval
+func (writer *baseFileWriter) SetBurstCaptureInterval(interval time.Duration) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	writer.burstInterval = interval
+	if nil != writer.burst {
+		writer.burst.setInterval(interval)
+	}
+}
+
+// burstCapture get burst capture in use, nil if disabled
+func (writer *baseFileWriter) burstCapture() *burstCapture {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.burst
+}
+
+// EnableTimeBucketStats counts messages written per level in buckets of
+// bucketSize, which can be retrieved by TimeBucketStats. Buckets older than
+// retention set by SetTimeBucketRetention are pruned. Not positive
+// bucketSize disables time bucket stats.
+func (writer *baseFileWriter) EnableTimeBucketStats(bucketSize time.Duration) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	writer.bucketStats = nil
+	if bucketSize > 0 {
+		writer.bucketStats = newTimeBucketStats(bucketSize, writer.bucketRetention)
+	}
+}
+
+// SetTimeBucketRetention set duration time buckets kept, default
+// DefaultTimeBucketRetention
+func (writer *baseFileWriter) SetTimeBucketRetention(retention time.Duration) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	writer.bucketRetention = retention
+	if nil != writer.bucketStats {
+		writer.bucketStats.setRetention(retention)
+	}
+}
+
+// TimeBucketStats return counts of messages per level of buckets starting
+// between from and to inclusive, by start time of bucket
+func (writer *baseFileWriter) TimeBucketStats(from, to time.Time) map[time.Time]map[LevelType]int64 {
+	result := make(map[time.Time]map[LevelType]int64)
+	if stats := writer.timeBucketStats(); nil != stats {
+		stats.between(from, to, result)
+	}
+	return result
+}
+
+// timeBucketStats get time bucket stats in use, nil if disabled
+func (writer *baseFileWriter) timeBucketStats() *timeBucketStats {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.bucketStats
+}
+
+// Histogram create a LevelHistogram counting messages written per level in
+// the last buckets of bucketDuration, replacing the one created before.
+// nil is returned and histogram is disabled if buckets or bucketDuration is
+// not positive.
+func (writer *baseFileWriter) Histogram(buckets int, bucketDuration time.Duration) *LevelHistogram {
+	histogram := newLevelHistogram(buckets, bucketDuration)
+	writer.setLevelHistogram(histogram)
+	return histogram
+}
+
+// setLevelHistogram set level histogram in use
+func (writer *baseFileWriter) setLevelHistogram(histogram *LevelHistogram) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.histogram = histogram
+}
+
+// levelHistogram get level histogram in use, nil if disabled
+func (writer *baseFileWriter) levelHistogram() *LevelHistogram {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.histogram
+}
+
+// SetAlertThreshold calls fn in daemon once count messages exceed level are
+// written within window, then alert is suppressed for another window.
+// Alert set before for the same level is replaced, and removed if count is
+// not positive or fn is nil.
+func (writer *baseFileWriter) SetAlertThreshold(level LevelType, count int, window time.Duration, fn AlertFunc) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	alerts := make([]*alerter, 0, len(writer.alerts)+1)
+	for _, alert := range writer.alerts {
+		if level != alert.level {
+			alerts = append(alerts, alert)
+		}
+	}
+	if count > 0 && nil != fn {
+		alerts = append(alerts, newAlerter(level, count, window, fn))
+	}
+	writer.alerts = alerts
+}
+
+// alerters get alerters in use
+func (writer *baseFileWriter) alerters() []*alerter {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.alerts
+}
+
+// SetWriteObserver set observer called synchronously after every write with
+// level, time taken to write and size written, like integration with APM
+// agents. nil observer removes it.
+func (writer *baseFileWriter) SetWriteObserver(observer WriteObserver) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.observer = observer
+}
+
+// writeObserver get observer in use, nil if not set
+func (writer *baseFileWriter) writeObserver() WriteObserver {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.observer
+}
+
+// SetLatencyTracking toggle tracking of write latency, the latest
+// LatencySamples samples of time taken to acquire lock and write are kept.
+// Samples are dropped when disabled.
+func (writer *baseFileWriter) SetLatencyTracking(enabled bool) {
+	var tracker *latencyTracker
+	if enabled {
+		tracker = newLatencyTracker(LatencySamples)
+	}
+	writer.setLatencyTracker(tracker)
+}
+
+// setLatencyTracker set tracker of write latency, nil disables it
+func (writer *baseFileWriter) setLatencyTracker(tracker *latencyTracker) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.latency = tracker
+}
+
+// latencyTracker get tracker of write latency, nil if disabled
+func (writer *baseFileWriter) latencyTracker() *latencyTracker {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.latency
+}
+
+// Percentile get write latency at percentile p of samples kept, p is
+// between 0 and 100, like 99 or 99.9. 0 is returned if latency tracking is
+// disabled or no sample kept.
+func (writer *baseFileWriter) Percentile(p float64) time.Duration {
+	if tracker := writer.latencyTracker(); nil != tracker {
+		return tracker.percentile(p)
+	}
+	return 0
+}
+
+// ResetLatency drops samples of write latency kept
+func (writer *baseFileWriter) ResetLatency() {
+	if tracker := writer.latencyTracker(); nil != tracker {
+		tracker.reset()
+	}
+}
+
+// SetAutoLevel lowers level to lowerTo when more than threshold messages
+// exceed trigger level within window, like lowering level to DEBUG when
+// errors spike to capture more context. Level is restored after cooldown set
+// by SetAutoLevelCooldown. A warning line is written when the adjustment
+// activates. Not positive threshold or window disables auto level
+// adjustment.
+func (writer *baseFileWriter) SetAutoLevel(trigger LevelType, threshold int, window time.Duration, lowerTo LevelType) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	writer.autoLevel = nil
+	if threshold > 0 && window > 0 {
+		writer.autoLevel = newAutoLevel(trigger, threshold, window, lowerTo, writer.autoLevelCooldown)
+	}
+}
+
+// SetAutoLevelCooldown set duration level kept lowered by auto level
+// adjustment, default DefaultAutoLevelCooldown
+func (writer *baseFileWriter) SetAutoLevelCooldown(cooldown time.Duration) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	writer.autoLevelCooldown = cooldown
+	if nil != writer.autoLevel {
+		writer.autoLevel.setCooldown(cooldown)
+	}
+}
+
+// autoLevelAdjust get auto level adjustment in use, nil if disabled
+func (writer *baseFileWriter) autoLevelAdjust() *autoLevel {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.autoLevel
+}
+
+// adjustLevel lowers or restores level by auto level adjustment at now
+func (writer *baseFileWriter) adjustLevel(now time.Time) {
+	auto := writer.autoLevelAdjust()
+	if nil == auto {
+		return
+	}
+
+	if ok, level := auto.restore(now); ok {
+		writer.SetLevel(level)
+		return
+	}
+
+	if ok, message := auto.activate(now, writer.Level()); ok {
+		writer.SetLevel(auto.lowered())
+		writer.metaLog(message)
+	}
+}
+
+// metaLog writes a warning line about blog4go itself, regardless of level
+// or hooks
+func (writer *baseFileWriter) metaLog(message string) {
+	size := writer.blog.write(WARNING, message)
+
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.currentSize += int64(size)
+	writer.currentLines++
+}
+
+// queueSize queues size written for daemon to sum up
+func (writer *baseFileWriter) queueSize(size int) {
+	writer.queueLock.RLock()
+	defer writer.queueLock.RUnlock()
+	writer.logSizeChan <- size
+}
+
+// sizeQueue get the queue of sizes written
+func (writer *baseFileWriter) sizeQueue() chan int {
+	writer.queueLock.RLock()
+	defer writer.queueLock.RUnlock()
+	return writer.logSizeChan
+}
+
+// QueueDepth get number of writes waiting for daemon to sum up sizes
+func (writer *baseFileWriter) QueueDepth() int {
+	return len(writer.sizeQueue())
+}
+
+// QueueCapacity get capacity of the queue of sizes written
+func (writer *baseFileWriter) QueueCapacity() int {
+	return cap(writer.sizeQueue())
+}
+
+// SetQueueCapacity resize the queue of sizes written, sizes already queued
+// are summed up at once by daemon
+func (writer *baseFileWriter) SetQueueCapacity(n int) error {
+	if n < 1 {
+		return ErrInvalidCapacity
+	}
+
+	writer.resizeLock.Lock()
+	defer writer.resizeLock.Unlock()
+
+	writer.lock.RLock()
+	closed := writer.closed || writer.isDraining()
+	writer.lock.RUnlock()
+	if closed {
+		return ErrWriterClosed
+	}
+
+	// wait for writes queueing to the old queue
+	queue := make(chan int, n)
+	writer.queueLock.Lock()
+	writer.logSizeChan = queue
+	writer.queueLock.Unlock()
+
+	// daemon keeps summing up the old queue until it takes the new one
+	select {
+	case writer.queueSig <- queue:
+	case <-writer.done:
+		return ErrWriterClosed
+	}
+	return nil
+}
+
+// Stats get a snapshot of status of the writer
+func (writer *baseFileWriter) Stats() Stats {
+	queue := writer.sizeQueue()
+
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return Stats{
+		FileName:      writer.fileName,
+		QueueDepth:    len(queue),
+		QueueCapacity: cap(queue),
+	}
+}
+
+// DebugInfo get a snapshot of internal state of the writer for
+// troubleshooting, it can be serialized to json
+func (writer *baseFileWriter) DebugInfo() map[string]interface{} {
+	queue := writer.sizeQueue()
+
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+
+	info := map[string]interface{}{
+		"file_name":         writer.fileName,
+		"current_file_name": writer.currentFileName,
+		"closed":            writer.closed,
+		"shutdown":          writer.shutdown,
+		"draining":          writer.isDraining(),
+		"inherited":         writer.inherited,
+		"level":             writer.blog.Level().String(),
+		"colored":           writer.colored,
+		"time_rotated":      writer.timeRotated,
+		"size_rotated":      writer.sizeRotated,
+		"line_rotated":      writer.lineRotated,
+		"rotate_size":       writer.rotateSize,
+		"rotate_lines":      writer.rotateLines,
+		"retentions":        writer.retentions,
+		"current_size":      writer.currentSize,
+		"current_lines":     writer.currentLines,
+		"queue_depth":       len(queue),
+		"queue_capacity":    cap(queue),
+		"integrity":         nil != writer.integrity,
+		"sample_rate":       writer.sampleRate,
+		"burst_capture":     nil != writer.burst,
+		"time_bucket_stats": nil != writer.bucketStats,
+		"histogram":         nil != writer.histogram,
+		"alerts":            len(writer.alerts),
+		"auto_level":        nil != writer.autoLevel,
+	}
+
+	buffered, size := writer.blog.bufferStatus()
+	info["buffered"] = buffered
+	info["buffer_size"] = size
+
+	if !writer.closed {
+		if position, err := writer.file.Seek(0, io.SeekCurrent); nil == err {
+			info["file_position"] = position
+		}
+	}
+	return info
+}
+
+// newSampler create a writer with the same settings writing to dest
+func (writer *baseFileWriter) newSampler(dest string) (sampler *baseFileWriter, err error) {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+
+	// daemon starts after the sampler is set up
+	sampler, err = openBaseFileWriter(dest, writer.timeRotated, DefaultFileFlag)
+	if nil != err {
+		return nil, err
+	}
+
+	sampler.sizeRotated = writer.sizeRotated
+	sampler.rotateSize = writer.rotateSize
+	sampler.lineRotated = writer.lineRotated
+	sampler.rotateLines = writer.rotateLines
+	sampler.retentions = writer.retentions
+	sampler.colored = writer.colored
+	sampler.blog.SetLevel(writer.blog.Level())
+	sampler.blog.SetEOL(writer.blog.EOL())
+	sampler.start()
+	return sampler, nil
+}
+
+// setSampler replaces sampler of the writer, old sampler will be closed
+func (writer *baseFileWriter) setSampler(rate float64, sampler *baseFileWriter) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	if nil != writer.sampler && sampler != writer.sampler {
+		writer.sampler.Close()
+	}
+	writer.sampleRate = rate
+	writer.sampler = sampler
+}
+
+// BeginShutdown makes every following write flushed to disk synchronously
+func (writer *baseFileWriter) BeginShutdown() {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.shutdown = true
+}
+
+// Drain stops accepting new messages, waits until every message written is
+// summed up by daemon and flushes logs to disk. context.DeadlineExceeded is
+// returned if it can not complete within timeout, logs are flushed anyway.
+// Drain is one-way before Close, messages are dropped after it until the
+// writer closed.
+func (writer *baseFileWriter) Drain(timeout time.Duration) (err error) {
+	if writer.Closed() {
+		return ErrWriterClosed
+	}
+
+	atomic.StoreInt32(&writer.draining, 1)
+
+	deadline := time.Now().Add(timeout)
+	for 0 != len(writer.sizeQueue()) {
+		wait := deadline.Sub(time.Now())
+		if wait <= 0 {
+			err = context.DeadlineExceeded
+			break
+		}
+		if wait > 10*time.Millisecond {
+			wait = 10 * time.Millisecond
+		}
+		time.Sleep(wait)
+	}
+
+	writer.flush()
+	return err
+}
+
+// isDraining determines whether Drain called
+func (writer *baseFileWriter) isDraining() bool {
+	return 1 == atomic.LoadInt32(&writer.draining)
+}
+
 // flush flush logs to disk
 func (writer *baseFileWriter) flush() {
 	writer.blog.flush()
@@ -493,7 +2134,7 @@ func (writer *baseFileWriter) flush() {
 
 // Trace trace
 func (writer *baseFileWriter) Trace(args ...interface{}) {
-	if nil == writer.blog || TRACE < writer.blog.Level() {
+	if nil == writer.blog || TRACE < writer.levelOfCaller() {
 		return
 	}
 
@@ -502,7 +2143,7 @@ func (writer *baseFileWriter) Trace(args ...interface{}) {
 
 // Tracef tracef
 func (writer *baseFileWriter) Tracef(format string, args ...interface{}) {
-	if nil == writer.blog || TRACE < writer.blog.Level() {
+	if nil == writer.blog || TRACE < writer.levelOfCaller() {
 		return
 	}
 
@@ -511,7 +2152,7 @@ func (writer *baseFileWriter) Tracef(format string, args ...interface{}) {
 
 // Debug debug
 func (writer *baseFileWriter) Debug(args ...interface{}) {
-	if nil == writer.blog || DEBUG < writer.blog.Level() {
+	if nil == writer.blog || DEBUG < writer.levelOfCaller() {
 		return
 	}
 
@@ -520,7 +2161,7 @@ func (writer *baseFileWriter) Debug(args ...interface{}) {
 
 // Debugf debugf
 func (writer *baseFileWriter) Debugf(format string, args ...interface{}) {
-	if nil == writer.blog || DEBUG < writer.blog.Level() {
+	if nil == writer.blog || DEBUG < writer.levelOfCaller() {
 		return
 	}
 
@@ -529,7 +2170,7 @@ func (writer *baseFileWriter) Debugf(format string, args ...interface{}) {
 
 // Info info
 func (writer *baseFileWriter) Info(args ...interface{}) {
-	if nil == writer.blog || INFO < writer.blog.Level() {
+	if nil == writer.blog || INFO < writer.levelOfCaller() {
 		return
 	}
 
@@ -538,7 +2179,7 @@ func (writer *baseFileWriter) Info(args ...interface{}) {
 
 // Infof infof
 func (writer *baseFileWriter) Infof(format string, args ...interface{}) {
-	if nil == writer.blog || INFO < writer.blog.Level() {
+	if nil == writer.blog || INFO < writer.levelOfCaller() {
 		return
 	}
 
@@ -547,7 +2188,7 @@ func (writer *baseFileWriter) Infof(format string, args ...interface{}) {
 
 // Warn warn
 func (writer *baseFileWriter) Warn(args ...interface{}) {
-	if nil == writer.blog || WARNING < writer.blog.Level() {
+	if nil == writer.blog || WARNING < writer.levelOfCaller() {
 		return
 	}
 
@@ -556,7 +2197,7 @@ func (writer *baseFileWriter) Warn(args ...interface{}) {
 
 // Warnf warn
 func (writer *baseFileWriter) Warnf(format string, args ...interface{}) {
-	if nil == writer.blog || WARNING < writer.blog.Level() {
+	if nil == writer.blog || WARNING < writer.levelOfCaller() {
 		return
 	}
 
@@ -565,7 +2206,7 @@ func (writer *baseFileWriter) Warnf(format string, args ...interface{}) {
 
 // Error error
 func (writer *baseFileWriter) Error(args ...interface{}) {
-	if nil == writer.blog || ERROR < writer.blog.Level() {
+	if nil == writer.blog || ERROR < writer.levelOfCaller() {
 		return
 	}
 
@@ -574,7 +2215,7 @@ func (writer *baseFileWriter) Error(args ...interface{}) {
 
 // Errorf errorf
 func (writer *baseFileWriter) Errorf(format string, args ...interface{}) {
-	if nil == writer.blog || ERROR < writer.blog.Level() {
+	if nil == writer.blog || ERROR < writer.levelOfCaller() {
 		return
 	}
 
@@ -583,7 +2224,7 @@ func (writer *baseFileWriter) Errorf(format string, args ...interface{}) {
 
 // Critical critical
 func (writer *baseFileWriter) Critical(args ...interface{}) {
-	if nil == writer.blog || CRITICAL < writer.blog.Level() {
+	if nil == writer.blog || CRITICAL < writer.levelOfCaller() {
 		return
 	}
 
@@ -592,7 +2233,7 @@ func (writer *baseFileWriter) Critical(args ...interface{}) {
 
 // Criticalf criticalf
 func (writer *baseFileWriter) Criticalf(format string, args ...interface{}) {
-	if nil == writer.blog || CRITICAL < writer.blog.Level() {
+	if nil == writer.blog || CRITICAL < writer.levelOfCaller() {
 		return
 	}
 
@@ -3,7 +3,16 @@
 package blog4go
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
 	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -123,3 +132,847 @@ func TestBaseFileWriterBasicOperation(t *testing.T) {
 	blog.Debug("Debug", 1)
 	blog.Debugf("%s", "Debug")
 }
+
+func TestBaseFileWriterWithFlags(t *testing.T) {
+	defer os.Remove("/tmp/flags.log")
+
+	if err := ioutil.WriteFile("/tmp/flags.log", []byte("old content\n"), 0644); nil != err {
+		t.Fatalf("prepare log file failed. err: %s", err.Error())
+	}
+
+	// os.O_EXCL should fail since file exists
+	if _, err := newBaseFileWriterWithFlags("/tmp/flags.log", false, DefaultFileFlag|os.O_EXCL); nil == err {
+		t.Error("open existing file with os.O_EXCL should fail")
+	}
+
+	writer, err := newBaseFileWriterWithFlags("/tmp/flags.log", false, DefaultFileFlag|os.O_TRUNC)
+	if nil != err {
+		t.Fatalf("initialize base file writer with flags failed. err: %s", err.Error())
+	}
+	defer writer.Close()
+
+	writer.Info("new content")
+	writer.flush()
+
+	content, err := ioutil.ReadFile("/tmp/flags.log")
+	if nil != err {
+		t.Fatalf("read log failed. err: %s", err.Error())
+	}
+
+	if strings.Contains(string(content), "old content") || !strings.Contains(string(content), "new content") {
+		t.Errorf("log file should be truncated. content: %s", content)
+	}
+}
+
+func TestBaseFileWriterBeginShutdown(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/shutdown.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/shutdown.log")
+	}()
+
+	writer.BeginShutdown()
+	writer.Info("flushed")
+	writer.Infof("%s", "flushed")
+
+	// no flush called, content should be on disk already
+	content, err := ioutil.ReadFile("/tmp/shutdown.log")
+	if nil != err {
+		t.Fatalf("read log failed. err: %s", err.Error())
+	}
+
+	if 2 != strings.Count(string(content), "flushed") {
+		t.Errorf("log should be flushed after every write when shutting down. content: %s", content)
+	}
+}
+
+func TestBaseFileWriterSamplingWriter(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/sampling.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/sampling.log")
+		os.Remove("/tmp/sample.log")
+	}()
+
+	if ErrInvalidSampleRate != writer.SetSamplingWriter(1.5, "/tmp/sample.log") {
+		t.Error("sample rate greater than 1 should fail")
+	}
+
+	// sample every message
+	if err = writer.SetSamplingWriter(1, "/tmp/sample.log"); nil != err {
+		t.Fatalf("set sampling writer failed. err: %s", err.Error())
+	}
+	writer.Info("sampled")
+	writer.Infof("%s", "sampled")
+
+	// disable sampling
+	writer.SetSamplingWriter(0, "")
+	writer.Info("not sampled")
+	writer.flush()
+
+	content, _ := ioutil.ReadFile("/tmp/sample.log")
+	if 2 != strings.Count(string(content), "sampled") {
+		t.Errorf("sample file content wrong. content: %s", content)
+	}
+
+	content, _ = ioutil.ReadFile("/tmp/sampling.log")
+	if 3 != strings.Count(string(content), "sampled") {
+		t.Errorf("log file content wrong. content: %s", content)
+	}
+}
+
+func TestBaseFileWriterDrain(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/drain.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/drain.log")
+	}()
+
+	writer.SetRotateLines(100)
+	writer.Info("drained")
+
+	// writes racing with drain
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 100; i++ {
+			writer.Info("racing")
+		}
+		close(done)
+	}()
+
+	if err = writer.Drain(time.Second); nil != err {
+		t.Errorf("drain failed. err: %s", err.Error())
+	}
+	<-done
+	writer.Info("dropped")
+	if err = writer.Drain(time.Second); nil != err {
+		t.Errorf("drain again failed. err: %s", err.Error())
+	}
+
+	content, err := ioutil.ReadFile("/tmp/drain.log")
+	if nil != err {
+		t.Fatalf("read log failed. err: %s", err.Error())
+	}
+
+	if !strings.Contains(string(content), "drained") {
+		t.Errorf("log should be flushed after drain. content: %s", content)
+	}
+	if strings.Contains(string(content), "dropped") {
+		t.Errorf("log should be dropped after drain. content: %s", content)
+	}
+}
+
+func TestBaseFileWriterWriteRaw(t *testing.T) {
+	if _, err := WriteRaw([]byte("raw\n")); ErrNotSupported != err {
+		t.Error("write raw without file writer should fail")
+	}
+
+	err := NewBaseFileWriter("/tmp/raw.log", false)
+	defer func() {
+		Close()
+		os.Remove("/tmp/raw.log")
+	}()
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+
+	SetLevel(CRITICAL)
+	Info("text")
+	n, err := WriteRaw([]byte("\x00raw entry\n"))
+	if nil != err || 11 != n {
+		t.Errorf("write raw failed. n: %d", n)
+	}
+	Flush()
+
+	content, err := ioutil.ReadFile("/tmp/raw.log")
+	if nil != err {
+		t.Fatalf("read log failed. err: %s", err.Error())
+	}
+
+	if "\x00raw entry\n" != string(content) {
+		t.Errorf("raw bytes should be written as it is. content: %q", content)
+	}
+}
+
+func TestBaseFileWriterWriteLocked(t *testing.T) {
+	if ErrNotSupported != WriteLocked(func(w io.Writer) {}) {
+		t.Error("write locked without file writer should fail")
+	}
+
+	err := NewBaseFileWriter("/tmp/locked.log", false)
+	defer func() {
+		Close()
+		os.Remove("/tmp/locked.log")
+	}()
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			WriteLocked(func(w io.Writer) {
+				io.WriteString(w, "{\n")
+				io.WriteString(w, "}\n")
+			})
+		}()
+	}
+	wg.Wait()
+	Flush()
+
+	content, err := ioutil.ReadFile("/tmp/locked.log")
+	if nil != err {
+		t.Fatalf("read log failed. err: %s", err.Error())
+	}
+
+	if strings.Repeat("{\n}\n", 10) != string(content) {
+		t.Errorf("parts written should not interleave. content: %q", content)
+	}
+
+	writer := blog.(*baseFileWriter)
+	Close()
+	if ErrWriterClosed != writer.WriteLocked(func(w io.Writer) {}) {
+		t.Error("write locked to closed writer should fail")
+	}
+}
+
+func TestBaseFileWriterSyncSize(t *testing.T) {
+	if err := ioutil.WriteFile("/tmp/sync.log", bytes.Repeat([]byte("x"), 100), 0644); nil != err {
+		t.Fatalf("write log failed. err: %s", err.Error())
+	}
+
+	writer, err := newBaseFileWriter("/tmp/sync.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/sync.log")
+	}()
+
+	writer.syncSize()
+	if 100 != writer.currentSize {
+		t.Errorf("size of existing file should be counted. size: %d", writer.currentSize)
+	}
+
+	// buffered
+	writer.WriteRaw([]byte("raw\n"))
+	writer.syncSize()
+	if 104 != writer.currentSize {
+		t.Errorf("size buffered should be counted. size: %d", writer.currentSize)
+	}
+
+	writer.flush()
+	writer.syncSize()
+	if 104 != writer.currentSize {
+		t.Errorf("size flushed should be counted once. size: %d", writer.currentSize)
+	}
+}
+
+func TestBaseFileWriterResetRetry(t *testing.T) {
+	os.MkdirAll("/tmp/reset", 0755)
+	defer os.RemoveAll("/tmp/reset")
+
+	writer, err := newBaseFileWriter("/tmp/reset/reset.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer writer.Close()
+
+	backoff := ResetRetryInitialBackoff
+	ResetRetryInitialBackoff = time.Millisecond
+	defer func() {
+		ResetRetryInitialBackoff = backoff
+	}()
+
+	var resetErr error
+	writer.SetResetErrorHandler(func(err error) {
+		resetErr = err
+	})
+
+	// directory of the file removed, messages are discarded
+	os.RemoveAll("/tmp/reset")
+	if err = writer.resetFile(); nil == err || resetErr != err {
+		t.Error("reset error handler should be called")
+	}
+	writer.Info("discarded")
+
+	// directory recreated while retrying
+	resetErr = nil
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		os.MkdirAll("/tmp/reset", 0755)
+	}()
+	if err = writer.resetFile(); nil != err || nil != resetErr {
+		t.Errorf("reset should succeed after retry. err: %v", err)
+	}
+
+	writer.Info("after reset")
+	writer.flush()
+	if content, err := ioutil.ReadFile("/tmp/reset/reset.log"); nil != err || !strings.HasSuffix(string(content), "] after reset\n") || strings.Contains(string(content), "discarded") {
+		t.Errorf("message should be written to the new file. content: %q", content)
+	}
+}
+
+func TestBaseFileWriterTimeRotateResetFailed(t *testing.T) {
+	os.MkdirAll("/tmp/reset", 0755)
+	defer os.RemoveAll("/tmp/reset")
+
+	writer, err := newBaseFileWriter("/tmp/reset/reset.log", true)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer writer.Close()
+
+	attempts := ResetRetryAttempts
+	ResetRetryAttempts = 1
+	defer func() {
+		ResetRetryAttempts = attempts
+	}()
+
+	failed := make(chan error, 1)
+	writer.SetResetErrorHandler(func(err error) {
+		select {
+		case failed <- err:
+		default:
+		}
+	})
+
+	// file of the date can not be opened, the daemon retries every tick
+	writer.lock.Lock()
+	current := writer.currentFileName
+	writer.currentFileName = "/tmp/reset/reset.log.yesterday"
+	writer.lock.Unlock()
+	os.RemoveAll("/tmp/reset")
+
+	select {
+	case <-failed:
+	case <-time.After(3 * time.Second):
+		t.Fatal("reset should fail")
+	}
+	writer.lock.RLock()
+	name := writer.currentFileName
+	writer.lock.RUnlock()
+	if "/tmp/reset/reset.log.yesterday" != name {
+		t.Errorf("current file name should not be advanced if reset failed. name: %s", name)
+	}
+
+	// reset succeeds once the directory is back
+	os.MkdirAll("/tmp/reset", 0755)
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		writer.lock.RLock()
+		name = writer.currentFileName
+		writer.lock.RUnlock()
+		if current == name {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if current != name {
+		t.Errorf("current file name should be advanced after reset. name: %s", name)
+	}
+}
+
+func TestBaseFileWriterDebugInfo(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/debug.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/debug.log")
+	}()
+
+	writer.SetRotateSize(MB)
+	writer.WriteRaw([]byte("raw\n"))
+	info := writer.DebugInfo()
+	if "/tmp/debug.log" != info["file_name"] || false != info["closed"] || true != info["size_rotated"] || MB != info["rotate_size"] {
+		t.Errorf("debug info wrong. info: %v", info)
+	}
+	if 4 != info["buffered"] || DefaultBufferSize != info["buffer_size"] || int64(0) != info["file_position"] {
+		t.Errorf("buffer status wrong. info: %v", info)
+	}
+
+	if _, err = json.Marshal(info); nil != err {
+		t.Errorf("debug info should be serialized to json. err: %s", err.Error())
+	}
+}
+
+func TestBaseFileWriterRotateNamer(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/named.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		exec.Command("/bin/sh", "-c", "/bin/rm /tmp/named.log*").Output()
+	}()
+
+	writer.SetRotateLines(2)
+	writer.SetRotateNamer(func(baseName string, rotateCount int, t time.Time) string {
+		return fmt.Sprintf("%s-%d-%s", baseName, rotateCount, t.Format("20060102"))
+	})
+
+	date := time.Now().Format("20060102")
+	for i := 0; i < 4; i++ {
+		writer.Infof("line %d", i)
+		if 1 != i%2 {
+			continue
+		}
+
+		// wait for logrotate
+		rotated := fmt.Sprintf("/tmp/named.log-%d-%s", i/2+1, date)
+		var content []byte
+		for j := 0; j < 20; j++ {
+			if content, err = ioutil.ReadFile(rotated); nil == err && 0 != len(content) {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		if !strings.HasSuffix(string(content), fmt.Sprintf("] line %d\n", i)) {
+			t.Errorf("file rotated should be named by namer. name: %s, content: %q", rotated, content)
+		}
+	}
+	if _, err = os.Stat("/tmp/named.log.1"); !os.IsNotExist(err) {
+		t.Error("default naming should not be used")
+	}
+}
+
+func TestBaseFileWriterFromFD(t *testing.T) {
+	if _, err := newBaseFileWriterFromFD(-1); ErrInvalidFD != err {
+		t.Error("negative file descriptor should fail")
+	}
+
+	file, err := os.OpenFile("/tmp/fd.log", DefaultFileFlag, os.FileMode(0644))
+	if nil != err {
+		t.Fatalf("open file failed. err: %s", err.Error())
+	}
+	defer func() {
+		file.Close()
+		os.Remove("/tmp/fd.log")
+	}()
+
+	writer, err := newBaseFileWriterFromFD(int(file.Fd()))
+	if nil != err {
+		t.Fatalf("initialize base file writer from fd failed. err: %s", err.Error())
+	}
+
+	writer.SetTimeRotated(true)
+	writer.SetRotateLines(1)
+	if writer.TimeRotated() || writer.lineRotated {
+		t.Error("logrotate should be disabled with inherited fd")
+	}
+	if ErrNotSupported != writer.SetIntegrityCheck([]byte("key")) {
+		t.Error("integrity check should not be supported with inherited fd")
+	}
+
+	if ErrNotSupported != writer.Reopen() {
+		t.Error("reopen should not be supported with inherited fd")
+	}
+
+	writer.Info("inherited")
+	writer.Close()
+
+	// fd is not closed by writer
+	if _, err = file.WriteString("after close\n"); nil != err {
+		t.Errorf("inherited fd should not be closed. err: %s", err.Error())
+	}
+
+	content, err := ioutil.ReadFile("/tmp/fd.log")
+	if nil != err {
+		t.Fatalf("read log failed. err: %s", err.Error())
+	}
+	if !strings.Contains(string(content), "] inherited\nafter close\n") {
+		t.Errorf("log content wrong. content: %q", content)
+	}
+}
+
+func TestBaseFileWriterReopen(t *testing.T) {
+	err := NewBaseFileWriter("/tmp/reopen.log", false)
+	defer func() {
+		Close()
+		os.Remove("/tmp/reopen.log")
+		os.Remove("/tmp/reopen.log.1")
+	}()
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+
+	SetColored(false)
+	Info("before rotated")
+	Flush()
+
+	// renamed by logrotate
+	if err = os.Rename("/tmp/reopen.log", "/tmp/reopen.log.1"); nil != err {
+		t.Fatalf("rename log failed. err: %s", err.Error())
+	}
+	Info("after rotated")
+	if err = Reopen(); nil != err {
+		t.Fatalf("reopen failed. err: %s", err.Error())
+	}
+	Info("after reopened")
+	Flush()
+
+	content, err := ioutil.ReadFile("/tmp/reopen.log.1")
+	if nil != err || !strings.HasSuffix(string(content), "] after rotated\n") {
+		t.Errorf("messages before reopened should be written to the renamed file. content: %q", content)
+	}
+
+	content, err = ioutil.ReadFile("/tmp/reopen.log")
+	if nil != err || !strings.HasSuffix(string(content), "] after reopened\n") || strings.Contains(string(content), "rotated") {
+		t.Errorf("messages after reopened should be written to a new file. content: %q", content)
+	}
+}
+
+func TestBaseFileWriterImmutable(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/immutable.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		MakeFileMutable("/tmp/immutable.log")
+		os.Remove("/tmp/immutable.log")
+	}()
+
+	if err = writer.SetImmutable(true); nil != err {
+		// not supported or no permission
+		t.Logf("set immutable failed. err: %s", err.Error())
+		return
+	}
+
+	writer.Info("immutable")
+	writer.flush()
+	if "linux" == runtime.GOOS {
+		if nil == os.Truncate("/tmp/immutable.log", 0) {
+			t.Error("append-only file should not be truncated")
+		}
+	}
+
+	if err = writer.SetImmutable(false); nil != err {
+		t.Errorf("set mutable failed. err: %s", err.Error())
+	}
+	if err = os.Truncate("/tmp/immutable.log", 0); nil != err {
+		t.Errorf("mutable file should be truncated. err: %s", err.Error())
+	}
+}
+
+func TestBaseFileWriterWriteObserver(t *testing.T) {
+	err := NewBaseFileWriter("/tmp/observer.log", false)
+	defer func() {
+		Close()
+		os.Remove("/tmp/observer.log")
+	}()
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+
+	var levels []LevelType
+	var sizes int
+	SetWriteObserver(func(level LevelType, latency time.Duration, size int) {
+		if latency < 0 {
+			t.Errorf("latency should not be negative. latency: %s", latency)
+		}
+		levels = append(levels, level)
+		sizes += size
+	})
+	SetColored(false)
+
+	Info("info")
+	Errorf("%s", "error")
+	SetWriteObserver(nil)
+	Warn("not observed")
+	Flush()
+
+	if 2 != len(levels) || INFO != levels[0] || ERROR != levels[1] {
+		t.Errorf("observer called wrong. levels: %v", levels)
+	}
+
+	content, err := ioutil.ReadFile("/tmp/observer.log")
+	if nil != err {
+		t.Fatalf("read log failed. err: %s", err.Error())
+	}
+	if sizes != len(content)-len(content[bytes.LastIndexByte(content[:len(content)-1], EOL)+1:]) {
+		t.Errorf("observer size wrong. size: %d, content: %q", sizes, content)
+	}
+}
+
+func TestBaseFileWriterSetOwner(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/owner.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/owner.log")
+		os.Remove("/tmp/owner.log.1")
+	}()
+
+	if "windows" == runtime.GOOS {
+		if ErrNotSupported != writer.SetOwner(0, 0) {
+			t.Error("set owner should not be supported on windows")
+		}
+		return
+	}
+	if 0 != os.Getuid() {
+		// changing owner requires root
+		return
+	}
+
+	if err = writer.SetOwner(65534, 65534); nil != err {
+		t.Fatalf("set owner failed. err: %s", err.Error())
+	}
+
+	// file rotated is created with the same owner
+	os.Rename("/tmp/owner.log", "/tmp/owner.log.1")
+	if err = writer.Reopen(); nil != err {
+		t.Fatalf("reopen failed. err: %s", err.Error())
+	}
+
+	for _, fileName := range []string{"/tmp/owner.log", "/tmp/owner.log.1"} {
+		out, err := exec.Command("stat", "-c", "%u:%g", fileName).Output()
+		if nil == err && "65534:65534" != strings.TrimSpace(string(out)) {
+			t.Errorf("owner of %s wrong. owner: %s", fileName, out)
+		}
+	}
+}
+
+func TestBaseFileWriterQueueCapacity(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/queue.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/queue.log")
+	}()
+
+	if DefaultQueueCapacity != writer.QueueCapacity() {
+		t.Errorf("default queue capacity wrong. capacity: %d", writer.QueueCapacity())
+	}
+
+	if ErrInvalidCapacity != writer.SetQueueCapacity(0) {
+		t.Error("capacity less than 1 should fail")
+	}
+
+	if err = writer.SetQueueCapacity(16); nil != err {
+		t.Errorf("set queue capacity failed. err: %s", err.Error())
+	}
+
+	writer.SetRotateLines(3)
+	for i := 0; i < 10; i++ {
+		writer.Info("queued")
+	}
+
+	stats := writer.Stats()
+	if "/tmp/queue.log" != stats.FileName || 16 != stats.QueueCapacity || stats.QueueDepth > 10 {
+		t.Errorf("stats wrong. stats: %+v", stats)
+	}
+}
+
+func TestBaseFileWriterQueueResizedWhileWriting(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/queue.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/queue.log")
+	}()
+
+	writer.SetRotateLines(1000000)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				writer.Info("queued")
+			}
+		}()
+	}
+	for i := 1; i <= 20; i++ {
+		if err = writer.SetQueueCapacity(i); nil != err {
+			t.Errorf("set queue capacity failed. err: %s", err.Error())
+		}
+	}
+	wg.Wait()
+
+	if err = writer.Drain(time.Second); nil != err {
+		t.Errorf("drain failed. err: %s", err.Error())
+	}
+	// wait for the last size received being summed up
+	time.Sleep(10 * time.Millisecond)
+	if lines := writer.rotationInfo().Lines; 4000 != lines {
+		t.Errorf("sizes lost while resizing queue. lines: %d", lines)
+	}
+}
+
+func TestBaseFileWriterWriteTo(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/writeto.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/writeto.log")
+	}()
+
+	writer.Info("first")
+	dst := new(bytes.Buffer)
+	n, err := writer.WriteTo(dst)
+	if nil != err || int64(dst.Len()) != n || !strings.HasSuffix(dst.String(), "] first\n") {
+		t.Errorf("write to failed. n: %d, content: %q", n, dst.String())
+	}
+
+	// only data written since the last call
+	writer.Info("second")
+	dst.Reset()
+	if _, err = writer.WriteTo(dst); nil != err || !strings.HasSuffix(dst.String(), "] second\n") || strings.Contains(dst.String(), "first") {
+		t.Errorf("write to should copy data written since the last call. content: %q", dst.String())
+	}
+
+	dst.Reset()
+	if n, err = writer.WriteTo(dst); nil != err || 0 != n {
+		t.Errorf("nothing should be copied without new data. n: %d", n)
+	}
+
+	// truncated file is copied from the beginning
+	os.Truncate("/tmp/writeto.log", 0)
+	writer.Info("third")
+	dst.Reset()
+	if _, err = writer.WriteTo(dst); nil != err || 1 != strings.Count(dst.String(), "\n") || !strings.HasSuffix(dst.String(), "] third\n") {
+		t.Errorf("truncated file should be copied from the beginning. content: %q", dst.String())
+	}
+}
+
+func TestBaseFileWriterRotate(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/rotate.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/rotate.log")
+		os.Remove("/tmp/rotate.log.1")
+	}()
+
+	writer.SetRetentions(2)
+	writer.Info("before rotate")
+	if err = writer.Rotate(); nil != err {
+		t.Fatalf("rotate failed. err: %s", err.Error())
+	}
+	writer.Info("after rotate")
+	writer.flush()
+
+	if content, err := ioutil.ReadFile("/tmp/rotate.log.1"); nil != err || !strings.HasSuffix(string(content), "] before rotate\n") {
+		t.Errorf("message should be written to the rotated file. content: %q", content)
+	}
+	if content, err := ioutil.ReadFile("/tmp/rotate.log"); nil != err || !strings.HasSuffix(string(content), "] after rotate\n") || strings.Contains(string(content), "before") {
+		t.Errorf("message should be written to the new file. content: %q", content)
+	}
+
+	writer.Close()
+	if ErrWriterClosed != writer.Rotate() {
+		t.Error("rotate should fail after closed")
+	}
+}
+
+func TestBaseFileWriterCloseWaitsDaemon(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/daemon.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer os.Remove("/tmp/daemon.log")
+
+	writer.SetRotateLines(1000)
+	writer.Info("message")
+	writer.Close()
+
+	select {
+	case <-writer.done:
+	default:
+		t.Error("daemon should exit before close returns")
+	}
+}
+
+func TestBaseFileWriterWriteBatch(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/batch.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/batch.log")
+	}()
+
+	hook := new(CountingHook)
+	writer.SetHook(hook)
+	writer.SetHookAsync(false)
+	writer.SetLevel(INFO)
+
+	err = writer.WriteBatch([]LogEntry{
+		{INFO, "record 1"},
+		{DEBUG, "filtered"},
+		{ERROR, "record 2"},
+	})
+	if nil != err {
+		t.Fatalf("write batch failed. err: %s", err.Error())
+	}
+	writer.flush()
+
+	content, _ := ioutil.ReadFile("/tmp/batch.log")
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if 2 != len(lines) || !strings.HasSuffix(stripColor(lines[0]), "[INFO] record 1") || !strings.HasSuffix(stripColor(lines[1]), "[ERROR] record 2") {
+		t.Errorf("batch written wrong. lines: %q", lines)
+	}
+	if 2 != hook.Count() {
+		t.Errorf("hook should be fired for every entry written. count: %d", hook.Count())
+	}
+
+	writer.Close()
+	if ErrWriterClosed != writer.WriteBatch([]LogEntry{{INFO, "closed"}}) {
+		t.Error("write batch should fail after closed")
+	}
+}
+
+func TestBaseFileWriterDaemonInterval(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/daemonInterval.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/daemonInterval.log")
+	}()
+
+	if DefaultDaemonInterval != writer.DaemonInterval() {
+		t.Errorf("daemon interval should be the default. interval: %s", writer.DaemonInterval())
+	}
+	writer.SetDaemonInterval(time.Microsecond)
+	if MinDaemonInterval != writer.DaemonInterval() {
+		t.Errorf("daemon interval should be raised to the min. interval: %s", writer.DaemonInterval())
+	}
+
+	writer.SetDaemonInterval(10 * time.Millisecond)
+	writer.SetTimeFormat("15:04:05.000", nil)
+	before := writer.timeCache.Now()
+	time.Sleep(100 * time.Millisecond)
+	if after := writer.timeCache.Now(); after.Sub(before) < 50*time.Millisecond {
+		t.Errorf("time cache should be refreshed every tick. before: %s, after: %s", before, after)
+	}
+}
@@ -7,12 +7,16 @@ package blog4go
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 )
 
 const (
@@ -37,6 +41,36 @@ var (
 	ErrInvalidFormat = errors.New("Invalid format type")
 	// ErrAlreadyInit show that blog is already initialized once
 	ErrAlreadyInit = errors.New("blog4go has been already initialized")
+	// ErrWriterClosed show that the writer is already closed
+	ErrWriterClosed = errors.New("Writer has been already closed")
+	// ErrNotSupported show that the feature is not supported by the writer
+	// or on this platform
+	ErrNotSupported = errors.New("Not supported")
+	// ErrInvalidFD show that the file descriptor is not valid
+	ErrInvalidFD = errors.New("Invalid file descriptor")
+	// ErrInvalidSampleRate show that sample rate is not between 0.0 and 1.0
+	ErrInvalidSampleRate = errors.New("Sample rate must be between 0.0 and 1.0")
+
+	// DefaultAnnotationFormat is the default format of annotation lines,
+	// %s is replaced with the annotation
+	DefaultAnnotationFormat = ">>>> %s <<<<"
+
+	// DefaultLineWrapMarker is the default prefix of continuation lines when
+	// line wrap enabled
+	DefaultLineWrapMarker = "  "
+
+	// LineTruncateMarker is appended to messages truncated by max line width
+	LineTruncateMarker = ">"
+	// MessageTruncateMarker is appended to messages truncated by max message
+	// size
+	MessageTruncateMarker = "...[TRUNCATED]"
+
+	// EOLUnix end of line used on unix
+	EOLUnix = []byte{EOL}
+	// EOLWindows end of line used on windows
+	EOLWindows = []byte{'\r', EOL}
+	// EOLCRLF end of line used by network protocols like telnet, smtp
+	EOLCRLF = []byte{'\r', EOL}
 )
 
 // Writer interface is a common definition of any writers in this package.
@@ -76,6 +110,8 @@ type Writer interface {
 
 	// flush log to disk
 	flush()
+	// flush log after every write while shutting down
+	BeginShutdown()
 
 	// hook
 	SetHook(hook Hook)
@@ -93,6 +129,15 @@ type Writer interface {
 	Retentions() int64
 	SetColored(colored bool)
 	Colored() bool
+	SetEOL(eol []byte)
+	AddMiddleware(middleware Middleware)
+
+	// structured logging
+	Build(level LevelType) *LogBuilder
+
+	// pipe
+	PipeFrom(r io.Reader, level LevelType) error
+	PipeFromWithContext(ctx context.Context, r io.Reader, level LevelType) error
 }
 
 func init() {
@@ -120,6 +165,7 @@ func NewWriterFromConfigAsFile(configFile string) (err error) {
 	}
 
 	multiWriter := new(MultiWriter)
+	multiWriter.self = multiWriter
 
 	multiWriter.level = DEBUG
 	if level := LevelFromString(config.MinLevel); level.valid() {
@@ -128,6 +174,7 @@ func NewWriterFromConfigAsFile(configFile string) (err error) {
 
 	multiWriter.closed = false
 	multiWriter.writers = make(map[LevelType]Writer)
+	multiWriter.hooks = NewHookManager()
 
 	for _, filter := range config.Filters {
 		var rotate = false
@@ -138,6 +185,8 @@ func NewWriterFromConfigAsFile(configFile string) (err error) {
 		var f *os.File
 		var blog *BLog
 		var fileLock *sync.RWMutex
+		// time cache shared by writers of the same file, like blog
+		var cache *timeFormatCacheType
 
 		// get file path
 		var filePath string
@@ -152,6 +201,8 @@ func NewWriterFromConfigAsFile(configFile string) (err error) {
 			}
 			blog = NewBLog(f)
 			fileLock = new(sync.RWMutex)
+			cache = newTimeFormatCache()
+			blog.timeCache = cache
 		} else if (rotateFile{}) != filter.RotateFile {
 			// file need logrotate
 			filePath = filter.RotateFile.Path
@@ -168,6 +219,8 @@ func NewWriterFromConfigAsFile(configFile string) (err error) {
 			}
 			blog = NewBLog(f)
 			fileLock = new(sync.RWMutex)
+			cache = newTimeFormatCache()
+			blog.timeCache = cache
 		} else if (socket{}) != filter.Socket {
 			isSocket = true
 		} else {
@@ -205,7 +258,8 @@ func NewWriterFromConfigAsFile(configFile string) (err error) {
 			}
 
 			// init a base file writer
-			writer, err := newBaseFileWriter(filePath, timeRotate)
+			// daemon starts after the file is shared
+			writer, err := openBaseFileWriter(filePath, timeRotate, DefaultFileFlag)
 			if nil != err {
 				return err
 			}
@@ -227,6 +281,8 @@ func NewWriterFromConfigAsFile(configFile string) (err error) {
 			writer.file = f
 			writer.blog = blog
 			writer.lock = fileLock
+			writer.timeCache = cache
+			writer.start()
 
 			// set color
 			multiWriter.SetColored(filter.Colored)
@@ -255,6 +311,40 @@ type BLog struct {
 
 	// closed tag
 	closed bool
+
+	// end of every line
+	eol []byte
+
+	// middlewares applied to message before written
+	middlewares []Middleware
+
+	// messages longer than wrapWidth bytes are split across multiple lines,
+	// line wrap is disabled if wrapWidth is not positive
+	wrapWidth int
+	// prefix of continuation lines
+	wrapMarker string
+
+	// lines longer than maxLineWidth bytes are truncated, disabled if
+	// maxLineWidth is not positive
+	maxLineWidth int
+	// messages longer than maxMessageSize bytes are truncated, disabled if
+	// maxMessageSize is not positive
+	maxMessageSize int
+
+	// whether the whole line is colored in the color of level when colored
+	fullLineColor bool
+
+	// time waited for lock by writes
+	contention *lockContention
+
+	// time cache of timestamps written, the global one by default
+	timeCache *timeFormatCacheType
+
+	// tee is called with every line written by write, writef and writeBatch,
+	// as bytes written without EOL, disabled if nil
+	tee func(line string)
+	// keeps bytes of the line written when tee enabled
+	teeWriter *teeWriter
 }
 
 // NewBLog create a BLog instance and return the pointer of it.
@@ -265,36 +355,324 @@ func NewBLog(in io.Writer) (blog *BLog) {
 	blog.level = TRACE
 	blog.lock = new(sync.Mutex)
 	blog.closed = false
+	blog.eol = EOLUnix
+	blog.wrapWidth = 0
+	blog.wrapMarker = DefaultLineWrapMarker
+	blog.contention = newLockContention()
+	blog.timeCache = &timeCache
 
 	blog.writer = bufio.NewWriterSize(in, DefaultBufferSize)
+	blog.teeWriter = &teeWriter{writer: blog.writer}
 	return
 }
 
 // write writes pure message with specific level
 func (blog *BLog) write(level LevelType, args ...interface{}) int {
-	blog.lock.Lock()
+	blog.lockWrite()
 	defer blog.lock.Unlock()
 
 	// 统计日志size
 	var size = 0
-	format := fmt.Sprint(args...)
-
-	blog.writer.Write(timeCache.Format())
-	blog.writer.WriteString(level.prefix())
-	blog.writer.WriteString(format)
-	blog.writer.WriteByte(EOL)
+	format := blog.applyMiddlewares(level, fmt.Sprint(args...))
 
-	size = len(timeCache.Format()) + len(level.prefix()) + len(format) + 1
+	prefix := blog.writePrefix(level)
+	size += prefix
+	size += blog.writeMessage(blog.truncate(format, prefix))
+	size += blog.writeEOL()
+	blog.teeLine()
 	return size
 }
 
 // write formats message with specific level and write it
 func (blog *BLog) writef(level LevelType, format string, args ...interface{}) int {
+	blog.lockWrite()
+	defer blog.lock.Unlock()
+
+	// 统计日志size
+	var size = 0
+
+	prefix := blog.writePrefix(level)
+	size += prefix
+
+	if len(blog.middlewares) > 0 || blog.wrapWidth > 0 || blog.maxLineWidth > 0 || blog.maxMessageSize > 0 {
+		// middlewares, line wrap and truncation need the whole message
+		buffer := new(bytes.Buffer)
+		formatTo(buffer, format, args...)
+		size += blog.writeMessage(blog.truncate(blog.applyMiddlewares(level, buffer.String()), prefix))
+	} else {
+		size += formatTo(blog.out(), format, args...)
+	}
+
+	size += blog.writeEOL()
+	blog.teeLine()
+	return size
+}
+
+// writeBytes writes message of bytes with specific level, it is not copied
+// unless middlewares, line wrap or truncation need the whole message
+func (blog *BLog) writeBytes(level LevelType, message []byte) int {
+	blog.lockWrite()
+	defer blog.lock.Unlock()
+
+	// 统计日志size
+	var size = 0
+
+	prefix := blog.writePrefix(level)
+	size += prefix
+
+	if len(blog.middlewares) > 0 || blog.wrapWidth > 0 || blog.maxLineWidth > 0 || blog.maxMessageSize > 0 {
+		size += blog.writeMessage(blog.truncate(blog.applyMiddlewares(level, string(message)), prefix))
+	} else {
+		s, _ := blog.out().Write(message)
+		size += s
+	}
+
+	size += blog.writeEOL()
+	blog.teeLine()
+	return size
+}
+
+// writePrefix writes timestamp and level prefix, the color of level is
+// written ahead and again after level prefix if full line colored.
+// It returns size written.
+func (blog *BLog) writePrefix(level LevelType) (size int) {
+	var color string
+	if blog.fullLineColor && levelsColored() {
+		color = "\x1b[" + level.colorCode() + "m"
+	}
+
+	out := blog.out()
+	format := blog.timeCache.Format()
+	out.WriteString(color)
+	out.Write(format)
+	out.WriteString(level.prefix())
+	out.WriteString(color)
+	return 2*len(color) + len(format) + len(level.prefix())
+}
+
+// writeEOL writes EOL, color is reset ahead if full line colored.
+// It returns size written.
+func (blog *BLog) writeEOL() (size int) {
+	out := blog.out()
+	if blog.fullLineColor && levelsColored() {
+		size, _ = out.WriteString(colorReset)
+	}
+
+	out.Write(blog.eol)
+	return size + len(blog.eol)
+}
+
+// truncate cuts message longer than maxMessageSize bytes first, then cuts
+// it so that the line with prefixSize bytes of prefix is no longer than
+// maxLineWidth bytes. MessageTruncateMarker or LineTruncateMarker is
+// appended to the message truncated. Prefix is never truncated.
+func (blog *BLog) truncate(message string, prefixSize int) string {
+	if blog.maxMessageSize > 0 && len(message) > blog.maxMessageSize {
+		message = cutMessage(message, blog.maxMessageSize, MessageTruncateMarker)
+	}
+
+	if blog.maxLineWidth <= 0 || prefixSize+len(message) <= blog.maxLineWidth {
+		return message
+	}
+	return cutMessage(message, blog.maxLineWidth-prefixSize, LineTruncateMarker)
+}
+
+// cutMessage cuts message to size bytes including marker appended, only
+// marker is left if size is too small
+func cutMessage(message string, size int, marker string) string {
+	cut := size - len(marker)
+	if cut < 0 {
+		cut = 0
+	}
+	// never split an utf-8 character
+	for cut > 0 && !utf8.RuneStart(message[cut]) {
+		cut--
+	}
+	return message[:cut] + marker
+}
+
+// writeMessage writes message, splits it across multiple lines prefixed
+// with wrapMarker if it is longer than wrapWidth bytes. Message is never
+// split in the middle of an utf-8 character. It returns size written.
+func (blog *BLog) writeMessage(message string) (size int) {
+	out := blog.out()
+	var s int
+	for blog.wrapWidth > 0 && len(message) > blog.wrapWidth {
+		cut := blog.wrapWidth
+		for cut > 0 && !utf8.RuneStart(message[cut]) {
+			cut--
+		}
+		if 0 == cut {
+			// character wider than wrapWidth
+			for cut = blog.wrapWidth; cut < len(message) && !utf8.RuneStart(message[cut]); cut++ {
+			}
+			if cut == len(message) {
+				break
+			}
+		}
+
+		s, _ = out.WriteString(message[:cut])
+		size += s
+		s, _ = out.Write(blog.eol)
+		size += s
+		s, _ = out.WriteString(blog.wrapMarker)
+		size += s
+		message = message[cut:]
+	}
+
+	s, _ = out.WriteString(message)
+	return size + s
+}
+
+// out return the buffer lines are written to, bytes of the line are kept
+// too if tee enabled
+func (blog *BLog) out() lineWriter {
+	if nil == blog.tee {
+		return blog.writer
+	}
+	return blog.teeWriter
+}
+
+// teeLine passes the line written to tee, bytes kept are reset
+func (blog *BLog) teeLine() {
+	if nil == blog.tee {
+		return
+	}
+
+	blog.tee(string(bytes.TrimSuffix(blog.teeWriter.line, blog.eol)))
+	blog.teeWriter.line = blog.teeWriter.line[:0]
+}
+
+// setTee set function called with every line written, nil disables it
+func (blog *BLog) setTee(tee func(line string)) {
+	blog.lock.Lock()
+	defer blog.lock.Unlock()
+	blog.tee = tee
+	blog.teeWriter.line = blog.teeWriter.line[:0]
+}
+
+// annotate writes an annotation line formatted with format after timestamp,
+// without level prefix or middlewares
+func (blog *BLog) annotate(format string, annotation string) int {
+	blog.lock.Lock()
+	defer blog.lock.Unlock()
+
+	if blog.closed {
+		return 0
+	}
+
+	line := fmt.Sprintf(format, annotation)
+	prefix := blog.timeCache.Format()
+	out := blog.out()
+	out.Write(prefix)
+	out.WriteString(" ")
+	out.WriteString(line)
+	out.Write(blog.eol)
+	blog.teeLine()
+
+	return len(prefix) + 1 + len(line) + len(blog.eol)
+}
+
+// validAnnotationFormat determines whether format has exactly one %s and no
+// other placeholders
+func validAnnotationFormat(format string) bool {
+	return 1 == strings.Count(format, "%") && 1 == strings.Count(format, "%s")
+}
+
+// writeRaw writes p as it is, without prefix, suffix or middlewares
+func (blog *BLog) writeRaw(p []byte) (n int, err error) {
+	blog.lock.Lock()
+	defer blog.lock.Unlock()
+
+	if blog.closed {
+		return 0, ErrWriterClosed
+	}
+	return blog.writer.Write(p)
+}
+
+// writeLocked calls fn with the buffer held exclusively, size written by fn
+// is returned
+func (blog *BLog) writeLocked(fn func(w io.Writer)) (n int, err error) {
+	blog.lock.Lock()
+	defer blog.lock.Unlock()
+
+	if blog.closed {
+		return 0, ErrWriterClosed
+	}
+
+	w := &sizeWriter{writer: blog.writer}
+	fn(w)
+	return w.size, nil
+}
+
+// writeBatch writes entries back-to-back holding the lock once, it returns
+// size written of every entry
+func (blog *BLog) writeBatch(entries []LogEntry) []int {
+	blog.lockWrite()
+	defer blog.lock.Unlock()
+
+	sizes := make([]int, len(entries))
+	for i, entry := range entries {
+		message := blog.applyMiddlewares(entry.Level, entry.Message)
+
+		prefix := blog.writePrefix(entry.Level)
+		sizes[i] = prefix + blog.writeMessage(blog.truncate(message, prefix)) + blog.writeEOL()
+		blog.teeLine()
+	}
+	return sizes
+}
+
+// sizeWriter counts size written to writer
+type sizeWriter struct {
+	writer io.Writer
+	size   int
+}
+
+func (w *sizeWriter) Write(p []byte) (n int, err error) {
+	n, err = w.writer.Write(p)
+	w.size += n
+	return n, err
+}
+
+// stringWriter is the output which formatTo writes partially to,
+// both bufio.Writer and bytes.Buffer implement it
+type stringWriter interface {
+	WriteString(s string) (int, error)
+	WriteByte(c byte) error
+}
+
+// lineWriter is the output which lines are written to
+type lineWriter interface {
+	io.Writer
+	stringWriter
+}
+
+// teeWriter writes to writer and keeps bytes written as the line
+type teeWriter struct {
+	writer *bufio.Writer
+	line   []byte
+}
+
+func (w *teeWriter) Write(p []byte) (int, error) {
+	w.line = append(w.line, p...)
+	return w.writer.Write(p)
+}
+
+func (w *teeWriter) WriteString(s string) (int, error) {
+	w.line = append(w.line, s...)
+	return w.writer.WriteString(s)
+}
+
+func (w *teeWriter) WriteByte(c byte) error {
+	w.line = append(w.line, c)
+	return w.writer.WriteByte(c)
+}
+
+// formatTo formats message and writes it to out, returns size written
+func formatTo(out stringWriter, format string, args ...interface{}) int {
 	// 格式化构造message
 	// 边解析边输出
 	// 使用 % 作占位符
-	blog.lock.Lock()
-	defer blog.lock.Unlock()
 
 	// 统计日志size
 	var size = 0
@@ -310,11 +688,6 @@ func (blog *BLog) writef(level LevelType, format string, args ...interface{}) in
 	var last int
 	var s int
 
-	blog.writer.Write(timeCache.Format())
-	blog.writer.WriteString(level.prefix())
-
-	size += len(timeCache.Format()) + len(level.prefix())
-
 	for i, v := range format {
 		if tag {
 			switch v {
@@ -323,7 +696,7 @@ func (blog *BLog) writef(level LevelType, format string, args ...interface{}) in
 					escape = false
 				}
 
-				s, _ = blog.writer.WriteString(fmt.Sprintf(format[tagPos:i+1], args[n]))
+				s, _ = out.WriteString(fmt.Sprintf(format[tagPos:i+1], args[n]))
 				size += s
 				n++
 				last = i + 1
@@ -331,7 +704,7 @@ func (blog *BLog) writef(level LevelType, format string, args ...interface{}) in
 			//转义符
 			case ESCAPE:
 				if escape {
-					blog.writer.WriteByte(ESCAPE)
+					out.WriteByte(ESCAPE)
 					size++
 				}
 				escape = !escape
@@ -345,19 +718,48 @@ func (blog *BLog) writef(level LevelType, format string, args ...interface{}) in
 			if PLACEHOLDER == format[i] && !escape {
 				tag = true
 				tagPos = i
-				s, _ = blog.writer.WriteString(format[last:i])
+				s, _ = out.WriteString(format[last:i])
 				size += s
 				escape = false
 			}
 		}
 	}
-	blog.writer.WriteString(format[last:])
-	blog.writer.WriteByte(EOL)
+	s, _ = out.WriteString(format[last:])
 
-	size += len(format[last:]) + 1
+	size += s
 	return size
 }
 
+// applyMiddlewares applies middlewares to message in order
+func (blog *BLog) applyMiddlewares(level LevelType, message string) string {
+	for _, middleware := range blog.middlewares {
+		message = middleware(level, message)
+	}
+	return message
+}
+
+// AddMiddleware add a middleware applied to every message before written
+func (blog *BLog) AddMiddleware(middleware Middleware) *BLog {
+	blog.lock.Lock()
+	defer blog.lock.Unlock()
+	blog.middlewares = append(blog.middlewares, middleware)
+	return blog
+}
+
+// buffered return size buffered not written to the file yet
+func (blog *BLog) buffered() int {
+	blog.lock.Lock()
+	defer blog.lock.Unlock()
+	return blog.writer.Buffered()
+}
+
+// bufferStatus return size buffered and size of the buffer
+func (blog *BLog) bufferStatus() (buffered int, size int) {
+	blog.lock.Lock()
+	defer blog.lock.Unlock()
+	return blog.writer.Buffered(), blog.writer.Buffered() + blog.writer.Available()
+}
+
 // Flush flush buffer to disk
 func (blog *BLog) flush() {
 	blog.lock.Lock()
@@ -400,6 +802,69 @@ func (blog *BLog) SetLevel(level LevelType) *BLog {
 	return blog
 }
 
+// EOL return end of every line
+func (blog *BLog) EOL() []byte {
+	return blog.eol
+}
+
+// SetEOL set end of every line, like EOLUnix or EOLWindows
+func (blog *BLog) SetEOL(eol []byte) *BLog {
+	blog.lock.Lock()
+	defer blog.lock.Unlock()
+	blog.eol = append([]byte(nil), eol...)
+	return blog
+}
+
+// SetLineWrap split messages longer than width bytes across multiple lines,
+// line wrap is disabled if width is not positive
+func (blog *BLog) SetLineWrap(width int) *BLog {
+	blog.lock.Lock()
+	defer blog.lock.Unlock()
+	blog.wrapWidth = width
+	return blog
+}
+
+// SetMaxLineWidth set max bytes of a line without EOL, messages of longer
+// lines are truncated with LineTruncateMarker appended. Timestamp and level
+// prefix, including escape sequences of colors, are counted but never
+// truncated. Truncation is disabled if width is not positive.
+func (blog *BLog) SetMaxLineWidth(width int) *BLog {
+	blog.lock.Lock()
+	defer blog.lock.Unlock()
+	blog.maxLineWidth = width
+	return blog
+}
+
+// SetMaxMessageSize set max bytes of a message, excluding prefix and EOL,
+// longer messages are truncated with MessageTruncateMarker appended, and the
+// marker is counted in size. Messages are truncated after formatted and
+// middlewares applied. Multi-line messages like stack traces are truncated
+// as a whole, so the end of the trace is lost, use WriteLocked to write such
+// messages untruncated. Truncation is disabled if size is not positive.
+func (blog *BLog) SetMaxMessageSize(size int) *BLog {
+	blog.lock.Lock()
+	defer blog.lock.Unlock()
+	blog.maxMessageSize = size
+	return blog
+}
+
+// SetFullLineColor toggle coloring the whole line in the color of level
+// when colored
+func (blog *BLog) SetFullLineColor(enabled bool) *BLog {
+	blog.lock.Lock()
+	defer blog.lock.Unlock()
+	blog.fullLineColor = enabled
+	return blog
+}
+
+// SetLineWrapMarker set prefix of continuation lines when line wrap enabled
+func (blog *BLog) SetLineWrapMarker(marker string) *BLog {
+	blog.lock.Lock()
+	defer blog.lock.Unlock()
+	blog.wrapMarker = marker
+	return blog
+}
+
 // resetFile resets file descriptor of the writer with specific file name
 func (blog *BLog) resetFile(in io.Writer) (err error) {
 	blog.lock.Lock()
@@ -413,6 +878,24 @@ func (blog *BLog) resetFile(in io.Writer) (err error) {
 	return
 }
 
+// fileWriters return file writers used by the singleton, every file writer
+// is returned only once even if it is shared by levels
+func fileWriters() (writers []*baseFileWriter) {
+	switch writer := unwrap(blog).(type) {
+	case *baseFileWriter:
+		writers = append(writers, writer)
+	case *MultiWriter:
+		added := make(map[*baseFileWriter]bool)
+		for _, levelWriter := range writer.writers {
+			if fileWriter, ok := levelWriter.(*baseFileWriter); ok && !added[fileWriter] {
+				writers = append(writers, fileWriter)
+				added[fileWriter] = true
+			}
+		}
+	}
+	return
+}
+
 // SetBufferSize set bufio buffer size in bytes
 func SetBufferSize(size int) {
 	DefaultBufferSize = size
@@ -433,6 +916,39 @@ func SetHook(hook Hook) {
 	blog.SetHook(hook)
 }
 
+// AddHook add hook with id without downtime. Only file writers support it.
+func AddHook(id string, hook Hook) {
+	if manager := hookManager(); nil != manager {
+		manager.AddHook(id, hook)
+	}
+}
+
+// RemoveHook remove hook with id
+func RemoveHook(id string) {
+	if manager := hookManager(); nil != manager {
+		manager.RemoveHook(id)
+	}
+}
+
+// ListHooks return sorted ids of hooks added
+func ListHooks() []string {
+	if manager := hookManager(); nil != manager {
+		return manager.ListHooks()
+	}
+	return nil
+}
+
+// hookManager return HookManager of the singleton, nil if not supported
+func hookManager() *HookManager {
+	switch writer := unwrap(blog).(type) {
+	case *baseFileWriter:
+		return writer.hooks
+	case *MultiWriter:
+		return writer.hooks
+	}
+	return nil
+}
+
 // SetHookLevel set when hook will be called
 func SetHookLevel(level LevelType) {
 	blog.SetHookLevel(level)
@@ -453,6 +969,433 @@ func SetColored(colored bool) {
 	blog.SetColored(colored)
 }
 
+// SetEOL set end of every line, like EOLUnix or EOLWindows
+func SetEOL(eol []byte) {
+	blog.SetEOL(eol)
+}
+
+// AddMiddleware add a middleware applied to every message before written
+func AddMiddleware(middleware Middleware) {
+	blog.AddMiddleware(middleware)
+}
+
+// SetLineWrap split messages longer than width bytes across multiple lines
+// for every log file
+func SetLineWrap(width int) {
+	for _, writer := range fileWriters() {
+		writer.SetLineWrap(width)
+	}
+}
+
+// SetSchemaVersion set schema version written in the header line of every
+// new log file
+func SetSchemaVersion(version int) {
+	for _, writer := range fileWriters() {
+		writer.SetSchemaVersion(version)
+	}
+}
+
+// SetMaxLineWidth set max bytes of a line without EOL for every log file
+func SetMaxLineWidth(width int) {
+	for _, writer := range fileWriters() {
+		writer.SetMaxLineWidth(width)
+	}
+}
+
+// SetMaxMessageSize set max bytes of a message for every log file
+func SetMaxMessageSize(size int) {
+	for _, writer := range fileWriters() {
+		writer.SetMaxMessageSize(size)
+	}
+}
+
+// SetFullLineColor toggle coloring the whole line in the color of level for
+// every log file when colored
+func SetFullLineColor(enabled bool) {
+	for _, writer := range fileWriters() {
+		writer.SetFullLineColor(enabled)
+	}
+}
+
+// SetLineWrapMarker set prefix of continuation lines for every log file
+func SetLineWrapMarker(marker string) {
+	for _, writer := range fileWriters() {
+		writer.SetLineWrapMarker(marker)
+	}
+}
+
+// Drain stops every file writer accepting new messages and flushes logs to
+// disk, the first error encountered is returned. Like Drain of writers, it
+// is one-way before Close.
+func Drain(timeout time.Duration) (err error) {
+	for _, writer := range fileWriters() {
+		if e := writer.Drain(timeout); nil != e && nil == err {
+			err = e
+		}
+	}
+	return err
+}
+
+// Annotate writes an annotation line to every log file regardless of
+// logging level, like test phase boundaries
+func Annotate(annotation string) {
+	for _, writer := range fileWriters() {
+		writer.Annotate(annotation)
+	}
+}
+
+// SetAnnotationFormat set format of annotation lines for every log file
+func SetAnnotationFormat(format string) error {
+	if !validAnnotationFormat(format) {
+		return ErrInvalidFormat
+	}
+
+	for _, writer := range fileWriters() {
+		writer.SetAnnotationFormat(format)
+	}
+	return nil
+}
+
+// SetCloseOnExit set whether log files are closed when writers closed
+func SetCloseOnExit(closeOnExit bool) {
+	for _, writer := range fileWriters() {
+		writer.SetCloseOnExit(closeOnExit)
+	}
+}
+
+// SetIntegrityCheck enables tamper-evident logging for every log file
+func SetIntegrityCheck(key []byte) error {
+	for _, writer := range fileWriters() {
+		if err := writer.SetIntegrityCheck(key); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reopen reopens every log file at the same path, used after the files are
+// renamed by external tools like logrotate
+func Reopen() error {
+	for _, writer := range fileWriters() {
+		if err := writer.Reopen(); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetOwner changes owner of every log file
+func SetOwner(uid, gid int) error {
+	for _, writer := range fileWriters() {
+		if err := writer.SetOwner(uid, gid); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetImmutable set whether every log file is append-only
+func SetImmutable(enabled bool) error {
+	for _, writer := range fileWriters() {
+		if err := writer.SetImmutable(enabled); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// MakeFileMutable removes the append-only attribute set by SetImmutable from
+// the file, so that it can be archived or deleted. It requires root or
+// CAP_LINUX_IMMUTABLE on linux.
+func MakeFileMutable(path string) error {
+	file, err := os.Open(path)
+	if nil != err {
+		return err
+	}
+	defer file.Close()
+
+	return clearAppendOnly(file)
+}
+
+// SetSamplingWriter makes every message also written to dest with
+// probability rate. All file writers share the same sample file.
+func SetSamplingWriter(rate float64, dest string) (err error) {
+	if rate < 0 || rate > 1 {
+		return ErrInvalidSampleRate
+	}
+
+	writers := fileWriters()
+	if 0 == len(writers) {
+		return nil
+	}
+
+	var sampler *baseFileWriter
+	if rate > 0 && "" != dest {
+		if sampler, err = writers[0].newSampler(dest); nil != err {
+			return err
+		}
+	}
+
+	for _, writer := range writers {
+		writer.setSampler(rate, sampler)
+	}
+	return nil
+}
+
+// SetBurstCapture makes the first burstCount occurrences of every format
+// string always written, and the following ones sampled with sampleRate,
+// for every log file
+func SetBurstCapture(burstCount int, sampleRate float64) error {
+	for _, writer := range fileWriters() {
+		if err := writer.SetBurstCapture(burstCount, sampleRate); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetBurstCaptureInterval set interval between resets of burst counters for
+// every log file
+func SetBurstCaptureInterval(interval time.Duration) {
+	for _, writer := range fileWriters() {
+		writer.SetBurstCaptureInterval(interval)
+	}
+}
+
+// SetQueueCapacity resize the queue of sizes written for every log file
+func SetQueueCapacity(n int) error {
+	for _, writer := range fileWriters() {
+		if err := writer.SetQueueCapacity(n); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnableTimeBucketStats counts messages written per level in buckets of
+// bucketSize for every file writer
+func EnableTimeBucketStats(bucketSize time.Duration) {
+	for _, writer := range fileWriters() {
+		writer.EnableTimeBucketStats(bucketSize)
+	}
+}
+
+// SetTimeBucketRetention set duration time buckets kept for every file writer
+func SetTimeBucketRetention(retention time.Duration) {
+	for _, writer := range fileWriters() {
+		writer.SetTimeBucketRetention(retention)
+	}
+}
+
+// TimeBucketStats return counts of messages per level of buckets starting
+// between from and to inclusive, added up over every file writer
+func TimeBucketStats(from, to time.Time) map[time.Time]map[LevelType]int64 {
+	result := make(map[time.Time]map[LevelType]int64)
+	for _, writer := range fileWriters() {
+		if stats := writer.timeBucketStats(); nil != stats {
+			stats.between(from, to, result)
+		}
+	}
+	return result
+}
+
+// Histogram create a LevelHistogram counting messages written per level by
+// every file writer in the last buckets of bucketDuration
+func Histogram(buckets int, bucketDuration time.Duration) *LevelHistogram {
+	histogram := newLevelHistogram(buckets, bucketDuration)
+	for _, writer := range fileWriters() {
+		writer.setLevelHistogram(histogram)
+	}
+	return histogram
+}
+
+// Suspend blocks writes of every file writer until Resume. Writers
+// suspended are resumed if any of them fails.
+func Suspend() error {
+	writers := fileWriters()
+	for i, writer := range writers {
+		if err := writer.Suspend(); nil != err {
+			for _, suspended := range writers[:i] {
+				suspended.Resume()
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Resume unblocks writes of every file writer blocked by Suspend
+func Resume() {
+	for _, writer := range fileWriters() {
+		writer.Resume()
+	}
+}
+
+// SetArchiveDir set directory files rotated are moved into for every log
+// file
+func SetArchiveDir(dir string) {
+	for _, writer := range fileWriters() {
+		writer.SetArchiveDir(dir)
+	}
+}
+
+// SetDaemonInterval set interval of daemon ticks for every file writer
+func SetDaemonInterval(d time.Duration) {
+	for _, writer := range fileWriters() {
+		writer.SetDaemonInterval(d)
+	}
+}
+
+// SetTimeFormat set layout and time zone of timestamps written by every
+// file writer
+func SetTimeFormat(layout string, location *time.Location) error {
+	for _, writer := range fileWriters() {
+		if err := writer.SetTimeFormat(layout, location); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetLockContentionTracking toggle recording of time waited for the write
+// lock for every file writer
+func SetLockContentionTracking(enabled bool) {
+	for _, writer := range fileWriters() {
+		writer.SetLockContentionTracking(enabled)
+	}
+}
+
+// SetContentionThreshold set lock wait counted as a contention event for
+// every file writer
+func SetContentionThreshold(d time.Duration) {
+	for _, writer := range fileWriters() {
+		writer.SetContentionThreshold(d)
+	}
+}
+
+// SetRotateAt do logrotate once a day at hour:minute local time for every
+// file writer
+func SetRotateAt(hour, minute int) error {
+	for _, writer := range fileWriters() {
+		if err := writer.SetRotateAt(hour, minute); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetCallerLevel set logging level of messages written from source files
+// matching pattern for every file writer
+func SetCallerLevel(pattern string, level LevelType) {
+	for _, writer := range fileWriters() {
+		writer.SetCallerLevel(pattern, level)
+	}
+}
+
+// SetLatencyTracking toggle tracking of write latency, samples of every file
+// writer are kept together
+func SetLatencyTracking(enabled bool) {
+	var tracker *latencyTracker
+	if enabled {
+		tracker = newLatencyTracker(LatencySamples)
+	}
+	for _, writer := range fileWriters() {
+		writer.setLatencyTracker(tracker)
+	}
+}
+
+// Percentile get write latency at percentile p of samples kept, 0 is
+// returned if latency tracking is disabled
+func Percentile(p float64) time.Duration {
+	writers := fileWriters()
+	if 0 == len(writers) {
+		return 0
+	}
+	// samples are shared by every file writer
+	return writers[0].Percentile(p)
+}
+
+// ResetLatency drops samples of write latency kept
+func ResetLatency() {
+	for _, writer := range fileWriters() {
+		writer.ResetLatency()
+	}
+}
+
+// SetResetErrorHandler set handler called if the new file can not be opened
+// while logrotate for every file writer
+func SetResetErrorHandler(handler func(err error)) {
+	for _, writer := range fileWriters() {
+		writer.SetResetErrorHandler(handler)
+	}
+}
+
+// SetAlertThreshold calls fn once count messages exceed level are written
+// within window, counted for every file writer separately
+func SetAlertThreshold(level LevelType, count int, window time.Duration, fn AlertFunc) {
+	for _, writer := range fileWriters() {
+		writer.SetAlertThreshold(level, count, window, fn)
+	}
+}
+
+// SetWriteObserver set observer called after every write for every file
+// writer
+func SetWriteObserver(observer WriteObserver) {
+	for _, writer := range fileWriters() {
+		writer.SetWriteObserver(observer)
+	}
+}
+
+// SetAutoLevel lowers level of every file writer to lowerTo when more than
+// threshold messages exceed trigger level within window
+func SetAutoLevel(trigger LevelType, threshold int, window time.Duration, lowerTo LevelType) {
+	for _, writer := range fileWriters() {
+		writer.SetAutoLevel(trigger, threshold, window, lowerTo)
+	}
+}
+
+// SetAutoLevelCooldown set duration level kept lowered by auto level
+// adjustment for every file writer
+func SetAutoLevelCooldown(cooldown time.Duration) {
+	for _, writer := range fileWriters() {
+		writer.SetAutoLevelCooldown(cooldown)
+	}
+}
+
+// FileStats get status of every log file
+func FileStats() []Stats {
+	var stats []Stats
+	for _, writer := range fileWriters() {
+		stats = append(stats, writer.Stats())
+	}
+	return stats
+}
+
+// DebugInfo get a snapshot of internal state of every file writer
+func DebugInfo() []map[string]interface{} {
+	var infos []map[string]interface{}
+	for _, writer := range fileWriters() {
+		infos = append(infos, writer.DebugInfo())
+	}
+	return infos
+}
+
+// Rotate do a size base logrotate at once for every file writer.
+// ErrNotSupported is returned if there is no file writer.
+func Rotate() error {
+	writers := fileWriters()
+	if 0 == len(writers) {
+		return ErrNotSupported
+	}
+
+	for _, writer := range writers {
+		if err := writer.Rotate(); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
 // TimeRotated get timeRotated
 func TimeRotated() bool {
 	return blog.TimeRotated()
@@ -468,6 +1411,21 @@ func Retentions() int64 {
 	return blog.Retentions()
 }
 
+// SetRotationPolicy set policy of logrotate for every log file
+func SetRotationPolicy(policy RotationPolicy) {
+	for _, writer := range fileWriters() {
+		writer.SetRotationPolicy(policy)
+	}
+}
+
+// SetRotateNamer set namer of files rotated by size or line base logrotate
+// for every log file
+func SetRotateNamer(namer RotateNamer) {
+	for _, writer := range fileWriters() {
+		writer.SetRotateNamer(namer)
+	}
+}
+
 // SetRetentions set how many logs will keep after logrotate
 func SetRetentions(retentions int64) {
 	blog.SetRetentions(retentions)
@@ -493,11 +1451,122 @@ func SetRotateLines(rotateLines int) {
 	blog.SetRotateLines(rotateLines)
 }
 
+// PipeFrom logs every line read from r with given level until r reaches EOF
+func PipeFrom(r io.Reader, level LevelType) error {
+	return blog.PipeFrom(r, level)
+}
+
+// PipeFromWithContext logs every line read from r with given level until r
+// reaches EOF or ctx is done
+func PipeFromWithContext(ctx context.Context, r io.Reader, level LevelType) error {
+	return blog.PipeFromWithContext(ctx, r, level)
+}
+
+// BeginShutdown makes every following write flushed synchronously,
+// call it when the program is going to exit
+func BeginShutdown() {
+	blog.BeginShutdown()
+}
+
 // Flush flush logs to disk
 func Flush() {
 	blog.flush()
 }
 
+// WriteRaw writes pre-formatted p to the log file as it is.
+// ErrNotSupported is returned if blog4go is not initialized as a single
+// file writer.
+func WriteRaw(p []byte) (n int, err error) {
+	writer, ok := unwrap(blog).(*baseFileWriter)
+	if !ok {
+		return 0, ErrNotSupported
+	}
+	return writer.WriteRaw(p)
+}
+
+// WriteLocked calls fn with the buffer of the log file held exclusively.
+// ErrNotSupported is returned if blog4go is not initialized as a single
+// file writer.
+func WriteLocked(fn func(w io.Writer)) error {
+	writer, ok := unwrap(blog).(*baseFileWriter)
+	if !ok {
+		return ErrNotSupported
+	}
+	return writer.WriteLocked(fn)
+}
+
+// WriteBatch writes entries to the log file without interleaving.
+// ErrNotSupported is returned if blog4go is not initialized as a single
+// file writer.
+func WriteBatch(entries []LogEntry) error {
+	writer, ok := unwrap(blog).(*baseFileWriter)
+	if !ok {
+		return ErrNotSupported
+	}
+	return writer.WriteBatch(entries)
+}
+
+// ExposeExpvars publishes metrics of the log file under blog4go.name in
+// expvar. ErrNotSupported is returned if blog4go is not initialized as a
+// single file writer.
+func ExposeExpvars(name string) (func(), error) {
+	writer, ok := unwrap(blog).(*baseFileWriter)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return writer.ExposeExpvars(name), nil
+}
+
+// Clone creates a writer writing to the same log file, with its own level,
+// colored and hooks. ErrNotSupported is returned if blog4go is not
+// initialized as a single file writer.
+func Clone() (Writer, error) {
+	writer, ok := unwrap(blog).(*baseFileWriter)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return writer.Clone(), nil
+}
+
+// StartShipper starts shipping new lines of the log file to the http
+// endpoint. ErrNotSupported is returned if blog4go is not initialized as a
+// single file writer.
+func StartShipper(endpoint string, batchSize int, interval time.Duration) (stop func(), err error) {
+	writer, ok := unwrap(blog).(*baseFileWriter)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return writer.StartShipper(endpoint, batchSize, interval)
+}
+
+// WriteTo copies data written to the log file since the last call to dst.
+// ErrNotSupported is returned if blog4go is not initialized as a single
+// file writer.
+func WriteTo(dst io.Writer) (n int64, err error) {
+	writer, ok := unwrap(blog).(*baseFileWriter)
+	if !ok {
+		return 0, ErrNotSupported
+	}
+	return writer.WriteTo(dst)
+}
+
+// Log static function for writing with level, like levels registered by
+// RegisterLevel
+func Log(level LevelType, args ...interface{}) {
+	if !level.valid() || level < blog.Level() {
+		return
+	}
+	blog.write(level, args...)
+}
+
+// Logf static function for writing formatted with level
+func Logf(level LevelType, format string, args ...interface{}) {
+	if !level.valid() || level < blog.Level() {
+		return
+	}
+	blog.writef(level, format, args...)
+}
+
 // Trace static function for Trace
 func Trace(args ...interface{}) {
 	blog.Trace(args...)
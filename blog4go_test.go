@@ -3,7 +3,9 @@
 package blog4go
 
 import (
+	"bytes"
 	"os/exec"
+	"strings"
 	"testing"
 	"time"
 )
@@ -108,3 +110,178 @@ func TestGlobalOperation(t *testing.T) {
 
 	SetBufferSize(0)
 }
+
+func TestBLogEOL(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	blog := NewBLog(buffer)
+
+	if !bytes.Equal(EOLUnix, blog.EOL()) {
+		t.Error("default EOL should be EOLUnix")
+	}
+
+	blog.SetEOL(EOLWindows)
+	size := blog.write(INFO, "windows")
+	size += blog.writef(INFO, "%s", "windows")
+	blog.flush()
+
+	if 2 != bytes.Count(buffer.Bytes(), EOLWindows) || size != buffer.Len() {
+		t.Errorf("message should end with EOLWindows. content: %q", buffer.String())
+	}
+}
+
+func TestBLogFullLineColor(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	blog := NewBLog(buffer)
+	blog.SetFullLineColor(true)
+
+	// not colored
+	initPrefix(false)
+	size := blog.write(INFO, "plain")
+	blog.flush()
+	if strings.Contains(buffer.String(), "\x1b[") || size != buffer.Len() {
+		t.Errorf("line should not be colored. content: %q", buffer.String())
+	}
+
+	initPrefix(true)
+	defer initPrefix(false)
+	buffer.Reset()
+	size = blog.write(ERROR, "error")
+	size += blog.writef(WARNING, "%s", "warn")
+	blog.flush()
+
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if 2 != len(lines) || size != buffer.Len() {
+		t.Fatalf("lines written wrong. content: %q", buffer.String())
+	}
+	if !strings.HasPrefix(lines[0], "\x1b[31m") || !strings.HasSuffix(lines[0], "\x1b[31merror\x1b[0m") {
+		t.Errorf("line should be colored in red. line: %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "\x1b[33m") || !strings.HasSuffix(lines[1], "\x1b[33mwarn\x1b[0m") {
+		t.Errorf("line should be colored in yellow. line: %q", lines[1])
+	}
+}
+
+func TestBLogMaxLineWidth(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	blog := NewBLog(buffer)
+
+	// timestamp and level prefix take 29 bytes
+	blog.SetMaxLineWidth(36)
+	size := blog.write(INFO, "message too long")
+	size += blog.writef(INFO, "%s", "short")
+	size += blog.writef(INFO, "%s", "中文中文")
+	blog.flush()
+
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if 3 != len(lines) || size != buffer.Len() {
+		t.Fatalf("lines written wrong. content: %q", buffer.String())
+	}
+	if 36 != len(lines[0]) || !strings.HasSuffix(lines[0], "] messag>") {
+		t.Errorf("message should be truncated. line: %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], "] short") {
+		t.Errorf("short message should not be truncated. line: %q", lines[1])
+	}
+	// never split an utf-8 character
+	if !strings.HasSuffix(lines[2], "] 中文>") {
+		t.Errorf("utf-8 character should not be split. line: %q", lines[2])
+	}
+
+	// prefix is never truncated
+	buffer.Reset()
+	blog.SetMaxLineWidth(10)
+	blog.write(INFO, "message")
+	blog.flush()
+	if !strings.HasSuffix(buffer.String(), "[INFO] >\n") {
+		t.Errorf("prefix should not be truncated. content: %q", buffer.String())
+	}
+}
+
+func TestBLogMaxMessageSize(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	blog := NewBLog(buffer)
+
+	size := len(MessageTruncateMarker) + 4
+	blog.SetMaxMessageSize(size)
+	written := blog.write(INFO, "message too long, really")
+	written += blog.writef(INFO, "%s %d", "ok", 1)
+	written += blog.writef(INFO, "%s", "中文中文中文中文")
+	blog.flush()
+
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if 3 != len(lines) || written != buffer.Len() {
+		t.Fatalf("lines written wrong. content: %q", buffer.String())
+	}
+	if !strings.HasSuffix(lines[0], "] mess"+MessageTruncateMarker) {
+		t.Errorf("message should be truncated. line: %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], "] ok 1") {
+		t.Errorf("short message should not be truncated. line: %q", lines[1])
+	}
+	// never split an utf-8 character
+	if !strings.HasSuffix(lines[2], "] 中"+MessageTruncateMarker) {
+		t.Errorf("utf-8 character should not be split. line: %q", lines[2])
+	}
+
+	// line width applies after message size
+	buffer.Reset()
+	blog.SetMaxLineWidth(len(lines[0]) - 1)
+	blog.write(INFO, "message too long, really")
+	blog.flush()
+	if !strings.HasSuffix(buffer.String(), "] mess...[TRUNCATE>\n") {
+		t.Errorf("message truncated wrong with max line width. content: %q", buffer.String())
+	}
+}
+
+func TestBLogLineWrap(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	blog := NewBLog(buffer)
+
+	blog.SetLineWrap(4)
+	size := blog.write(INFO, "0123456789")
+	blog.SetLineWrapMarker("> ")
+	size += blog.writef(INFO, "%s", "01234")
+	blog.flush()
+
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if 5 != len(lines) || size != buffer.Len() {
+		t.Fatalf("message should be wrapped. content: %q", buffer.String())
+	}
+	if !strings.HasSuffix(lines[0], "] 0123") || "  4567" != lines[1] || "  89" != lines[2] {
+		t.Errorf("message wrapped wrong. content: %q", buffer.String())
+	}
+	if !strings.HasSuffix(lines[3], "] 0123") || "> 4" != lines[4] {
+		t.Errorf("message wrapped wrong with marker. content: %q", buffer.String())
+	}
+
+	// never split an utf-8 character
+	buffer.Reset()
+	blog.SetLineWrap(2)
+	blog.write(INFO, "中文")
+	blog.flush()
+	if !strings.HasSuffix(buffer.String(), "] 中\n> 文\n") {
+		t.Errorf("utf-8 character should not be split. content: %q", buffer.String())
+	}
+}
+
+func TestBLogAnnotate(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	blog := NewBLog(buffer)
+
+	var lines []string
+	blog.setTee(func(line string) {
+		lines = append(lines, line)
+	})
+	size := blog.annotate(DefaultAnnotationFormat, "starting TestFoo")
+	blog.flush()
+
+	if size != buffer.Len() || 1 != len(lines) || lines[0]+"\n" != buffer.String() {
+		t.Errorf("annotation should be written through tee. content: %q, lines: %q", buffer.String(), lines)
+	}
+
+	// not written after closed
+	blog.Close()
+	if 0 != blog.annotate(DefaultAnnotationFormat, "closed") {
+		t.Error("annotation should not be written after closed")
+	}
+}
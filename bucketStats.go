@@ -0,0 +1,92 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTimeBucketRetention is the default duration time bucket stats
+	// kept
+	DefaultTimeBucketRetention = 24 * time.Hour
+)
+
+// timeBucketStats counts messages written per level in time buckets.
+// Buckets older than retention are pruned.
+type timeBucketStats struct {
+	bucketSize time.Duration
+	retention  time.Duration
+
+	// counts of levels, by start time of bucket
+	buckets map[time.Time]map[LevelType]int64
+
+	lock *sync.Mutex
+}
+
+// newTimeBucketStats create a timeBucketStats
+func newTimeBucketStats(bucketSize time.Duration, retention time.Duration) *timeBucketStats {
+	stats := new(timeBucketStats)
+	stats.bucketSize = bucketSize
+	stats.retention = retention
+	stats.buckets = make(map[time.Time]map[LevelType]int64)
+	stats.lock = new(sync.Mutex)
+	return stats
+}
+
+// add counts a message with level written at t
+func (stats *timeBucketStats) add(level LevelType, t time.Time) {
+	stats.lock.Lock()
+	defer stats.lock.Unlock()
+
+	start := t.Truncate(stats.bucketSize)
+	bucket, ok := stats.buckets[start]
+	if !ok {
+		bucket = make(map[LevelType]int64)
+		stats.buckets[start] = bucket
+	}
+	bucket[level]++
+}
+
+// sweep prunes buckets ended before retention
+func (stats *timeBucketStats) sweep(now time.Time) {
+	stats.lock.Lock()
+	defer stats.lock.Unlock()
+
+	expired := now.Add(-stats.retention)
+	for start := range stats.buckets {
+		if start.Add(stats.bucketSize).Before(expired) {
+			delete(stats.buckets, start)
+		}
+	}
+}
+
+// setRetention set duration buckets kept
+func (stats *timeBucketStats) setRetention(retention time.Duration) {
+	stats.lock.Lock()
+	defer stats.lock.Unlock()
+	stats.retention = retention
+}
+
+// between adds up counts of buckets starting between from and to inclusive
+// into result
+func (stats *timeBucketStats) between(from, to time.Time, result map[time.Time]map[LevelType]int64) {
+	stats.lock.Lock()
+	defer stats.lock.Unlock()
+
+	for start, bucket := range stats.buckets {
+		if start.Before(from) || start.After(to) {
+			continue
+		}
+
+		counts, ok := result[start]
+		if !ok {
+			counts = make(map[LevelType]int64)
+			result[start] = counts
+		}
+		for level, count := range bucket {
+			counts[level] += count
+		}
+	}
+}
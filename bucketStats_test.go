@@ -0,0 +1,75 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestTimeBucketStats(t *testing.T) {
+	stats := newTimeBucketStats(time.Minute, time.Hour)
+
+	begin := time.Date(2017, 6, 30, 12, 0, 0, 0, time.Local)
+	stats.add(INFO, begin)
+	stats.add(INFO, begin.Add(30*time.Second))
+	stats.add(ERROR, begin.Add(59*time.Second))
+	stats.add(INFO, begin.Add(time.Minute))
+	stats.add(WARNING, begin.Add(2*time.Hour))
+
+	result := make(map[time.Time]map[LevelType]int64)
+	stats.between(begin, begin.Add(time.Minute), result)
+	if 2 != len(result) || 2 != result[begin][INFO] || 1 != result[begin][ERROR] || 1 != result[begin.Add(time.Minute)][INFO] {
+		t.Errorf("time bucket stats wrong. result: %v", result)
+	}
+
+	// buckets added up
+	stats.between(begin, begin, result)
+	if 4 != result[begin][INFO] {
+		t.Errorf("time bucket stats should be added up. result: %v", result)
+	}
+
+	stats.sweep(begin.Add(2 * time.Hour))
+	result = make(map[time.Time]map[LevelType]int64)
+	stats.between(begin, begin.Add(3*time.Hour), result)
+	if 1 != len(result) || 1 != result[begin.Add(2*time.Hour)][WARNING] {
+		t.Errorf("expired buckets should be pruned. result: %v", result)
+	}
+}
+
+func TestBaseFileWriterTimeBucketStats(t *testing.T) {
+	err := NewBaseFileWriter("/tmp/bucket.log", false)
+	defer func() {
+		Close()
+		os.Remove("/tmp/bucket.log")
+	}()
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+
+	from := timeCache.Now().Truncate(time.Minute)
+	to := from.Add(time.Minute)
+
+	Info("not counted")
+	EnableTimeBucketStats(time.Minute)
+	SetTimeBucketRetention(time.Hour)
+	Info("info")
+	Errorf("%s", "error")
+	Error("error")
+
+	result := TimeBucketStats(from, to)
+	var infos, errs int64
+	for _, counts := range result {
+		infos += counts[INFO]
+		errs += counts[ERROR]
+	}
+	if 1 != infos || 2 != errs {
+		t.Errorf("time bucket stats wrong. result: %v", result)
+	}
+
+	EnableTimeBucketStats(0)
+	if 0 != len(TimeBucketStats(from, to)) {
+		t.Error("time bucket stats should be disabled")
+	}
+}
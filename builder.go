@@ -0,0 +1,167 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// TagsKey is the key of tags field
+	TagsKey = "tags"
+)
+
+// builderPool pools LogBuilder to avoid allocations for every message
+var builderPool = sync.Pool{
+	New: func() interface{} {
+		return &LogBuilder{fields: make([]byte, 0, 256), line: make([]byte, 0, 512)}
+	},
+}
+
+// bytesWriter is implemented by writers able to write message of bytes
+// without formatting it, LogBuilder writes to them without allocations.
+// message must not be kept after writeBytes returns.
+type bytesWriter interface {
+	writeBytes(level LevelType, message []byte)
+}
+
+// LogBuilder builds a message with fields appended as key=value, like
+// Build(INFO).Str("user", "tom").Int("cost", 12).Msg("login")
+// writes "login user=tom cost=12". Values with spaces, quotes or '=' are
+// quoted. A LogBuilder must not be used after Msg called.
+type LogBuilder struct {
+	writer Writer
+	level  LevelType
+
+	// fields already appended, every field starts with a space
+	fields []byte
+	// message followed by fields, written to bytesWriter
+	line []byte
+}
+
+// Build return a LogBuilder of the message with level. nil is returned if
+// level is lower than logging level, methods of nil builder do nothing.
+func Build(level LevelType) *LogBuilder {
+	return newLogBuilder(blog, level)
+}
+
+// newLogBuilder return a LogBuilder writing to writer
+func newLogBuilder(writer Writer, level LevelType) *LogBuilder {
+	if nil == writer || level < writer.Level() {
+		return nil
+	}
+
+	builder := builderPool.Get().(*LogBuilder)
+	builder.writer = writer
+	builder.level = level
+	builder.fields = builder.fields[:0]
+	return builder
+}
+
+// Str append a string field
+func (builder *LogBuilder) Str(key, val string) *LogBuilder {
+	if nil == builder {
+		return nil
+	}
+
+	builder.appendKey(key)
+	if needsQuote(val) {
+		builder.fields = strconv.AppendQuote(builder.fields, val)
+	} else {
+		builder.fields = append(builder.fields, val...)
+	}
+	return builder
+}
+
+// Int append an integer field
+func (builder *LogBuilder) Int(key string, val int64) *LogBuilder {
+	if nil == builder {
+		return nil
+	}
+
+	builder.appendKey(key)
+	builder.fields = strconv.AppendInt(builder.fields, val, 10)
+	return builder
+}
+
+// Float append a float field
+func (builder *LogBuilder) Float(key string, val float64) *LogBuilder {
+	if nil == builder {
+		return nil
+	}
+
+	builder.appendKey(key)
+	builder.fields = strconv.AppendFloat(builder.fields, val, 'g', -1, 64)
+	return builder
+}
+
+// Bool append a bool field
+func (builder *LogBuilder) Bool(key string, val bool) *LogBuilder {
+	if nil == builder {
+		return nil
+	}
+
+	builder.appendKey(key)
+	builder.fields = strconv.AppendBool(builder.fields, val)
+	return builder
+}
+
+// Tags append tags as field named tags, like tags=[env:prod,service:api].
+// Tags can be filtered in query, like tags CONTAINS "env:prod".
+func (builder *LogBuilder) Tags(tags ...string) *LogBuilder {
+	if nil == builder || 0 == len(tags) {
+		return builder
+	}
+
+	builder.appendKey(TagsKey)
+	builder.fields = append(builder.fields, '[')
+	for i, tag := range tags {
+		if i > 0 {
+			builder.fields = append(builder.fields, ',')
+		}
+		builder.fields = append(builder.fields, tag...)
+	}
+	builder.fields = append(builder.fields, ']')
+	return builder
+}
+
+// Err append err as field named error, nil err is ignored
+func (builder *LogBuilder) Err(err error) *LogBuilder {
+	if nil == builder || nil == err {
+		return builder
+	}
+
+	return builder.Str("error", err.Error())
+}
+
+// Msg writes message followed by fields appended, then builder is put back
+// to pool
+func (builder *LogBuilder) Msg(message string) {
+	if nil == builder {
+		return
+	}
+
+	if writer, ok := builder.writer.(bytesWriter); ok {
+		builder.line = append(append(builder.line[:0], message...), builder.fields...)
+		writer.writeBytes(builder.level, builder.line)
+	} else {
+		builder.writer.write(builder.level, message+string(builder.fields))
+	}
+
+	builder.writer = nil
+	builderPool.Put(builder)
+}
+
+// appendKey append " key=" to fields
+func (builder *LogBuilder) appendKey(key string) {
+	builder.fields = append(builder.fields, ' ')
+	builder.fields = append(builder.fields, key...)
+	builder.fields = append(builder.fields, '=')
+}
+
+// needsQuote determines whether val must be quoted as a field value
+func needsQuote(val string) bool {
+	return "" == val || strings.ContainsAny(val, " \t\r\n\"=")
+}
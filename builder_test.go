@@ -0,0 +1,192 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLogBuilder(t *testing.T) {
+	writer, err := newRingBufferWriter(10)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	writer.SetLevel(INFO)
+
+	newLogBuilder(writer, INFO).
+		Str("user", "tom").
+		Str("agent", "go client").
+		Int("cost", -12).
+		Float("ratio", 0.5).
+		Bool("ok", true).
+		Err(errors.New("bad request")).
+		Err(nil).
+		Msg("login")
+
+	// below logging level
+	if builder := newLogBuilder(writer, DEBUG); nil != builder {
+		t.Error("builder below logging level should be nil")
+	}
+	newLogBuilder(writer, DEBUG).Str("user", "tom").Int("cost", 1).Msg("ignored")
+
+	// builder reused from pool should not keep old fields
+	newLogBuilder(writer, ERROR).Str("empty", "").Msg("second")
+
+	entries := writer.Entries()
+	if 2 != len(entries) {
+		t.Fatalf("builder wrote wrong messages. count: %d", len(entries))
+	}
+
+	if expected := `login user=tom agent="go client" cost=-12 ratio=0.5 ok=true error="bad request"`; INFO != entries[0].Level || expected != entries[0].Message {
+		t.Errorf("message built wrong. message: %s", entries[0].Message)
+	}
+	if ERROR != entries[1].Level || `second empty=""` != entries[1].Message {
+		t.Errorf("message built wrong. message: %s", entries[1].Message)
+	}
+
+	if strings.Contains(entries[1].Message, "user") {
+		t.Errorf("builder reused should be reset. message: %s", entries[1].Message)
+	}
+}
+
+func TestLogBuilderWithoutWriter(t *testing.T) {
+	singleton := blog
+	blog = nil
+	defer func() {
+		blog = singleton
+	}()
+
+	if nil != Build(INFO) {
+		t.Error("builder without writer should be nil")
+	}
+	Build(INFO).Str("user", "tom").Msg("ignored")
+}
+
+func TestLogBuilderTags(t *testing.T) {
+	writer, err := newRingBufferWriter(10)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	newLogBuilder(writer, INFO).Str("user", "tom").Tags("env:prod", "service:api").Msg("login")
+	newLogBuilder(writer, INFO).Tags().Msg("no tags")
+
+	entries := writer.Entries()
+	if "login user=tom tags=[env:prod,service:api]" != entries[0].Message || "no tags" != entries[1].Message {
+		t.Errorf("tags appended wrong. messages: %s, %s", entries[0].Message, entries[1].Message)
+	}
+
+	filter, err := ParseQuery(`tags CONTAINS "service:api"`)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	if !filter.Match(entries[0]) || filter.Match(entries[1]) {
+		t.Error("tags filtered wrong")
+	}
+
+	if _, err = ParseQuery(`tags = "env:prod"`); nil == err {
+		t.Error("tags support only CONTAINS")
+	}
+}
+
+func TestWriterBuild(t *testing.T) {
+	writer, err := newRingBufferWriter(10)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	chain := NewWriterChain(writer).Use(func(level LevelType, message string) (LevelType, string, bool) {
+		return level, strings.ToUpper(message), true
+	})
+
+	writer.Build(INFO).Str("user", "tom").Msg("login")
+	chain.Build(INFO).Str("user", "tom").Msg("logout")
+
+	entries := writer.Entries()
+	if 2 != len(entries) {
+		t.Fatalf("builder wrote wrong messages. count: %d", len(entries))
+	}
+	if "login user=tom" != entries[0].Message {
+		t.Errorf("message built wrong. message: %s", entries[0].Message)
+	}
+	if "LOGOUT USER=TOM" != entries[1].Message {
+		t.Errorf("builder of wrapper should write through wrapper. message: %s", entries[1].Message)
+	}
+}
+
+func TestLogBuilderFileWriter(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/builder.log", false)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/builder.log")
+	}()
+
+	hook := NewMyHook()
+	writer.SetHook(hook)
+	writer.SetHookAsync(false)
+
+	newLogBuilder(writer, INFO).Str("user", "tom").Int("cost", 12).Msg("login")
+	newLogBuilder(writer, ERROR).Bool("ok", false).Msg("logout")
+	writer.flush()
+
+	content, err := ioutil.ReadFile("/tmp/builder.log")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	if 2 != len(lines) {
+		t.Fatalf("builder wrote wrong lines. content: %s", content)
+	}
+	if !strings.HasSuffix(stripColor(lines[0]), "[INFO] login user=tom cost=12") {
+		t.Errorf("line built wrong. line: %s", lines[0])
+	}
+	if !strings.HasSuffix(stripColor(lines[1]), "[ERROR] logout ok=false") {
+		t.Errorf("line built wrong. line: %s", lines[1])
+	}
+
+	// hook gets a copy of the line, not the buffer reused by builder
+	if "logout ok=false" != hook.Message() {
+		t.Errorf("hook got wrong message: %s", hook.Message())
+	}
+}
+
+func TestLogBuilderAllocs(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/builder.log", false)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/builder.log")
+	}()
+
+	allocs := testing.AllocsPerRun(100, func() {
+		newLogBuilder(writer, INFO).Str("user", "tom").Int("cost", 12).Bool("ok", true).Msg("login")
+	})
+	if allocs > 0 {
+		t.Errorf("builder writing to file writer allocates %v times", allocs)
+	}
+}
+
+func BenchmarkEntryPool(b *testing.B) {
+	writer, err := newBaseFileWriter("/tmp/builder.log", false)
+	if nil != err {
+		b.Fatal(err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/builder.log")
+	}()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		newLogBuilder(writer, INFO).Str("user", "tom").Int("cost", 12).Bool("ok", true).Msg("login")
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultBurstInterval is the default interval between resets of burst
+	// counters
+	DefaultBurstInterval = 1 * time.Minute
+)
+
+// burstCapture writes the first count occurrences of a message, and samples
+// the following ones with probability rate. Counters are reset every
+// interval so that new bursts can be captured fully.
+type burstCapture struct {
+	count int
+	rate  float64
+
+	interval time.Duration
+	// time when counters were reset last time
+	lastReset time.Time
+
+	// occurrences of messages from last reset, by format string
+	counters map[string]int
+
+	lock *sync.Mutex
+}
+
+// newBurstCapture create a burstCapture
+func newBurstCapture(count int, rate float64, interval time.Duration) *burstCapture {
+	capture := new(burstCapture)
+	capture.count = count
+	capture.rate = rate
+	capture.interval = interval
+	capture.lastReset = time.Now()
+	capture.counters = make(map[string]int)
+	capture.lock = new(sync.Mutex)
+	return capture
+}
+
+// allow determines whether message with key should be written
+func (capture *burstCapture) allow(key string) bool {
+	capture.lock.Lock()
+	defer capture.lock.Unlock()
+
+	capture.counters[key]++
+	if capture.counters[key] <= capture.count {
+		return true
+	}
+	return rand.Float64() < capture.rate
+}
+
+// sweep resets counters if interval passed since last reset
+func (capture *burstCapture) sweep(now time.Time) {
+	capture.lock.Lock()
+	defer capture.lock.Unlock()
+
+	if now.Sub(capture.lastReset) < capture.interval {
+		return
+	}
+
+	capture.counters = make(map[string]int)
+	capture.lastReset = now
+}
+
+// setInterval set interval between resets
+func (capture *burstCapture) setInterval(interval time.Duration) {
+	capture.lock.Lock()
+	defer capture.lock.Unlock()
+	capture.interval = interval
+}
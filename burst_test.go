@@ -0,0 +1,84 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBurstCapture(t *testing.T) {
+	capture := newBurstCapture(2, 0, time.Minute)
+
+	if !capture.allow("a") || !capture.allow("a") || capture.allow("a") {
+		t.Error("only the first 2 occurrences should be allowed with 0 rate")
+	}
+	if !capture.allow("b") {
+		t.Error("occurrences of another message should be counted separately")
+	}
+
+	// not reset before interval passed
+	capture.sweep(time.Now())
+	if capture.allow("a") {
+		t.Error("counters should not be reset before interval passed")
+	}
+
+	capture.sweep(time.Now().Add(time.Minute))
+	if !capture.allow("a") {
+		t.Error("counters should be reset after interval passed")
+	}
+
+	capture = newBurstCapture(1, 1, time.Minute)
+	for i := 0; i < 10; i++ {
+		if !capture.allow("a") {
+			t.Error("every occurrence should be allowed with rate 1")
+		}
+	}
+}
+
+func TestBaseFileWriterBurstCapture(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/burst.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/burst.log")
+	}()
+
+	if ErrInvalidSampleRate != writer.SetBurstCapture(3, -0.1) {
+		t.Error("negative sample rate should fail")
+	}
+
+	writer.SetBurstCaptureInterval(time.Hour)
+	if err = writer.SetBurstCapture(3, 0); nil != err {
+		t.Fatalf("set burst capture failed. err: %s", err.Error())
+	}
+
+	for i := 0; i < 10; i++ {
+		writer.Errorf("timeout after %d retries", i)
+		writer.Error("connection refused")
+	}
+	writer.flush()
+
+	content, err := ioutil.ReadFile("/tmp/burst.log")
+	if nil != err {
+		t.Fatalf("read log failed. err: %s", err.Error())
+	}
+	if 3 != strings.Count(string(content), "timeout after") || 3 != strings.Count(string(content), "connection refused") {
+		t.Errorf("only the first 3 occurrences should be written. content: %s", content)
+	}
+
+	// disable burst capture
+	writer.SetBurstCapture(0, 0)
+	writer.Error("connection refused")
+	writer.flush()
+
+	content, _ = ioutil.ReadFile("/tmp/burst.log")
+	if 4 != strings.Count(string(content), "connection refused") {
+		t.Errorf("every message should be written after burst capture disabled. content: %s", content)
+	}
+}
@@ -0,0 +1,10 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+//go:build !nocaller
+// +build !nocaller
+
+package blog4go
+
+// callerEnabled enables looking up caller of writes for caller level
+// overrides, build with -tags nocaller to disable it
+const callerEnabled = true
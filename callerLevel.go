@@ -0,0 +1,151 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// packageDir is the directory of source files of this package, frames in it
+// are skipped while looking up the caller
+var packageDir string
+
+func init() {
+	if _, file, _, ok := runtime.Caller(0); ok {
+		packageDir = filepath.Dir(file)
+	}
+}
+
+// callerLevel overrides logging level for callers matching pattern
+type callerLevel struct {
+	pattern string
+	level   LevelType
+}
+
+// match return true if pattern is a prefix of file, or a glob matching the
+// full path or base name of file
+func (override callerLevel) match(file string) bool {
+	if strings.HasPrefix(file, override.pattern) {
+		return true
+	}
+	if ok, _ := filepath.Match(override.pattern, file); ok {
+		return true
+	}
+	ok, _ := filepath.Match(override.pattern, filepath.Base(file))
+	return ok
+}
+
+// callerFile return file name of the first caller outside this package, test
+// files of this package are regarded as callers as well
+func callerFile() string {
+	return callerFrame().File
+}
+
+// maxCallerDepth is the max number of frames walked while looking up the
+// caller, wrappers of this package stacked deeper than it yields no caller
+const maxCallerDepth = 64
+
+// callerFrame return frame of the first caller outside this package, or zero
+// frame if not found in maxCallerDepth frames
+func callerFrame() runtime.Frame {
+	var pcs [16]uintptr
+	for skip := 2; skip < maxCallerDepth+2; skip += len(pcs) {
+		n := runtime.Callers(skip, pcs[:])
+		for _, pc := range pcs[:n] {
+			for _, frame := range callerFrames.lookup(pc) {
+				if filepath.Dir(frame.File) != packageDir || strings.HasSuffix(frame.File, "_test.go") {
+					return frame
+				}
+			}
+		}
+		if n < len(pcs) {
+			break
+		}
+	}
+	return runtime.Frame{}
+}
+
+// callerFrames caches frames of every pc looked up by callerFrame
+var callerFrames = new(frameCache)
+
+// frameCache keeps frames resolved by pc, so symbols of hot call sites are
+// resolved once. Call sites are limited, so frames are never evicted.
+type frameCache struct {
+	// map pc to []runtime.Frame
+	frames sync.Map
+}
+
+// lookup return frames of pc, more than one if functions inlined
+func (cache *frameCache) lookup(pc uintptr) []runtime.Frame {
+	if frames, ok := cache.frames.Load(pc); ok {
+		return frames.([]runtime.Frame)
+	}
+
+	var frames []runtime.Frame
+	iter := runtime.CallersFrames([]uintptr{pc})
+	for {
+		frame, more := iter.Next()
+		frames = append(frames, frame)
+		if !more {
+			break
+		}
+	}
+
+	cache.frames.Store(pc, frames)
+	return frames
+}
+
+// SetCallerLevel set logging level of messages written from source files
+// matching pattern, like SetCallerLevel("*/vendor/*", ERROR) or
+// SetCallerLevel("/src/myapp/", DEBUG). Pattern is a path prefix, or a glob
+// matched against the full path or base name of the caller's file. Patterns
+// are checked in order they are set, the first one matched wins, and
+// logging level is used if none matched. Setting a pattern again changes
+// its level. Overrides are ignored if built with -tags nocaller.
+func (writer *baseFileWriter) SetCallerLevel(pattern string, level LevelType) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	// copy on write, levels in use are never modified
+	levels := make([]callerLevel, 0, len(writer.callerLevels)+1)
+	found := false
+	for _, override := range writer.callerLevels {
+		if pattern == override.pattern {
+			override.level = level
+			found = true
+		}
+		levels = append(levels, override)
+	}
+	if !found {
+		levels = append(levels, callerLevel{pattern, level})
+	}
+	writer.callerLevels = levels
+}
+
+// callerLevelOverrides get level overrides in order they are set
+func (writer *baseFileWriter) callerLevelOverrides() []callerLevel {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.callerLevels
+}
+
+// levelOfCaller get logging level for the caller, stack is walked only if
+// any override is set and caller lookup is not disabled by -tags nocaller
+func (writer *baseFileWriter) levelOfCaller() LevelType {
+	if !callerEnabled {
+		return writer.blog.Level()
+	}
+
+	if overrides := writer.callerLevelOverrides(); len(overrides) > 0 {
+		file := callerFile()
+		for _, override := range overrides {
+			if override.match(file) {
+				return override.level
+			}
+		}
+	}
+	return writer.blog.Level()
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCallerLevelMatch(t *testing.T) {
+	cases := []struct {
+		pattern string
+		file    string
+		matched bool
+	}{
+		{"/src/myapp/", "/src/myapp/main.go", true},
+		{"/src/myapp/", "/src/lib/main.go", false},
+		{"/src/*/vendor/*", "/src/myapp/vendor/lib.go", true},
+		{"lib_*.go", "/src/myapp/lib_debug.go", true},
+		{"lib_*.go", "/src/myapp/main.go", false},
+	}
+
+	for _, c := range cases {
+		if c.matched != (callerLevel{c.pattern, DEBUG}).match(c.file) {
+			t.Errorf("match wrong. pattern: %s, file: %s", c.pattern, c.file)
+		}
+	}
+}
+
+func TestSetCallerLevel(t *testing.T) {
+	if !callerEnabled {
+		t.Skip("caller lookup disabled by -tags nocaller")
+	}
+
+	err := NewBaseFileWriter("/tmp/caller.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		Close()
+		os.Remove("/tmp/caller.log")
+	}()
+
+	SetLevel(WARNING)
+	Debug("filtered")
+
+	SetCallerLevel("*/vendor/*", CRITICAL)
+	SetCallerLevel("callerLevel_test.go", ERROR)
+	SetCallerLevel("callerLevel_*.go", CRITICAL)
+	Warn("filtered by caller level")
+
+	// first pattern matched wins, changing its level keeps its order
+	SetCallerLevel("callerLevel_test.go", DEBUG)
+	Debug("debug from test")
+	Debugf("debugf from %s", "test")
+	blog.flush()
+
+	data, err := ioutil.ReadFile("/tmp/caller.log")
+	if nil != err {
+		t.Fatalf("read log failed. err: %s", err.Error())
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if 2 != len(lines) || !strings.HasSuffix(lines[0], "] debug from test") || !strings.HasSuffix(lines[1], "] debugf from test") {
+		t.Errorf("caller level wrong. lines: %q", lines)
+	}
+}
+
+func TestCallerFrameCache(t *testing.T) {
+	var frames []runtime.Frame
+	for i := 0; i < 2; i++ {
+		frames = append(frames, callerFrame())
+	}
+
+	if frames[0] != frames[1] || !strings.HasSuffix(frames[0].File, "callerLevel_test.go") || 0 == frames[0].Line {
+		t.Errorf("caller frame wrong. frames: %+v", frames)
+	}
+
+	cached := false
+	callerFrames.frames.Range(func(key, value interface{}) bool {
+		cached = true
+		return false
+	})
+	if !cached {
+		t.Error("frames should be cached")
+	}
+}
+
+func BenchmarkCallerFrame(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		callerFrame()
+	}
+}
@@ -0,0 +1,10 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+//go:build nocaller
+// +build nocaller
+
+package blog4go
+
+// callerEnabled is false, caller of writes is never looked up and caller
+// level overrides are ignored
+const callerEnabled = false
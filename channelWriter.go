@@ -0,0 +1,345 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// ChannelWriter sends every message written as an *Entry to a channel, so
+// messages can drive other code like alerting or metrics without reading
+// files. Entries are sent with the lock released, so a slow receiver only
+// blocks writers if entries are not dropped.
+type ChannelWriter struct {
+	writerBase
+
+	level LevelType
+
+	closed bool
+
+	// log hook
+	hook      Hook
+	hookLevel LevelType
+	hookAsync bool
+
+	// middlewares applied to message before sent
+	middlewareList
+
+	ch chan<- *Entry
+	// sign of non-blocking send, entries are dropped if ch is full
+	dropOnFull bool
+	dropped    int64
+
+	lock *sync.Mutex
+}
+
+// NewChannelWriter create a ChannelWriter sending entries to ch, not
+// singlton. Entries are dropped and counted if ch is full and dropOnFull is
+// true, otherwise writes block until ch is ready. ch is not closed by the
+// writer.
+func NewChannelWriter(ch chan<- *Entry, dropOnFull bool) *ChannelWriter {
+	channelWriter := new(ChannelWriter)
+	channelWriter.self = channelWriter
+	channelWriter.level = DEBUG
+	channelWriter.closed = false
+	channelWriter.lock = new(sync.Mutex)
+	channelWriter.ch = ch
+	channelWriter.dropOnFull = dropOnFull
+
+	// log hook
+	channelWriter.hook = nil
+	channelWriter.hookLevel = DEBUG
+	channelWriter.hookAsync = true
+
+	return channelWriter
+}
+
+// entry create an entry of message applied middlewares, nil if closed
+func (writer *ChannelWriter) entry(level LevelType, message string) *Entry {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	if writer.closed {
+		return nil
+	}
+
+	message = writer.applyMiddlewares(level, message)
+
+	entry := &Entry{Time: timeCache.Now(), Level: level, Message: message}
+	if callerEnabled {
+		frame := callerFrame()
+		entry.Caller = fmt.Sprintf("%s:%d", frame.File, frame.Line)
+	}
+	return entry
+}
+
+// send sends entry to ch, dropped if ch is full and dropOnFull is true
+func (writer *ChannelWriter) send(entry *Entry) {
+	if !writer.dropOnFull {
+		writer.ch <- entry
+		return
+	}
+
+	select {
+	case writer.ch <- entry:
+	default:
+		atomic.AddInt64(&writer.dropped, 1)
+	}
+}
+
+// DroppedCount return number of entries dropped for ch is full
+func (writer *ChannelWriter) DroppedCount() int64 {
+	return atomic.LoadInt64(&writer.dropped)
+}
+
+// fire calls log hook
+func (writer *ChannelWriter) fire(level LevelType, message string) {
+	if nil == writer.hook || level < writer.hookLevel {
+		return
+	}
+
+	if writer.hookAsync {
+		go writer.hook.Fire(level, message)
+	} else {
+		writer.hook.Fire(level, message)
+	}
+}
+
+func (writer *ChannelWriter) write(level LevelType, args ...interface{}) {
+	message := fmt.Sprint(args...)
+	entry := writer.entry(level, message)
+	if nil == entry {
+		return
+	}
+
+	writer.send(entry)
+	writer.fire(level, message)
+}
+
+func (writer *ChannelWriter) writef(level LevelType, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	entry := writer.entry(level, message)
+	if nil == entry {
+		return
+	}
+
+	writer.send(entry)
+	writer.fire(level, message)
+}
+
+// Level get level
+func (writer *ChannelWriter) Level() LevelType {
+	return writer.level
+}
+
+// SetLevel set logger level
+func (writer *ChannelWriter) SetLevel(level LevelType) {
+	writer.level = level
+}
+
+// SetHook set hook for logging action
+func (writer *ChannelWriter) SetHook(hook Hook) {
+	writer.hook = hook
+}
+
+// SetHookAsync set hook async for channel writer
+func (writer *ChannelWriter) SetHookAsync(async bool) {
+	writer.hookAsync = async
+}
+
+// SetHookLevel set when hook will be called
+func (writer *ChannelWriter) SetHookLevel(level LevelType) {
+	writer.hookLevel = level
+}
+
+// TimeRotated do nothing
+func (writer *ChannelWriter) TimeRotated() bool {
+	return false
+}
+
+// SetTimeRotated do nothing
+func (writer *ChannelWriter) SetTimeRotated(timeRotated bool) {
+	return
+}
+
+// Retentions do nothing
+func (writer *ChannelWriter) Retentions() int64 {
+	return 0
+}
+
+// SetRetentions do nothing
+func (writer *ChannelWriter) SetRetentions(retentions int64) {
+	return
+}
+
+// RotateSize do nothing
+func (writer *ChannelWriter) RotateSize() int64 {
+	return 0
+}
+
+// SetRotateSize do nothing
+func (writer *ChannelWriter) SetRotateSize(rotateSize int64) {
+	return
+}
+
+// RotateLines do nothing
+func (writer *ChannelWriter) RotateLines() int {
+	return 0
+}
+
+// SetRotateLines do nothing
+func (writer *ChannelWriter) SetRotateLines(rotateLines int) {
+	return
+}
+
+// Colored do nothing
+func (writer *ChannelWriter) Colored() bool {
+	return false
+}
+
+// SetColored do nothing
+func (writer *ChannelWriter) SetColored(colored bool) {
+	return
+}
+
+// PipeFromWithContext logs every line read from r with given level until r
+// reaches EOF or ctx is done
+func (writer *ChannelWriter) PipeFromWithContext(ctx context.Context, r io.Reader, level LevelType) error {
+	if writer.Closed() {
+		return ErrWriterClosed
+	}
+
+	go pipe(ctx, writer, r, level)
+	return nil
+}
+
+// Close will close the writer, entries are not sent any more
+func (writer *ChannelWriter) Close() {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.closed = true
+}
+
+// Closed get writer status
+func (writer *ChannelWriter) Closed() bool {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	return writer.closed
+}
+
+// flush do nothing
+func (writer *ChannelWriter) flush() {
+	return
+}
+
+// Trace trace
+func (writer *ChannelWriter) Trace(args ...interface{}) {
+	if TRACE < writer.level {
+		return
+	}
+
+	writer.write(TRACE, args...)
+}
+
+// Tracef tracef
+func (writer *ChannelWriter) Tracef(format string, args ...interface{}) {
+	if TRACE < writer.level {
+		return
+	}
+
+	writer.writef(TRACE, format, args...)
+}
+
+// Debug debug
+func (writer *ChannelWriter) Debug(args ...interface{}) {
+	if DEBUG < writer.level {
+		return
+	}
+
+	writer.write(DEBUG, args...)
+}
+
+// Debugf debugf
+func (writer *ChannelWriter) Debugf(format string, args ...interface{}) {
+	if DEBUG < writer.level {
+		return
+	}
+
+	writer.writef(DEBUG, format, args...)
+}
+
+// Info info
+func (writer *ChannelWriter) Info(args ...interface{}) {
+	if INFO < writer.level {
+		return
+	}
+
+	writer.write(INFO, args...)
+}
+
+// Infof infof
+func (writer *ChannelWriter) Infof(format string, args ...interface{}) {
+	if INFO < writer.level {
+		return
+	}
+
+	writer.writef(INFO, format, args...)
+}
+
+// Warn warn
+func (writer *ChannelWriter) Warn(args ...interface{}) {
+	if WARNING < writer.level {
+		return
+	}
+
+	writer.write(WARNING, args...)
+}
+
+// Warnf warnf
+func (writer *ChannelWriter) Warnf(format string, args ...interface{}) {
+	if WARNING < writer.level {
+		return
+	}
+
+	writer.writef(WARNING, format, args...)
+}
+
+// Error error
+func (writer *ChannelWriter) Error(args ...interface{}) {
+	if ERROR < writer.level {
+		return
+	}
+
+	writer.write(ERROR, args...)
+}
+
+// Errorf error
+func (writer *ChannelWriter) Errorf(format string, args ...interface{}) {
+	if ERROR < writer.level {
+		return
+	}
+
+	writer.writef(ERROR, format, args...)
+}
+
+// Critical critical
+func (writer *ChannelWriter) Critical(args ...interface{}) {
+	if CRITICAL < writer.level {
+		return
+	}
+
+	writer.write(CRITICAL, args...)
+}
+
+// Criticalf criticalf
+func (writer *ChannelWriter) Criticalf(format string, args ...interface{}) {
+	if CRITICAL < writer.level {
+		return
+	}
+
+	writer.writef(CRITICAL, format, args...)
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChannelWriter(t *testing.T) {
+	ch := make(chan *Entry, 2)
+	writer := NewChannelWriter(ch, true)
+	writer.AddMiddleware(func(level LevelType, message string) string {
+		return strings.ToUpper(message)
+	})
+
+	writer.Info("hello")
+	writer.Errorf("code %d", 500)
+	writer.Warn("dropped")
+
+	if 1 != writer.DroppedCount() {
+		t.Errorf("dropped count wrong. count: %d", writer.DroppedCount())
+	}
+
+	entry := <-ch
+	if INFO != entry.Level || "HELLO" != entry.Message || entry.Time.IsZero() {
+		t.Errorf("entry sent wrong. entry: %+v", entry)
+	}
+	if callerEnabled && !strings.Contains(entry.Caller, "channelWriter_test.go:") {
+		t.Errorf("caller of entry wrong. caller: %s", entry.Caller)
+	}
+	if entry = <-ch; ERROR != entry.Level || "CODE 500" != entry.Message {
+		t.Errorf("entry sent wrong. entry: %+v", entry)
+	}
+
+	writer.Close()
+	writer.Info("closed")
+	if 0 != len(ch) {
+		t.Error("entry should not be sent after closed")
+	}
+
+	blocking := make(chan *Entry)
+	writer = NewChannelWriter(blocking, false)
+	go writer.Debug("blocked")
+	if entry = <-blocking; "blocked" != entry.Message || 0 != writer.DroppedCount() {
+		t.Errorf("blocking send wrong. entry: %+v", entry)
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	// IntegritySuffix is the suffix of sidecar file which stores chained
+	// hmac of every line in the log file
+	IntegritySuffix = ".sha256"
+)
+
+// integrityWriter writes to the log file and appends chained hmac of every
+// line written to the sidecar file. hmac of a line is computed over the
+// hmac of the previous line and the line itself without EOL, so that any
+// modification, deletion or insertion of lines can be detected.
+type integrityWriter struct {
+	// the log file
+	out io.Writer
+	// sidecar file stores "<lineNumber> <hex(hmac)>" lines
+	sidecar *os.File
+
+	key []byte
+	// hmac of the previous line
+	prev []byte
+	// line number of the last line signed
+	line int
+	// bytes of the line not ended yet
+	partial []byte
+}
+
+// newIntegrityWriter create an integrityWriter for the log file.
+// If the sidecar file already covers every line in the log file, the chain
+// continues, otherwise sidecar file restarts from the end of the log file.
+func newIntegrityWriter(out io.Writer, fileName string, key []byte) (writer *integrityWriter, err error) {
+	writer = new(integrityWriter)
+	writer.out = out
+	writer.key = append([]byte(nil), key...)
+
+	lines, err := countLines(fileName)
+	if nil != err {
+		return nil, err
+	}
+
+	flag := DefaultFileFlag
+	if line, prev, err := lastSignature(fileName + IntegritySuffix); nil == err && line == lines {
+		writer.prev = prev
+	} else {
+		// chain can not continue
+		flag |= os.O_TRUNC
+	}
+	writer.line = lines
+
+	writer.sidecar, err = os.OpenFile(fileName+IntegritySuffix, flag, os.FileMode(0644))
+	if nil != err {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// Write writes p to the log file and signs every line ended. Error of
+// signing is returned if err of the log file is nil, so that failures are
+// not hidden behind lines written unsigned.
+func (writer *integrityWriter) Write(p []byte) (n int, err error) {
+	n, err = writer.out.Write(p)
+
+	data := p[:n]
+	for {
+		i := bytes.IndexByte(data, EOL)
+		if i < 0 {
+			writer.partial = append(writer.partial, data...)
+			break
+		}
+
+		writer.partial = append(writer.partial, data[:i]...)
+		if signErr := writer.sign(writer.partial); nil != signErr && nil == err {
+			err = signErr
+		}
+		writer.partial = writer.partial[:0]
+		data = data[i+1:]
+	}
+	return
+}
+
+// sign appends hmac of line to the sidecar file
+func (writer *integrityWriter) sign(line []byte) error {
+	writer.prev = chainHMAC(writer.key, writer.prev, line)
+	writer.line++
+	_, err := fmt.Fprintf(writer.sidecar, "%d %s\n", writer.line, hex.EncodeToString(writer.prev))
+	return err
+}
+
+// Close close the sidecar file
+func (writer *integrityWriter) Close() error {
+	return writer.sidecar.Close()
+}
+
+// chainHMAC computes HMAC-SHA256 over prev || line
+func chainHMAC(key []byte, prev []byte, line []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(prev)
+	mac.Write(line)
+	return mac.Sum(nil)
+}
+
+// countLines counts lines in a file, 0 if file not exists
+func countLines(fileName string) (lines int, err error) {
+	file, err := os.Open(fileName)
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if nil != err {
+		return 0, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for {
+		_, err = reader.ReadBytes(EOL)
+		if io.EOF == err {
+			return lines, nil
+		} else if nil != err {
+			return 0, err
+		}
+		lines++
+	}
+}
+
+// lastSignature read the last signature in the sidecar file
+func lastSignature(sidecarFile string) (line int, signature []byte, err error) {
+	file, err := os.Open(sidecarFile)
+	if nil != err {
+		return 0, nil, err
+	}
+	defer file.Close()
+
+	var hexSignature string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if _, err = fmt.Sscanf(scanner.Text(), "%d %s", &line, &hexSignature); nil != err {
+			return 0, nil, err
+		}
+	}
+	if err = scanner.Err(); nil != err {
+		return 0, nil, err
+	}
+
+	signature, err = hex.DecodeString(hexSignature)
+	return line, signature, err
+}
+
+// VerifyIntegrity verifies lines in logFile with the chained hmac stored in
+// sidecarFile. It returns false if any line signed is modified, deleted or
+// inserted, or any line is appended without being signed.
+func VerifyIntegrity(logFile, sidecarFile string, key []byte) (bool, error) {
+	log, err := os.Open(logFile)
+	if nil != err {
+		return false, err
+	}
+	defer log.Close()
+
+	sidecar, err := os.Open(sidecarFile)
+	if nil != err {
+		return false, err
+	}
+	defer sidecar.Close()
+
+	reader := bufio.NewReader(log)
+	scanner := bufio.NewScanner(sidecar)
+
+	var prev []byte
+	var current int
+	var first = true
+	for scanner.Scan() {
+		var line int
+		var hexSignature string
+		if _, err = fmt.Sscanf(scanner.Text(), "%d %s", &line, &hexSignature); nil != err {
+			return false, err
+		}
+
+		// lines signed must be continuous
+		if !first && current+1 != line {
+			return false, nil
+		}
+		first = false
+
+		// skip lines before the chain begins
+		var content []byte
+		for current < line {
+			content, err = reader.ReadBytes(EOL)
+			if io.EOF == err {
+				// line signed is deleted
+				return false, nil
+			} else if nil != err {
+				return false, err
+			}
+			current++
+		}
+
+		signature, err := hex.DecodeString(hexSignature)
+		if nil != err {
+			return false, err
+		}
+
+		prev = chainHMAC(key, prev, bytes.TrimSuffix(content, []byte{EOL}))
+		if !hmac.Equal(prev, signature) {
+			return false, nil
+		}
+	}
+	if err = scanner.Err(); nil != err {
+		return false, err
+	}
+
+	// lines appended without signature
+	if _, err = reader.ReadByte(); io.EOF != err {
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestIntegrityCheck(t *testing.T) {
+	key := []byte("secret key")
+	defer func() {
+		os.Remove("/tmp/integrity.log")
+		os.Remove("/tmp/integrity.log" + IntegritySuffix)
+	}()
+
+	writer, err := newBaseFileWriter("/tmp/integrity.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+
+	if err = writer.SetIntegrityCheck(key); nil != err {
+		t.Fatalf("enable integrity check failed. err: %s", err.Error())
+	}
+
+	writer.Info("first line")
+	writer.Warnf("%s line", "second")
+	writer.Close()
+
+	// chain continues when reopened
+	writer, err = newBaseFileWriter("/tmp/integrity.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	writer.SetIntegrityCheck(key)
+	writer.Error("third line")
+	writer.Close()
+
+	if ok, err := VerifyIntegrity("/tmp/integrity.log", "/tmp/integrity.log"+IntegritySuffix, key); !ok || nil != err {
+		t.Errorf("integrity check should pass. err: %v", err)
+	}
+
+	if ok, _ := VerifyIntegrity("/tmp/integrity.log", "/tmp/integrity.log"+IntegritySuffix, []byte("wrong key")); ok {
+		t.Error("integrity check should fail with wrong key")
+	}
+
+	// tamper log file
+	content, _ := ioutil.ReadFile("/tmp/integrity.log")
+	ioutil.WriteFile("/tmp/integrity.log", []byte(strings.Replace(string(content), "second", "2nd", 1)), 0644)
+	if ok, _ := VerifyIntegrity("/tmp/integrity.log", "/tmp/integrity.log"+IntegritySuffix, key); ok {
+		t.Error("integrity check should fail when line modified")
+	}
+
+	// append unsigned line
+	ioutil.WriteFile("/tmp/integrity.log", append(content, "forged line\n"...), 0644)
+	if ok, _ := VerifyIntegrity("/tmp/integrity.log", "/tmp/integrity.log"+IntegritySuffix, key); ok {
+		t.Error("integrity check should fail when line appended")
+	}
+}
+
+func TestIntegrityCheckReopenFailed(t *testing.T) {
+	key := []byte("secret key")
+	defer func() {
+		os.Remove("/tmp/integrity.log")
+		os.Remove("/tmp/integrity.log" + IntegritySuffix)
+		os.Remove("/tmp/integrityNext.log")
+		os.Remove("/tmp/integrityNext.log" + IntegritySuffix)
+	}()
+
+	writer, err := newBaseFileWriter("/tmp/integrity.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	if err = writer.SetIntegrityCheck(key); nil != err {
+		t.Fatalf("enable integrity check failed. err: %s", err.Error())
+	}
+	integrity := writer.integrity
+
+	// sidecar file can not be opened
+	if err = os.Mkdir("/tmp/integrityNext.log"+IntegritySuffix, 0755); nil != err {
+		t.Fatalf("create directory failed. err: %s", err.Error())
+	}
+	writer.lock.Lock()
+	err = writer.openFile("/tmp/integrityNext.log")
+	writer.lock.Unlock()
+	if nil == err || integrity != writer.integrity {
+		t.Error("file which can not be signed should not be written")
+	}
+
+	writer.Info("still signed")
+	writer.Close()
+	if ok, err := VerifyIntegrity("/tmp/integrity.log", "/tmp/integrity.log"+IntegritySuffix, key); !ok || nil != err {
+		t.Errorf("integrity check should pass. err: %v", err)
+	}
+}
+
+func TestIntegrityWriterSignFailed(t *testing.T) {
+	defer func() {
+		os.Remove("/tmp/integrity.log")
+		os.Remove("/tmp/integrity.log" + IntegritySuffix)
+	}()
+
+	writer, err := newIntegrityWriter(ioutil.Discard, "/tmp/integrity.log", []byte("secret key"))
+	if nil != err {
+		t.Fatalf("create integrity writer failed. err: %s", err.Error())
+	}
+	writer.Close()
+
+	if _, err = writer.Write([]byte("unsigned\n")); nil == err {
+		t.Error("error of signing should be returned")
+	}
+}
@@ -0,0 +1,314 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"context"
+	"io"
+)
+
+// hookSource provides hooks to be called for logging action
+type hookSource interface {
+	hooksFired(level LevelType) []Hook
+	hooksAsync() bool
+}
+
+// fileWriterClone is a writer sharing the file, buffer, lock, daemon and
+// file settings like logrotate with the parent file writer, but with its
+// own level, colored and hooks. Close on the clone only stops it from
+// writing, the file is closed by the parent.
+type fileWriterClone struct {
+	*baseFileWriter
+
+	level LevelType
+
+	// close sign of the clone, messages are dropped if the clone or the
+	// parent is closed
+	closed bool
+
+	colored bool
+
+	// log hook
+	hook      Hook
+	hookLevel LevelType
+	hookAsync bool
+	hooks     *HookManager
+}
+
+// Clone creates a writer writing to the same file, with its own level,
+// colored and hooks. It is useful for separate loggers at different levels
+// writing to the same file, like an audit logger at INFO and an app logger
+// at DEBUG.
+func (writer *baseFileWriter) Clone() Writer {
+	clone := new(fileWriterClone)
+	clone.baseFileWriter = writer
+	clone.level = writer.Level()
+	clone.closed = false
+	clone.colored = writer.Colored()
+
+	// log hook
+	clone.hook = nil
+	clone.hookLevel = DEBUG
+	clone.hookAsync = true
+	clone.hooks = NewHookManager()
+	return clone
+}
+
+func (writer *fileWriterClone) write(level LevelType, args ...interface{}) {
+	if writer.Closed() {
+		return
+	}
+
+	writer.baseFileWriter.writeFrom(writer, level, args...)
+}
+
+func (writer *fileWriterClone) writeBytes(level LevelType, message []byte) {
+	if writer.Closed() {
+		return
+	}
+
+	writer.baseFileWriter.writeBytesFrom(writer, level, message)
+}
+
+func (writer *fileWriterClone) writef(level LevelType, format string, args ...interface{}) {
+	if writer.Closed() {
+		return
+	}
+
+	writer.baseFileWriter.writefFrom(writer, level, format, args...)
+}
+
+// Closed get whether the clone or the parent is closed
+func (writer *fileWriterClone) Closed() bool {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.closed || writer.baseFileWriter.closed
+}
+
+// Close stops the clone from writing, the file is still kept open for the
+// parent
+func (writer *fileWriterClone) Close() {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.closed = true
+}
+
+// Level get level of the clone
+func (writer *fileWriterClone) Level() LevelType {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.level
+}
+
+// SetLevel set logging level threshold of the clone
+func (writer *fileWriterClone) SetLevel(level LevelType) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.level = level
+}
+
+// Colored get whether the clone is log with colored
+func (writer *fileWriterClone) Colored() bool {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.colored
+}
+
+// SetColored set logging color of the clone
+func (writer *fileWriterClone) SetColored(colored bool) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if colored == writer.colored {
+		return
+	}
+
+	writer.colored = colored
+	initPrefix(colored)
+}
+
+// SetHook set hook for the clone
+func (writer *fileWriterClone) SetHook(hook Hook) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.hook = hook
+}
+
+// AddHook add hook with id, called along with hook set by SetHook
+func (writer *fileWriterClone) AddHook(id string, hook Hook) {
+	writer.hooks.AddHook(id, hook)
+}
+
+// RemoveHook remove hook with id
+func (writer *fileWriterClone) RemoveHook(id string) {
+	writer.hooks.RemoveHook(id)
+}
+
+// ListHooks return sorted ids of hooks added
+func (writer *fileWriterClone) ListHooks() []string {
+	return writer.hooks.ListHooks()
+}
+
+// hooksFired snapshots hooks to be called for logging action with level
+func (writer *fileWriterClone) hooksFired(level LevelType) []Hook {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	if level < writer.hookLevel {
+		return nil
+	}
+
+	hooks := writer.hooks.snapshot()
+	if nil != writer.hook {
+		hooks = append(hooks, writer.hook)
+	}
+	return hooks
+}
+
+// hooksAsync determines whether hooks are called async
+func (writer *fileWriterClone) hooksAsync() bool {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.hookAsync
+}
+
+// SetHookAsync set hook async for the clone
+func (writer *fileWriterClone) SetHookAsync(async bool) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.hookAsync = async
+}
+
+// SetHookLevel set when hook will be called
+func (writer *fileWriterClone) SetHookLevel(level LevelType) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.hookLevel = level
+}
+
+// Build return a LogBuilder of the message with level
+func (writer *fileWriterClone) Build(level LevelType) *LogBuilder {
+	return newLogBuilder(writer, level)
+}
+
+// PipeFrom logs every line read from r with given level until r reaches EOF
+func (writer *fileWriterClone) PipeFrom(r io.Reader, level LevelType) error {
+	return writer.PipeFromWithContext(context.Background(), r, level)
+}
+
+// PipeFromWithContext logs every line read from r with given level until r
+// reaches EOF or ctx is done
+func (writer *fileWriterClone) PipeFromWithContext(ctx context.Context, r io.Reader, level LevelType) error {
+	if writer.Closed() {
+		return ErrWriterClosed
+	}
+
+	go pipe(ctx, writer, r, level)
+	return nil
+}
+
+// Trace trace
+func (writer *fileWriterClone) Trace(args ...interface{}) {
+	if TRACE < writer.Level() {
+		return
+	}
+
+	writer.write(TRACE, args...)
+}
+
+// Tracef tracef
+func (writer *fileWriterClone) Tracef(format string, args ...interface{}) {
+	if TRACE < writer.Level() {
+		return
+	}
+
+	writer.writef(TRACE, format, args...)
+}
+
+// Debug debug
+func (writer *fileWriterClone) Debug(args ...interface{}) {
+	if DEBUG < writer.Level() {
+		return
+	}
+
+	writer.write(DEBUG, args...)
+}
+
+// Debugf debugf
+func (writer *fileWriterClone) Debugf(format string, args ...interface{}) {
+	if DEBUG < writer.Level() {
+		return
+	}
+
+	writer.writef(DEBUG, format, args...)
+}
+
+// Info info
+func (writer *fileWriterClone) Info(args ...interface{}) {
+	if INFO < writer.Level() {
+		return
+	}
+
+	writer.write(INFO, args...)
+}
+
+// Infof infof
+func (writer *fileWriterClone) Infof(format string, args ...interface{}) {
+	if INFO < writer.Level() {
+		return
+	}
+
+	writer.writef(INFO, format, args...)
+}
+
+// Warn warn
+func (writer *fileWriterClone) Warn(args ...interface{}) {
+	if WARNING < writer.Level() {
+		return
+	}
+
+	writer.write(WARNING, args...)
+}
+
+// Warnf warnf
+func (writer *fileWriterClone) Warnf(format string, args ...interface{}) {
+	if WARNING < writer.Level() {
+		return
+	}
+
+	writer.writef(WARNING, format, args...)
+}
+
+// Error error
+func (writer *fileWriterClone) Error(args ...interface{}) {
+	if ERROR < writer.Level() {
+		return
+	}
+
+	writer.write(ERROR, args...)
+}
+
+// Errorf errorf
+func (writer *fileWriterClone) Errorf(format string, args ...interface{}) {
+	if ERROR < writer.Level() {
+		return
+	}
+
+	writer.writef(ERROR, format, args...)
+}
+
+// Critical critical
+func (writer *fileWriterClone) Critical(args ...interface{}) {
+	if CRITICAL < writer.Level() {
+		return
+	}
+
+	writer.write(CRITICAL, args...)
+}
+
+// Criticalf criticalf
+func (writer *fileWriterClone) Criticalf(format string, args ...interface{}) {
+	if CRITICAL < writer.Level() {
+		return
+	}
+
+	writer.writef(CRITICAL, format, args...)
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClone(t *testing.T) {
+	if _, err := Clone(); ErrNotSupported != err {
+		t.Error("clone without file writer should fail")
+	}
+
+	err := NewBaseFileWriter("/tmp/clone.log", false)
+	defer func() {
+		Close()
+		os.Remove("/tmp/clone.log")
+	}()
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+
+	SetLevel(INFO)
+	clone, err := Clone()
+	if nil != err {
+		t.Fatalf("clone failed. err: %s", err.Error())
+	}
+	if INFO != clone.Level() {
+		t.Error("clone should inherit level of parent")
+	}
+
+	hook := NewMyHook()
+	clone.SetHook(hook)
+	clone.SetHookAsync(false)
+	clone.SetHookLevel(WARNING)
+	clone.SetLevel(DEBUG)
+	if DEBUG != clone.Level() || INFO != Level() {
+		t.Error("level of clone should be separated from parent")
+	}
+
+	Debug("parent debug")
+	Warn("parent warn")
+	clone.Debug("clone debug")
+	clone.Debugf("%s", "clone debug")
+	clone.Warn("clone warn")
+	clone.Close()
+	clone.Info("clone closed")
+	if ErrWriterClosed != clone.PipeFrom(strings.NewReader(""), INFO) {
+		t.Error("pipe from closed clone should fail")
+	}
+	if 1 != hook.Cnt() || "clone warn" != hook.Message() {
+		t.Errorf("hook of clone called wrong. count: %d, message: %s", hook.Cnt(), hook.Message())
+	}
+
+	// file is still kept open for parent
+	Info("parent info")
+	Flush()
+
+	content, err := ioutil.ReadFile("/tmp/clone.log")
+	if nil != err {
+		t.Fatalf("read log failed. err: %s", err.Error())
+	}
+
+	var messages []string
+	for _, line := range strings.Split(strings.TrimSuffix(string(content), "\n"), "\n") {
+		rest := line[len(PrefixTimeFormat):]
+		messages = append(messages, rest[strings.Index(rest, "] ")+2:])
+	}
+	if "parent warn,clone debug,clone debug,clone warn,parent info" != strings.Join(messages, ",") {
+		t.Errorf("messages written wrong. messages: %v", messages)
+	}
+}
+
+func TestCloneParentClosed(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/clone_closed.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer os.Remove("/tmp/clone_closed.log")
+
+	clone := writer.Clone()
+	clone.SetColored(true)
+	if !clone.Colored() {
+		t.Error("clone should be colored")
+	}
+	clone.SetColored(false)
+
+	clone.(*fileWriterClone).AddHook("hook", NewMyHook())
+	if ids := clone.(*fileWriterClone).ListHooks(); 1 != len(ids) || 0 != len(writer.ListHooks()) {
+		t.Error("hooks of clone should be separated from parent")
+	}
+	clone.(*fileWriterClone).RemoveHook("hook")
+
+	done := make(chan struct{})
+	r, w, _ := os.Pipe()
+	clone.PipeFrom(r, INFO)
+	go func() {
+		w.WriteString("piped\n")
+		w.Close()
+		close(done)
+	}()
+	<-done
+	time.Sleep(100 * time.Millisecond)
+
+	writer.Close()
+	clone.Trace("trace")
+	clone.Tracef("%s", "trace")
+	clone.Infof("%s", "info")
+	clone.Warnf("%s", "warn")
+	clone.Error("error")
+	clone.Errorf("%s", "error")
+	clone.Critical("critical")
+	clone.Criticalf("%s", "critical")
+
+	content, _ := ioutil.ReadFile("/tmp/clone_closed.log")
+	if !strings.HasSuffix(string(content), "] piped\n") {
+		t.Errorf("clone should not write after parent closed. content: %q", content)
+	}
+}
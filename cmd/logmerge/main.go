@@ -0,0 +1,65 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+// logmerge merges log files written by blog4go into a single stream in
+// chronological order, like
+//
+//	logmerge --output merged.log app.log.1 app.log worker.log.1 worker.log
+//
+// Every file must be in chronological order itself, like files written by
+// the same writer.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	log "github.com/YoungPioneers/blog4go"
+)
+
+func main() {
+	output := flag.String("output", "", "file merged logs are written to, stdout if empty")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [--output file] file...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if 0 == flag.NArg() {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := merge(flag.Args(), *output); nil != err {
+		fmt.Fprintf(os.Stderr, "logmerge: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+// merge merges files into output, stdout if output is empty
+func merge(files []string, output string) error {
+	readers := make([]io.Reader, 0, len(files))
+	for _, name := range files {
+		file, err := os.Open(name)
+		if nil != err {
+			return err
+		}
+		defer file.Close()
+		readers = append(readers, file)
+	}
+
+	if "" == output {
+		return log.MergeLogFiles(readers, os.Stdout)
+	}
+
+	file, err := os.Create(output)
+	if nil != err {
+		return err
+	}
+	if err = log.MergeLogFiles(readers, file); nil != err {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -3,13 +3,17 @@
 package blog4go
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"time"
 )
 
 // ConsoleWriter is a console logger
 type ConsoleWriter struct {
+	writerBase
+
 	blog *BLog
 	// for stderr
 	errblog *BLog
@@ -19,6 +23,11 @@ type ConsoleWriter struct {
 	closed bool
 
 	colored bool
+	// color disabled by environment, see respectsEnvColor
+	noColor bool
+
+	// flush after every write when shutting down
+	shutdown bool
 
 	// log hook
 	hook      Hook
@@ -48,6 +57,7 @@ func NewConsoleWriter(redirected bool) (err error) {
 // if redirected, stderr will be redirected to stdout
 func newConsoleWriter(redirected bool) (consoleWriter *ConsoleWriter, err error) {
 	consoleWriter = new(ConsoleWriter)
+	consoleWriter.self = consoleWriter
 	consoleWriter.blog = NewBLog(os.Stdout)
 	consoleWriter.redirected = redirected
 	if !redirected {
@@ -57,6 +67,7 @@ func newConsoleWriter(redirected bool) (consoleWriter *ConsoleWriter, err error)
 	consoleWriter.closed = false
 
 	consoleWriter.colored = false
+	consoleWriter.noColor = respectsEnvColor()
 
 	// log hook
 	consoleWriter.hook = nil
@@ -69,6 +80,12 @@ func newConsoleWriter(redirected bool) (consoleWriter *ConsoleWriter, err error)
 	return consoleWriter, nil
 }
 
+// respectsEnvColor determines whether color is disabled by environment,
+// NO_COLOR set (see https://no-color.org) or TERM=dumb
+func respectsEnvColor() bool {
+	return "" != os.Getenv("NO_COLOR") || "dumb" == os.Getenv("TERM")
+}
+
 func (writer *ConsoleWriter) daemon() {
 	f := time.Tick(1 * time.Second)
 
@@ -105,10 +122,16 @@ func (writer *ConsoleWriter) write(level LevelType, args ...interface{}) {
 
 	if !writer.redirected && level >= WARNING {
 		writer.errblog.write(level, args...)
+		if writer.shutdown {
+			writer.errblog.flush()
+		}
 		return
 	}
 
 	writer.blog.write(level, args...)
+	if writer.shutdown {
+		writer.blog.flush()
+	}
 }
 
 func (writer *ConsoleWriter) writef(level LevelType, format string, args ...interface{}) {
@@ -132,10 +155,16 @@ func (writer *ConsoleWriter) writef(level LevelType, format string, args ...inte
 
 	if !writer.redirected && level >= WARNING {
 		writer.errblog.writef(level, format, args...)
+		if writer.shutdown {
+			writer.errblog.flush()
+		}
 		return
 	}
 
 	writer.blog.writef(level, format, args...)
+	if writer.shutdown {
+		writer.blog.flush()
+	}
 }
 
 // Level get level
@@ -153,9 +182,10 @@ func (writer *ConsoleWriter) Colored() bool {
 	return writer.colored
 }
 
-// SetColored set logging color
+// SetColored set logging color, do nothing if color is disabled by
+// environment
 func (writer *ConsoleWriter) SetColored(colored bool) {
-	if colored == writer.colored {
+	if colored == writer.colored || (colored && writer.noColor) {
 		return
 	}
 
@@ -164,6 +194,22 @@ func (writer *ConsoleWriter) SetColored(colored bool) {
 	initPrefix(colored)
 }
 
+// SetEOL set end of every line
+func (writer *ConsoleWriter) SetEOL(eol []byte) {
+	writer.blog.SetEOL(eol)
+	if nil != writer.errblog {
+		writer.errblog.SetEOL(eol)
+	}
+}
+
+// AddMiddleware add a middleware applied to every message before written
+func (writer *ConsoleWriter) AddMiddleware(middleware Middleware) {
+	writer.blog.AddMiddleware(middleware)
+	if nil != writer.errblog {
+		writer.errblog.AddMiddleware(middleware)
+	}
+}
+
 // SetHook set hook for logging action
 func (writer *ConsoleWriter) SetHook(hook Hook) {
 	writer.hook = hook
@@ -230,6 +276,22 @@ func (writer *ConsoleWriter) SetRotateLines(rotateLines int) {
 	return
 }
 
+// PipeFromWithContext logs every line read from r with given level until r
+// reaches EOF or ctx is done
+func (writer *ConsoleWriter) PipeFromWithContext(ctx context.Context, r io.Reader, level LevelType) error {
+	if writer.closed {
+		return ErrWriterClosed
+	}
+
+	go pipe(ctx, writer, r, level)
+	return nil
+}
+
+// BeginShutdown makes every following write flushed synchronously
+func (writer *ConsoleWriter) BeginShutdown() {
+	writer.shutdown = true
+}
+
 // flush buffer to disk
 func (writer *ConsoleWriter) flush() {
 	writer.blog.flush()
@@ -3,6 +3,8 @@
 package blog4go
 
 import (
+	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -227,3 +229,62 @@ func BenchmarkConsoleWriter(b *testing.B) {
 		blog.Debugf("haha %s. en\\en, always %d and %f", "eddie", 18, 3.1415)
 	}
 }
+
+func TestConsoleWriterOperations(t *testing.T) {
+	writer, err := newConsoleWriter(false)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	blog = nil
+
+	writer.SetEOL(EOLUnix)
+	writer.AddMiddleware(func(level LevelType, message string) string {
+		return "console: " + message
+	})
+	writer.BeginShutdown()
+	writer.Info("info")
+	writer.Errorf("%s", "error")
+
+	if err = writer.PipeFrom(strings.NewReader("piped\n"), INFO); nil != err {
+		t.Errorf("pipe from failed. err: %s", err.Error())
+	}
+	// wait for pipe
+	time.Sleep(10 * time.Millisecond)
+
+	writer.Close()
+	if ErrWriterClosed != writer.PipeFrom(strings.NewReader(""), INFO) {
+		t.Error("pipe from closed writer should fail")
+	}
+}
+
+func TestConsoleWriterNoColor(t *testing.T) {
+	noColor, term := os.Getenv("NO_COLOR"), os.Getenv("TERM")
+	defer func() {
+		os.Setenv("NO_COLOR", noColor)
+		os.Setenv("TERM", term)
+	}()
+
+	os.Setenv("NO_COLOR", "")
+	os.Setenv("TERM", "dumb")
+	if !respectsEnvColor() {
+		t.Error("color should be disabled with TERM=dumb")
+	}
+
+	os.Setenv("TERM", "xterm")
+	if respectsEnvColor() {
+		t.Error("color should not be disabled")
+	}
+
+	os.Setenv("NO_COLOR", "1")
+	writer, err := newConsoleWriter(false)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	blog = nil
+	defer writer.Close()
+
+	writer.SetColored(true)
+	if writer.Colored() {
+		t.Error("color should be disabled with NO_COLOR set")
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// DefaultContentionThreshold is the default lock wait counted as a
+	// contention event
+	DefaultContentionThreshold = time.Millisecond
+)
+
+// lockContention records time waited for the write lock, all fields are
+// accessed atomically since they are read before the lock is held
+type lockContention struct {
+	// 1 if tracking enabled
+	enabled int32
+	// wait longer than threshold nanoseconds is a contention event
+	threshold int64
+	events    int64
+	// max wait observed in nanoseconds
+	maxWait int64
+}
+
+// newLockContention create a disabled lockContention
+func newLockContention() *lockContention {
+	contention := new(lockContention)
+	contention.threshold = int64(DefaultContentionThreshold)
+	return contention
+}
+
+// record counts wait as a contention event if longer than threshold and
+// updates max wait observed
+func (contention *lockContention) record(wait time.Duration) {
+	if int64(wait) > atomic.LoadInt64(&contention.threshold) {
+		atomic.AddInt64(&contention.events, 1)
+	}
+
+	for {
+		max := atomic.LoadInt64(&contention.maxWait)
+		if int64(wait) <= max || atomic.CompareAndSwapInt64(&contention.maxWait, max, int64(wait)) {
+			return
+		}
+	}
+}
+
+// lockWrite holds the write lock, time waited is recorded if contention
+// tracking enabled
+func (blog *BLog) lockWrite() {
+	if 0 == atomic.LoadInt32(&blog.contention.enabled) {
+		blog.lock.Lock()
+		return
+	}
+
+	start := time.Now()
+	blog.lock.Lock()
+	blog.contention.record(time.Since(start))
+}
+
+// SetLockContentionTracking toggle recording of time waited for the write
+// lock, it helps to decide whether writes should be queued, like with
+// FanInWriter
+func (writer *baseFileWriter) SetLockContentionTracking(enabled bool) {
+	var flag int32
+	if enabled {
+		flag = 1
+	}
+	atomic.StoreInt32(&writer.blog.contention.enabled, flag)
+}
+
+// SetContentionThreshold set lock wait longer than d counted as a contention
+// event, default DefaultContentionThreshold
+func (writer *baseFileWriter) SetContentionThreshold(d time.Duration) {
+	atomic.StoreInt64(&writer.blog.contention.threshold, int64(d))
+}
+
+// ContentionEvents return number of lock waits longer than threshold
+func (writer *baseFileWriter) ContentionEvents() int64 {
+	return atomic.LoadInt64(&writer.blog.contention.events)
+}
+
+// MaxLockWait return the longest lock wait observed
+func (writer *baseFileWriter) MaxLockWait() time.Duration {
+	return time.Duration(atomic.LoadInt64(&writer.blog.contention.maxWait))
+}
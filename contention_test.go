@@ -0,0 +1,48 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLockContention(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/contention.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/contention.log")
+	}()
+
+	// hold the lock like a slow disk
+	slow := func() {
+		writer.blog.lock.Lock()
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			writer.blog.lock.Unlock()
+		}()
+	}
+
+	slow()
+	writer.Info("not tracked")
+	if 0 != writer.ContentionEvents() || 0 != writer.MaxLockWait() {
+		t.Error("lock wait should not be recorded if tracking disabled")
+	}
+
+	writer.SetLockContentionTracking(true)
+	writer.SetContentionThreshold(10 * time.Millisecond)
+	writer.Info("fast")
+	slow()
+	writer.Infof("slow %d", 1)
+
+	if 1 != writer.ContentionEvents() {
+		t.Errorf("contention events wrong. events: %d", writer.ContentionEvents())
+	}
+	if writer.MaxLockWait() < 30*time.Millisecond {
+		t.Errorf("max lock wait wrong. wait: %s", writer.MaxLockWait())
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"expvar"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// writers exposed by ExposeExpvars, by name
+	expvarWriters     = make(map[string]*baseFileWriter)
+	expvarWritersLock sync.Mutex
+	// blog4go is published once
+	expvarOnce sync.Once
+)
+
+// writeCounters counts messages written by a file writer, accessed
+// atomically
+type writeCounters struct {
+	lines     int64
+	bytes     int64
+	rotations int64
+	drops     int64
+}
+
+// written counts a message of size written
+func (counters *writeCounters) written(size int) {
+	atomic.AddInt64(&counters.lines, 1)
+	atomic.AddInt64(&counters.bytes, int64(size))
+}
+
+// rotated counts a logrotate
+func (counters *writeCounters) rotated() {
+	atomic.AddInt64(&counters.rotations, 1)
+}
+
+// dropped counts a message dropped
+func (counters *writeCounters) dropped() {
+	atomic.AddInt64(&counters.drops, 1)
+}
+
+// snapshot return counters as expvar metrics
+func (counters *writeCounters) snapshot() map[string]int64 {
+	return map[string]int64{
+		"lines_written":  atomic.LoadInt64(&counters.lines),
+		"bytes_written":  atomic.LoadInt64(&counters.bytes),
+		"rotation_count": atomic.LoadInt64(&counters.rotations),
+		"drop_count":     atomic.LoadInt64(&counters.drops),
+	}
+}
+
+// ExposeExpvars publishes metrics of the writer under blog4go.name in
+// expvar, like blog4go.name.lines_written, so that they appear at
+// /debug/vars. Writer exposed before with the same name is replaced. The
+// returned function removes the metrics.
+func (writer *baseFileWriter) ExposeExpvars(name string) func() {
+	expvarOnce.Do(func() {
+		expvar.Publish("blog4go", expvar.Func(expvarMetrics))
+	})
+
+	expvarWritersLock.Lock()
+	defer expvarWritersLock.Unlock()
+	expvarWriters[name] = writer
+
+	return func() {
+		expvarWritersLock.Lock()
+		defer expvarWritersLock.Unlock()
+		if writer == expvarWriters[name] {
+			delete(expvarWriters, name)
+		}
+	}
+}
+
+// expvarMetrics return metrics of writers exposed, by name
+func expvarMetrics() interface{} {
+	expvarWritersLock.Lock()
+	defer expvarWritersLock.Unlock()
+
+	metrics := make(map[string]map[string]int64, len(expvarWriters))
+	for name, writer := range expvarWriters {
+		metrics[name] = writer.counters.snapshot()
+	}
+	return metrics
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"encoding/json"
+	"expvar"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestBaseFileWriterExposeExpvars(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/expvar.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/expvar.log")
+	}()
+
+	remove := writer.ExposeExpvars("app")
+	// exposed twice
+	defer writer.ExposeExpvars("another")()
+
+	writer.Info("info")
+	writer.Errorf("%s", "error")
+	writer.resetFile()
+	writer.Drain(time.Second)
+	writer.Info("dropped")
+
+	var metrics map[string]map[string]int64
+	if err = json.Unmarshal([]byte(expvar.Get("blog4go").String()), &metrics); nil != err {
+		t.Fatalf("decode expvar failed. err: %s", err.Error())
+	}
+
+	app := metrics["app"]
+	if 2 != app["lines_written"] || 0 == app["bytes_written"] || 1 != app["rotation_count"] || 1 != app["drop_count"] {
+		t.Errorf("metrics wrong. metrics: %v", app)
+	}
+
+	remove()
+	if _, ok := expvarMetrics().(map[string]map[string]int64)["app"]; ok {
+		t.Error("metrics should be removed")
+	}
+}
@@ -0,0 +1,297 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"container/heap"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// fanInItem is an entry queued, or a flush marker if flushed is not nil
+type fanInItem struct {
+	entry   *Entry
+	flushed chan struct{}
+	// order submitted
+	seq uint64
+}
+
+// level return level of the entry, flush markers are lower than any level
+func (item fanInItem) level() LevelType {
+	if nil == item.entry {
+		return LevelType(-1)
+	}
+	return item.entry.Level
+}
+
+// fanInQueue is a heap of items in order submitted, or by level then order
+// submitted if priority
+type fanInQueue struct {
+	items    []fanInItem
+	priority bool
+}
+
+func (q *fanInQueue) Len() int { return len(q.items) }
+func (q *fanInQueue) Less(i, j int) bool {
+	if q.priority && q.items[i].level() != q.items[j].level() {
+		return q.items[i].level() > q.items[j].level()
+	}
+	return q.items[i].seq < q.items[j].seq
+}
+func (q *fanInQueue) Swap(i, j int)      { q.items[i], q.items[j] = q.items[j], q.items[i] }
+func (q *fanInQueue) Push(x interface{}) { q.items = append(q.items, x.(fanInItem)) }
+func (q *fanInQueue) Pop() interface{} {
+	item := q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	return item
+}
+
+// FanInWriter wraps a writer, entries submitted by any goroutines are queued
+// and written to the writer by a single goroutine, so callers never contend
+// on the lock of the writer. Submit blocks when the queue is full. Time of
+// entries is decided by the writer wrapped when written.
+type FanInWriter struct {
+	writerWrapper
+
+	queue    *fanInQueue
+	capacity int
+	// number of items submitted
+	seq uint64
+	// closed when every entry queued is written after Close
+	done chan struct{}
+
+	// sign of closed, default false
+	closed bool
+
+	lock *sync.Mutex
+	// signaled when an item is queued, or an item is dequeued
+	queued   *sync.Cond
+	dequeued *sync.Cond
+}
+
+// NewFanInWriter create a FanInWriter wrapping writer with a queue of
+// queueSize entries, at least 1
+func NewFanInWriter(writer Writer, queueSize int) *FanInWriter {
+	if queueSize < 1 {
+		queueSize = 1
+	}
+
+	fanIn := new(FanInWriter)
+	fanIn.Writer = writer
+	fanIn.self = fanIn
+	fanIn.queue = new(fanInQueue)
+	fanIn.capacity = queueSize
+	fanIn.done = make(chan struct{})
+	fanIn.lock = new(sync.Mutex)
+	fanIn.queued = sync.NewCond(fanIn.lock)
+	fanIn.dequeued = sync.NewCond(fanIn.lock)
+
+	go fanIn.daemon()
+	return fanIn
+}
+
+// SetPriorityQueue toggle dequeuing entries of higher level first, like
+// CRITICAL ahead of pending DEBUG. Entries of the same level are written in
+// order submitted. Entries are written in order submitted by default.
+func (fanIn *FanInWriter) SetPriorityQueue(enabled bool) {
+	fanIn.lock.Lock()
+	defer fanIn.lock.Unlock()
+
+	fanIn.queue.priority = enabled
+	heap.Init(fanIn.queue)
+}
+
+// daemon writes entries queued until Close and every entry queued written
+func (fanIn *FanInWriter) daemon() {
+	defer close(fanIn.done)
+
+	for {
+		fanIn.lock.Lock()
+		for 0 == fanIn.queue.Len() && !fanIn.closed {
+			fanIn.queued.Wait()
+		}
+		if 0 == fanIn.queue.Len() {
+			fanIn.lock.Unlock()
+			return
+		}
+		item := heap.Pop(fanIn.queue).(fanInItem)
+		fanIn.dequeued.Signal()
+		fanIn.lock.Unlock()
+
+		if nil != item.flushed {
+			fanIn.Writer.flush()
+			close(item.flushed)
+			continue
+		}
+		fanIn.Writer.write(item.entry.Level, item.entry.Message)
+	}
+}
+
+// enqueue queues item, it blocks while the queue is full. false is returned
+// if closed.
+func (fanIn *FanInWriter) enqueue(item fanInItem) bool {
+	fanIn.lock.Lock()
+	defer fanIn.lock.Unlock()
+
+	for fanIn.queue.Len() >= fanIn.capacity && !fanIn.closed {
+		fanIn.dequeued.Wait()
+	}
+	if fanIn.closed {
+		return false
+	}
+
+	fanIn.seq++
+	item.seq = fanIn.seq
+	heap.Push(fanIn.queue, item)
+	fanIn.queued.Signal()
+	return true
+}
+
+// Submit queues entry to be written, entry is dropped if closed
+func (fanIn *FanInWriter) Submit(entry *Entry) {
+	fanIn.enqueue(fanInItem{entry: entry})
+}
+
+// Close stops accepting entries, closes the writer wrapped after every
+// entry queued is written
+func (fanIn *FanInWriter) Close() {
+	fanIn.lock.Lock()
+	if fanIn.closed {
+		fanIn.lock.Unlock()
+		return
+	}
+	fanIn.closed = true
+	fanIn.queued.Broadcast()
+	fanIn.dequeued.Broadcast()
+	fanIn.lock.Unlock()
+
+	<-fanIn.done
+	fanIn.Writer.Close()
+}
+
+// flush waits for entries queued before written, then flushes the writer
+// wrapped. With priority queue, entries submitted later are written before
+// flushed as well.
+func (fanIn *FanInWriter) flush() {
+	flushed := make(chan struct{})
+	if fanIn.enqueue(fanInItem{flushed: flushed}) {
+		<-flushed
+	}
+}
+
+func (fanIn *FanInWriter) write(level LevelType, args ...interface{}) {
+	fanIn.Submit(&Entry{Time: time.Now(), Level: level, Message: fmt.Sprint(args...)})
+}
+
+func (fanIn *FanInWriter) writef(level LevelType, format string, args ...interface{}) {
+	fanIn.Submit(&Entry{Time: time.Now(), Level: level, Message: fmt.Sprintf(format, args...)})
+}
+
+// Trace trace
+func (fanIn *FanInWriter) Trace(args ...interface{}) {
+	if TRACE < fanIn.Level() {
+		return
+	}
+
+	fanIn.write(TRACE, args...)
+}
+
+// Tracef tracef
+func (fanIn *FanInWriter) Tracef(format string, args ...interface{}) {
+	if TRACE < fanIn.Level() {
+		return
+	}
+
+	fanIn.writef(TRACE, format, args...)
+}
+
+// Debug debug
+func (fanIn *FanInWriter) Debug(args ...interface{}) {
+	if DEBUG < fanIn.Level() {
+		return
+	}
+
+	fanIn.write(DEBUG, args...)
+}
+
+// Debugf debugf
+func (fanIn *FanInWriter) Debugf(format string, args ...interface{}) {
+	if DEBUG < fanIn.Level() {
+		return
+	}
+
+	fanIn.writef(DEBUG, format, args...)
+}
+
+// Info info
+func (fanIn *FanInWriter) Info(args ...interface{}) {
+	if INFO < fanIn.Level() {
+		return
+	}
+
+	fanIn.write(INFO, args...)
+}
+
+// Infof infof
+func (fanIn *FanInWriter) Infof(format string, args ...interface{}) {
+	if INFO < fanIn.Level() {
+		return
+	}
+
+	fanIn.writef(INFO, format, args...)
+}
+
+// Warn warn
+func (fanIn *FanInWriter) Warn(args ...interface{}) {
+	if WARNING < fanIn.Level() {
+		return
+	}
+
+	fanIn.write(WARNING, args...)
+}
+
+// Warnf warnf
+func (fanIn *FanInWriter) Warnf(format string, args ...interface{}) {
+	if WARNING < fanIn.Level() {
+		return
+	}
+
+	fanIn.writef(WARNING, format, args...)
+}
+
+// Error error
+func (fanIn *FanInWriter) Error(args ...interface{}) {
+	if ERROR < fanIn.Level() {
+		return
+	}
+
+	fanIn.write(ERROR, args...)
+}
+
+// Errorf errorf
+func (fanIn *FanInWriter) Errorf(format string, args ...interface{}) {
+	if ERROR < fanIn.Level() {
+		return
+	}
+
+	fanIn.writef(ERROR, format, args...)
+}
+
+// Critical critical
+func (fanIn *FanInWriter) Critical(args ...interface{}) {
+	if CRITICAL < fanIn.Level() {
+		return
+	}
+
+	fanIn.write(CRITICAL, args...)
+}
+
+// Criticalf criticalf
+func (fanIn *FanInWriter) Criticalf(format string, args ...interface{}) {
+	if CRITICAL < fanIn.Level() {
+		return
+	}
+
+	fanIn.writef(CRITICAL, format, args...)
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"container/heap"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestFanInWriter(t *testing.T) {
+	writer, err := newRingBufferWriter(1000)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	fanIn := NewFanInWriter(writer, 10)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				fanIn.Submit(&Entry{Level: INFO, Message: fmt.Sprintf("%d-%d", i, j)})
+			}
+		}(i)
+	}
+	wg.Wait()
+	fanIn.Warnf("from %s", "writer")
+
+	fanIn.flush()
+	entries := writer.Entries()
+	if 101 != len(entries) {
+		t.Fatalf("every entry should be written. count: %d", len(entries))
+	}
+	if WARNING != entries[100].Level || "from writer" != entries[100].Message {
+		t.Errorf("entry written wrong. entry: %+v", entries[100])
+	}
+
+	fanIn.Close()
+	if !writer.Closed() {
+		t.Error("writer wrapped should be closed")
+	}
+
+	// dropped after closed
+	fanIn.Info("dropped")
+	fanIn.flush()
+	if 101 != len(writer.Entries()) {
+		t.Error("entries should be dropped after closed")
+	}
+}
+
+func TestFanInWriterPriorityQueue(t *testing.T) {
+	writer, err := newRingBufferWriter(100)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	fanIn := NewFanInWriter(writer, 100)
+	fanIn.SetPriorityQueue(true)
+
+	// hold the daemon until every entry queued
+	fanIn.lock.Lock()
+	for i, level := range []LevelType{DEBUG, DEBUG, INFO, CRITICAL, DEBUG, CRITICAL} {
+		fanIn.seq++
+		heap.Push(fanIn.queue, fanInItem{entry: &Entry{Level: level, Message: fmt.Sprint(i)}, seq: fanIn.seq})
+	}
+	fanIn.queued.Signal()
+	fanIn.lock.Unlock()
+
+	fanIn.flush()
+	expected := []string{"3", "5", "2", "0", "1", "4"}
+	entries := writer.Entries()
+	if len(expected) != len(entries) {
+		t.Fatalf("every entry should be written. count: %d", len(entries))
+	}
+	for i, entry := range entries {
+		if expected[i] != entry.Message {
+			t.Errorf("entries dequeued in wrong order. entries: %v", writer.Lines())
+			break
+		}
+	}
+	fanIn.Close()
+}
@@ -19,6 +19,7 @@ func NewFileWriter(baseDir string, rotate bool) (err error) {
 	}
 
 	fileWriter := new(MultiWriter)
+	fileWriter.self = fileWriter
 	fileWriter.level = DEBUG
 	fileWriter.closed = false
 
@@ -36,7 +37,64 @@ func NewFileWriter(baseDir string, rotate bool) (err error) {
 	fileWriter.hook = nil
 	fileWriter.hookLevel = DEBUG
 	fileWriter.hookAsync = true
+	fileWriter.hooks = NewHookManager()
 
 	blog = fileWriter
 	return
 }
+
+// NewLevelSplitWriter initialize a writer which writes logs of each level
+// to the file given in patterns. Levels with the same file path share one
+// file writer. Levels not given in patterns are not logged.
+func NewLevelSplitWriter(patterns map[LevelType]string) (err error) {
+	singltonLock.Lock()
+	defer singltonLock.Unlock()
+	if nil != blog {
+		return ErrAlreadyInit
+	}
+
+	splitWriter := new(MultiWriter)
+	splitWriter.self = splitWriter
+	splitWriter.level = DEBUG
+	splitWriter.closed = false
+
+	// file writers shared by levels with the same path
+	fileWriters := make(map[string]*baseFileWriter)
+	defer func() {
+		if nil != err {
+			for _, writer := range fileWriters {
+				writer.Close()
+			}
+		}
+	}()
+
+	splitWriter.writers = make(map[LevelType]Writer)
+	for level, fileName := range patterns {
+		if !level.valid() {
+			return ErrInvalidLevel
+		}
+
+		if "" == fileName {
+			return ErrFilePathNotFound
+		}
+
+		writer, ok := fileWriters[fileName]
+		if !ok {
+			writer, err = newBaseFileWriter(fileName, false)
+			if nil != err {
+				return err
+			}
+			fileWriters[fileName] = writer
+		}
+		splitWriter.writers[level] = writer
+	}
+
+	// log hook
+	splitWriter.hook = nil
+	splitWriter.hookLevel = DEBUG
+	splitWriter.hookAsync = true
+	splitWriter.hooks = NewHookManager()
+
+	blog = splitWriter
+	return
+}
@@ -4,7 +4,9 @@ package blog4go
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"strconv"
@@ -474,3 +476,194 @@ func BenchmarkFileWriters(b *testing.B) {
 	}
 	Flush()
 }
+
+func TestLevelSplitWriter(t *testing.T) {
+	err := NewLevelSplitWriter(map[LevelType]string{
+		DEBUG:    "/tmp/app.debug.log",
+		INFO:     "/tmp/app.debug.log",
+		ERROR:    "/tmp/app.error.log",
+		CRITICAL: "/tmp/app.error.log",
+	})
+	defer func() {
+		Close()
+
+		// clean logs
+		_, err = exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	if nil != err {
+		t.Fatalf("initialize level split writer failed. err: %s", err.Error())
+	}
+
+	multiWriter := blog.(*MultiWriter)
+	if multiWriter.writers[DEBUG] != multiWriter.writers[INFO] || multiWriter.writers[ERROR] != multiWriter.writers[CRITICAL] {
+		t.Error("levels with the same path should share file writer")
+	}
+	if multiWriter.writers[DEBUG] == multiWriter.writers[ERROR] {
+		t.Error("levels with different path should not share file writer")
+	}
+
+	Debug("debug")
+	Info("info")
+	Warn("warn")
+	Error("error")
+	Critical("critical")
+	Flush()
+
+	debugContent, _ := ioutil.ReadFile("/tmp/app.debug.log")
+	errorContent, _ := ioutil.ReadFile("/tmp/app.error.log")
+	if 2 != strings.Count(string(debugContent), "\n") || strings.Contains(string(debugContent), "error") {
+		t.Errorf("debug log content wrong. content: %s", debugContent)
+	}
+	if 2 != strings.Count(string(errorContent), "\n") || strings.Contains(string(errorContent), "warn") {
+		t.Errorf("error log content wrong. content: %s", errorContent)
+	}
+}
+
+func TestLevelSplitWriterInvalidPatterns(t *testing.T) {
+	if err := NewLevelSplitWriter(map[LevelType]string{LevelType(-1): "/tmp/app.log"}); ErrInvalidLevel != err {
+		t.Error("invalid level should fail")
+	}
+
+	if err := NewLevelSplitWriter(map[LevelType]string{INFO: ""}); ErrFilePathNotFound != err {
+		t.Error("empty file path should fail")
+	}
+}
+
+func TestLevelSplitWriterOperations(t *testing.T) {
+	err := NewLevelSplitWriter(map[LevelType]string{
+		INFO:  "/tmp/app.info.log",
+		ERROR: "/tmp/app.error.log",
+	})
+	defer func() {
+		Close()
+		os.Remove("/tmp/app.info.log")
+		os.Remove("/tmp/app.error.log")
+	}()
+
+	if nil != err {
+		t.Fatalf("initialize level split writer failed. err: %s", err.Error())
+	}
+
+	SetEOL(EOLWindows)
+	SetLineWrap(8)
+	SetLineWrapMarker("> ")
+	AddMiddleware(func(level LevelType, message string) string {
+		return strings.ToUpper(message)
+	})
+	BeginShutdown()
+
+	Info("info message")
+	if err = PipeFrom(strings.NewReader("piped\n"), ERROR); nil != err {
+		t.Errorf("pipe from failed. err: %s", err.Error())
+	}
+	if err = PipeFromWithContext(context.Background(), strings.NewReader("piped\n"), INFO); nil != err {
+		t.Errorf("pipe from with context failed. err: %s", err.Error())
+	}
+	// wait for pipe
+	time.Sleep(10 * time.Millisecond)
+
+	if err = Drain(time.Second); nil != err {
+		t.Errorf("drain failed. err: %s", err.Error())
+	}
+	Info("dropped")
+
+	infoContent, _ := ioutil.ReadFile("/tmp/app.info.log")
+	errorContent, _ := ioutil.ReadFile("/tmp/app.error.log")
+	if !strings.Contains(string(infoContent), "] INFO MES\r\n> SAGE\r\n") || !strings.Contains(string(infoContent), "] PIPED\r\n") {
+		t.Errorf("info log content wrong. content: %q", infoContent)
+	}
+	if strings.Contains(string(infoContent), "DROPPED") {
+		t.Errorf("message should be dropped after drain. content: %q", infoContent)
+	}
+	if !strings.HasSuffix(string(errorContent), "] PIPED\r\n") {
+		t.Errorf("error log content wrong. content: %q", errorContent)
+	}
+}
+
+func TestFileWriterAsConfigFileTimeFormat(t *testing.T) {
+	config := `<blog4go minlevel="info">
+	<filter levels="info,error">
+		<file path="/tmp/configTimeFormat.log"></file>
+	</filter>
+</blog4go>`
+	if err := ioutil.WriteFile("/tmp/configTimeFormat.xml", []byte(config), 0644); nil != err {
+		t.Fatalf("write config failed. err: %s", err.Error())
+	}
+	defer os.Remove("/tmp/configTimeFormat.xml")
+
+	if err := NewWriterFromConfigAsFile("/tmp/configTimeFormat.xml"); nil != err {
+		t.Fatalf("initialize writer from config failed. err: %s", err.Error())
+	}
+	defer func() {
+		Close()
+		os.Remove("/tmp/configTimeFormat.log")
+	}()
+
+	writers := fileWriters()
+	if 2 != len(writers) {
+		t.Fatalf("file writers wrong. writers: %d", len(writers))
+	}
+	// writers of the same file share the time cache
+	if writers[0].blog.timeCache != writers[0].timeCache || writers[1].blog.timeCache != writers[0].timeCache {
+		t.Fatal("time cache of writers built from config wrong")
+	}
+
+	writers[0].SetTimeFormat(time.RFC3339, time.UTC)
+	Info("info")
+	Error("error")
+	for _, writer := range writers {
+		writer.flush()
+	}
+
+	data, err := ioutil.ReadFile("/tmp/configTimeFormat.log")
+	if nil != err {
+		t.Fatalf("read log failed. err: %s", err.Error())
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if 2 != len(lines) {
+		t.Fatalf("lines written wrong. lines: %v", lines)
+	}
+	for _, line := range lines {
+		if _, err := time.Parse(time.RFC3339, line[:strings.Index(line, " ")]); nil != err || !strings.Contains(line, "Z [") {
+			t.Errorf("time format not applied. line: %s", line)
+		}
+	}
+}
+
+func TestFileWriterAsConfigFileMiddleware(t *testing.T) {
+	config := `<blog4go minlevel="info">
+	<filter levels="info,warn,error">
+		<file path="/tmp/configMiddleware.log"></file>
+	</filter>
+</blog4go>`
+	if err := ioutil.WriteFile("/tmp/configMiddleware.xml", []byte(config), 0644); nil != err {
+		t.Fatalf("write config failed. err: %s", err.Error())
+	}
+	defer os.Remove("/tmp/configMiddleware.xml")
+
+	if err := NewWriterFromConfigAsFile("/tmp/configMiddleware.xml"); nil != err {
+		t.Fatalf("initialize writer from config failed. err: %s", err.Error())
+	}
+	defer func() {
+		Close()
+		os.Remove("/tmp/configMiddleware.log")
+	}()
+
+	AddMiddleware(func(level LevelType, message string) string {
+		return message + "!"
+	})
+	Info("hi")
+	Flush()
+
+	data, err := ioutil.ReadFile("/tmp/configMiddleware.log")
+	if nil != err {
+		t.Fatalf("read log failed. err: %s", err.Error())
+	}
+	if !strings.HasSuffix(string(data), "[INFO] hi!\n") {
+		t.Errorf("middleware should be applied once. content: %q", data)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"sync"
+	"time"
+)
+
+// LevelHistogram counts messages written per level in the last buckets of
+// bucketDuration. The current bucket is advanced by daemon of writer, so
+// bucketDuration is accurate to a second.
+type LevelHistogram struct {
+	bucketDuration time.Duration
+
+	// circular buffer of counts
+	buckets []map[LevelType]int64
+	// position of the current bucket
+	current int
+	// start time of the current bucket
+	start time.Time
+
+	lock *sync.Mutex
+}
+
+// newLevelHistogram create a LevelHistogram, nil if buckets or
+// bucketDuration is not positive
+func newLevelHistogram(buckets int, bucketDuration time.Duration) *LevelHistogram {
+	if buckets < 1 || bucketDuration <= 0 {
+		return nil
+	}
+
+	histogram := new(LevelHistogram)
+	histogram.bucketDuration = bucketDuration
+	histogram.buckets = make([]map[LevelType]int64, buckets)
+	for i := range histogram.buckets {
+		histogram.buckets[i] = make(map[LevelType]int64)
+	}
+	histogram.start = time.Now()
+	histogram.lock = new(sync.Mutex)
+	return histogram
+}
+
+// add counts a message with level in the current bucket
+func (histogram *LevelHistogram) add(level LevelType) {
+	histogram.lock.Lock()
+	defer histogram.lock.Unlock()
+	histogram.buckets[histogram.current][level]++
+}
+
+// advance moves to the bucket now belongs to, buckets skipped are cleared
+func (histogram *LevelHistogram) advance(now time.Time) {
+	histogram.lock.Lock()
+	defer histogram.lock.Unlock()
+
+	n := int64(now.Sub(histogram.start) / histogram.bucketDuration)
+	if n < 1 {
+		return
+	}
+	histogram.start = histogram.start.Add(time.Duration(n) * histogram.bucketDuration)
+
+	for i := int64(0); i < n && i < int64(len(histogram.buckets)); i++ {
+		histogram.current = (histogram.current + 1) % len(histogram.buckets)
+		histogram.buckets[histogram.current] = make(map[LevelType]int64)
+	}
+}
+
+// Snapshot return a copy of counts of buckets in chronological order, the
+// last one is the current bucket
+func (histogram *LevelHistogram) Snapshot() []map[LevelType]int64 {
+	histogram.lock.Lock()
+	defer histogram.lock.Unlock()
+
+	snapshot := make([]map[LevelType]int64, 0, len(histogram.buckets))
+	for i := 1; i <= len(histogram.buckets); i++ {
+		bucket := histogram.buckets[(histogram.current+i)%len(histogram.buckets)]
+		counts := make(map[LevelType]int64, len(bucket))
+		for level, count := range bucket {
+			counts[level] = count
+		}
+		snapshot = append(snapshot, counts)
+	}
+	return snapshot
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLevelHistogram(t *testing.T) {
+	if nil != newLevelHistogram(0, time.Second) || nil != newLevelHistogram(3, 0) {
+		t.Error("histogram with invalid arguments should be nil")
+	}
+
+	histogram := newLevelHistogram(3, time.Minute)
+	start := histogram.start
+
+	histogram.add(INFO)
+	histogram.advance(start.Add(30 * time.Second))
+	histogram.add(ERROR)
+	histogram.advance(start.Add(time.Minute))
+	histogram.add(INFO)
+	histogram.add(INFO)
+
+	snapshot := histogram.Snapshot()
+	if 3 != len(snapshot) || 0 != len(snapshot[0]) || 1 != snapshot[1][INFO] || 1 != snapshot[1][ERROR] || 2 != snapshot[2][INFO] {
+		t.Errorf("snapshot wrong. snapshot: %v", snapshot)
+	}
+
+	// snapshot is a copy
+	snapshot[2][INFO] = 100
+	if 2 != histogram.Snapshot()[2][INFO] {
+		t.Error("snapshot should be a copy")
+	}
+
+	// buckets skipped are cleared
+	histogram.advance(start.Add(3 * time.Minute))
+	histogram.add(WARNING)
+	snapshot = histogram.Snapshot()
+	if 2 != snapshot[0][INFO] || 0 != len(snapshot[1]) || 1 != snapshot[2][WARNING] {
+		t.Errorf("snapshot wrong after advanced. snapshot: %v", snapshot)
+	}
+
+	histogram.advance(start.Add(time.Hour))
+	for _, bucket := range histogram.Snapshot() {
+		if 0 != len(bucket) {
+			t.Errorf("every bucket should be cleared. bucket: %v", bucket)
+		}
+	}
+}
+
+func TestBaseFileWriterHistogram(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/histogram.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/histogram.log")
+	}()
+
+	histogram := writer.Histogram(5, time.Hour)
+	writer.Info("info")
+	writer.Errorf("%s", "error")
+
+	snapshot := histogram.Snapshot()
+	if 1 != snapshot[4][INFO] || 1 != snapshot[4][ERROR] {
+		t.Errorf("messages counted wrong. snapshot: %v", snapshot)
+	}
+
+	if nil != writer.Histogram(0, time.Hour) || nil != writer.levelHistogram() {
+		t.Error("histogram should be disabled")
+	}
+}
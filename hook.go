@@ -2,6 +2,13 @@
 
 package blog4go
 
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
 // Hook Interface determine types of functions should be declared and
 // implemented when user offers user defined function call before every
 // logging action end.
@@ -12,3 +19,189 @@ package blog4go
 type Hook interface {
 	Fire(level LevelType, args ...interface{})
 }
+
+// NopHook is a hook doing nothing, like in benchmarks of hook dispatch
+type NopHook struct{}
+
+// Fire do nothing
+func (hook NopHook) Fire(level LevelType, args ...interface{}) {
+	return
+}
+
+// CountingHook is a hook counting times it is fired, safe for async hooks
+type CountingHook struct {
+	count int64
+}
+
+// Fire increments the counter
+func (hook *CountingHook) Fire(level LevelType, args ...interface{}) {
+	atomic.AddInt64(&hook.count, 1)
+}
+
+// Count return times the hook is fired
+func (hook *CountingHook) Count() int64 {
+	return atomic.LoadInt64(&hook.count)
+}
+
+// RateLimitedHook fires inner hook at most maxPerSecond times a second with
+// a token bucket, fires exceed are dropped
+type RateLimitedHook struct {
+	inner Hook
+
+	// tokens left in bucket, bucket is full at capacity
+	tokens   int64
+	capacity int64
+	dropped  int64
+
+	ticker *time.Ticker
+	stop   chan struct{}
+	once   *sync.Once
+}
+
+// NewRateLimitedHook create a RateLimitedHook wrapping inner, bucket is
+// refilled by a ticker until Stop is called. maxPerSecond less than 1 is
+// treated as 1.
+func NewRateLimitedHook(inner Hook, maxPerSecond int) *RateLimitedHook {
+	if maxPerSecond < 1 {
+		maxPerSecond = 1
+	}
+
+	// refill a token every interval, ticker faster than a millisecond is
+	// too costly, refill more tokens a millisecond instead
+	interval := time.Second / time.Duration(maxPerSecond)
+	if interval < time.Millisecond {
+		interval = time.Millisecond
+	}
+
+	hook := new(RateLimitedHook)
+	hook.inner = inner
+	hook.tokens = int64(maxPerSecond)
+	hook.capacity = int64(maxPerSecond)
+	hook.ticker = time.NewTicker(interval)
+	hook.stop = make(chan struct{})
+	hook.once = new(sync.Once)
+
+	go hook.refill(int64(maxPerSecond))
+	return hook
+}
+
+// refill adds tokens for time elapsed every tick until bucket is full,
+// fractions of a token are carried to the next tick
+func (hook *RateLimitedHook) refill(rate int64) {
+	// tokens carried multiplied by time.Second
+	var carried int64
+	last := time.Now()
+	for {
+		select {
+		case now := <-hook.ticker.C:
+			// bucket is full after a second anyway
+			elapsed := now.Sub(last)
+			if elapsed > time.Second {
+				elapsed = time.Second
+			}
+			last = now
+
+			carried += rate * int64(elapsed)
+			n := carried / int64(time.Second)
+			carried %= int64(time.Second)
+			if 0 == n {
+				continue
+			}
+
+			for {
+				tokens := atomic.LoadInt64(&hook.tokens)
+				if tokens >= hook.capacity {
+					break
+				}
+				next := tokens + n
+				if next > hook.capacity {
+					next = hook.capacity
+				}
+				if atomic.CompareAndSwapInt64(&hook.tokens, tokens, next) {
+					break
+				}
+			}
+		case <-hook.stop:
+			return
+		}
+	}
+}
+
+// Fire takes a token and fires inner hook, dropped if bucket is empty
+func (hook *RateLimitedHook) Fire(level LevelType, args ...interface{}) {
+	for {
+		tokens := atomic.LoadInt64(&hook.tokens)
+		if tokens <= 0 {
+			atomic.AddInt64(&hook.dropped, 1)
+			return
+		}
+		if atomic.CompareAndSwapInt64(&hook.tokens, tokens, tokens-1) {
+			break
+		}
+	}
+
+	hook.inner.Fire(level, args...)
+}
+
+// DroppedFires return times Fire is dropped for bucket is empty
+func (hook *RateLimitedHook) DroppedFires() int64 {
+	return atomic.LoadInt64(&hook.dropped)
+}
+
+// Stop stops refilling bucket, fires after bucket is empty are all dropped
+func (hook *RateLimitedHook) Stop() {
+	hook.once.Do(func() {
+		hook.ticker.Stop()
+		close(hook.stop)
+	})
+}
+
+// HookManager keeps hooks identified by id, hooks can be added or removed
+// on the fly while logging
+type HookManager struct {
+	// map id to Hook
+	hooks sync.Map
+}
+
+// NewHookManager create an empty HookManager
+func NewHookManager() *HookManager {
+	return new(HookManager)
+}
+
+// AddHook add hook with id, hook with the same id is replaced
+func (manager *HookManager) AddHook(id string, hook Hook) {
+	manager.hooks.Store(id, hook)
+}
+
+// RemoveHook remove hook with id
+func (manager *HookManager) RemoveHook(id string) {
+	manager.hooks.Delete(id)
+}
+
+// ListHooks return sorted ids of hooks kept
+func (manager *HookManager) ListHooks() []string {
+	ids := make([]string, 0)
+	manager.hooks.Range(func(id, hook interface{}) bool {
+		ids = append(ids, id.(string))
+		return true
+	})
+	sort.Strings(ids)
+	return ids
+}
+
+// snapshot return hooks kept at the moment
+func (manager *HookManager) snapshot() []Hook {
+	var hooks []Hook
+	manager.hooks.Range(func(id, hook interface{}) bool {
+		hooks = append(hooks, hook.(Hook))
+		return true
+	})
+	return hooks
+}
+
+// fireHooks calls every hook, in no particular order
+func fireHooks(hooks []Hook, level LevelType, args ...interface{}) {
+	for _, hook := range hooks {
+		hook.Fire(level, args...)
+	}
+}
@@ -110,3 +110,140 @@ func TestHook(t *testing.T) {
 		t.Errorf("clean files failed. err: %s", err.Error())
 	}
 }
+
+func TestHookManager(t *testing.T) {
+	hook := NewMyHook()
+	another := NewMyHook()
+
+	err := NewFileWriter("/tmp", false)
+	defer func() {
+		Close()
+		exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+	}()
+	if nil != err {
+		t.Fatalf("initialize file writer faied. err: %s", err.Error())
+	}
+
+	SetHookAsync(false)
+	SetHookLevel(INFO)
+	AddHook("b", hook)
+	AddHook("a", another)
+	if ids := ListHooks(); 2 != len(ids) || "a" != ids[0] || "b" != ids[1] {
+		t.Errorf("hooks listed wrong. ids: %v", ids)
+	}
+
+	Debug("ignored")
+	Info("both")
+	if 1 != hook.Cnt() || 1 != another.Cnt() || "both" != hook.Message() {
+		t.Errorf("hooks added should be called. count: %d, %d", hook.Cnt(), another.Cnt())
+	}
+
+	RemoveHook("a")
+	Errorf("%s", "one")
+	if 2 != hook.Cnt() || 1 != another.Cnt() || "one" != hook.Message() {
+		t.Errorf("hook removed should not be called. count: %d, %d", hook.Cnt(), another.Cnt())
+	}
+
+	// add and remove hooks while logging
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprintf("hook%d", i)
+			AddHook(id, NewMyHook())
+			RemoveHook(id)
+		}(i)
+		go func() {
+			defer wg.Done()
+			Info("concurrent")
+		}()
+	}
+	wg.Wait()
+
+	if ids := ListHooks(); 1 != len(ids) || "b" != ids[0] {
+		t.Errorf("hooks listed wrong. ids: %v", ids)
+	}
+}
+
+func TestCountingHook(t *testing.T) {
+	manager := NewHookManager()
+	counting := new(CountingHook)
+	manager.AddHook("nop", NopHook{})
+	manager.AddHook("counting", counting)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fireHooks(manager.snapshot(), INFO, "message")
+		}()
+	}
+	wg.Wait()
+
+	if 10 != counting.Count() {
+		t.Errorf("counting hook count wrong. count: %d", counting.Count())
+	}
+}
+
+func TestRateLimitedHook(t *testing.T) {
+	counting := new(CountingHook)
+	hook := NewRateLimitedHook(counting, 5)
+	// stop refilling to keep tokens exactly a full bucket
+	hook.Stop()
+	hook.Stop()
+
+	for i := 0; i < 20; i++ {
+		hook.Fire(CRITICAL, "message")
+	}
+
+	if 5 != counting.Count() || 15 != hook.DroppedFires() {
+		t.Errorf("rate limited hook wrong. fired: %d, dropped: %d", counting.Count(), hook.DroppedFires())
+	}
+
+	refilled := NewRateLimitedHook(counting, 100)
+	defer refilled.Stop()
+	for i := 0; i < 100; i++ {
+		refilled.Fire(CRITICAL, "message")
+	}
+	time.Sleep(100 * time.Millisecond)
+	refilled.Fire(CRITICAL, "message")
+
+	if 0 != refilled.DroppedFires() || 106 != counting.Count() {
+		t.Errorf("bucket not refilled. fired: %d, dropped: %d", counting.Count(), refilled.DroppedFires())
+	}
+}
+
+func TestRateLimitedHookFractionalRate(t *testing.T) {
+	counting := new(CountingHook)
+	// 1.5 tokens a millisecond
+	hook := NewRateLimitedHook(counting, 1500)
+	defer hook.Stop()
+
+	for i := 0; i < 1500; i++ {
+		hook.Fire(CRITICAL, "message")
+	}
+
+	start := time.Now()
+	time.Sleep(200 * time.Millisecond)
+	for i := 0; i < 1000; i++ {
+		hook.Fire(CRITICAL, "message")
+	}
+	elapsed := time.Since(start)
+
+	refilled := counting.Count() - 1500
+	if refilled < 270 || refilled > int64(1500*elapsed.Seconds())+1 {
+		t.Errorf("bucket refilled wrong. refilled: %d, elapsed: %s", refilled, elapsed)
+	}
+}
+
+func BenchmarkHookDispatch(b *testing.B) {
+	manager := NewHookManager()
+	manager.AddHook("nop", NopHook{})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		fireHooks(manager.snapshot(), INFO, "message")
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// HistogramBuckets is the number of minutes shown by histogram handler
+	HistogramBuckets = 60
+	// HistogramWidth is the width of the longest bar of histogram handler
+	HistogramWidth = 50
+)
+
+// writerConfig is the json form of rotation and level configuration of a
+// file writer
+type writerConfig struct {
+	FileName    string `json:"file_name"`
+	Level       string `json:"level"`
+	TimeRotated bool   `json:"time_rotated"`
+	RotateSize  int64  `json:"rotate_size"`
+	RotateLines int    `json:"rotate_lines"`
+	Retentions  int64  `json:"retentions"`
+	Colored     bool   `json:"colored"`
+}
+
+// RegisterHTTPHandler mounts debugging handlers of the singleton writer on
+// mux, like RegisterHTTPHandler("/debug/blog4go", http.DefaultServeMux)
+//
+//	prefix/status     internal state of every file writer, see DebugInfo
+//	prefix/stats      status of every log file, see FileStats
+//	prefix/config     rotation and level configuration of every file writer
+//	prefix/rotate     POST only, do a logrotate at once, see Rotate
+//	prefix/histogram  plain text bar chart of messages per level per minute
+//	                  in the last hour, see EnableTimeBucketStats
+func RegisterHTTPHandler(prefix string, mux *http.ServeMux) {
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	mux.HandleFunc(prefix+"/status", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, DebugInfo())
+	})
+
+	mux.HandleFunc(prefix+"/stats", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, FileStats())
+	})
+
+	mux.HandleFunc(prefix+"/config", func(w http.ResponseWriter, r *http.Request) {
+		configs := make([]writerConfig, 0)
+		for _, writer := range fileWriters() {
+			configs = append(configs, writerConfig{
+				FileName:    writer.fileName,
+				Level:       writer.Level().String(),
+				TimeRotated: writer.TimeRotated(),
+				RotateSize:  writer.RotateSize(),
+				RotateLines: writer.RotateLines(),
+				Retentions:  writer.Retentions(),
+				Colored:     writer.Colored(),
+			})
+		}
+		writeJSON(w, configs)
+	})
+
+	mux.HandleFunc(prefix+"/rotate", func(w http.ResponseWriter, r *http.Request) {
+		if http.MethodPost != r.Method {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		if err := Rotate(); nil != err {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc(prefix+"/histogram", func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now()
+		from := now.Truncate(time.Minute).Add(-time.Duration(HistogramBuckets-1) * time.Minute)
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		writeHistogram(w, TimeBucketStats(from, now), from, HistogramBuckets)
+	})
+}
+
+// writeHistogram writes a bar chart of counts per level of buckets minutes
+// starting from from, bars are scaled to the max count. Counts of time
+// buckets are added up into the minute they start in.
+func writeHistogram(w io.Writer, stats map[time.Time]map[LevelType]int64, from time.Time, buckets int) {
+	counts := make(map[LevelType][]int64)
+	var max int64
+	for start, bucket := range stats {
+		i := int(start.Sub(from) / time.Minute)
+		if i < 0 || i >= buckets {
+			continue
+		}
+
+		for level, count := range bucket {
+			if _, ok := counts[level]; !ok {
+				counts[level] = make([]int64, buckets)
+			}
+			counts[level][i] += count
+			if counts[level][i] > max {
+				max = counts[level][i]
+			}
+		}
+	}
+
+	if 0 == len(counts) {
+		io.WriteString(w, "no messages counted, time bucket stats may be disabled\n")
+		return
+	}
+
+	levels := make([]LevelType, 0, len(counts))
+	for level := range counts {
+		levels = append(levels, level)
+	}
+	sort.Sort(levelTypes(levels))
+
+	for _, level := range levels {
+		fmt.Fprintf(w, "%s\n", level.String())
+		for i, count := range counts[level] {
+			bar := strings.Repeat("#", int(count*int64(HistogramWidth)/max))
+			fmt.Fprintf(w, "%s |%-*s %d\n", from.Add(time.Duration(i)*time.Minute).Format("15:04"), HistogramWidth, bar, count)
+		}
+	}
+}
+
+// levelTypes implements sort.Interface
+type levelTypes []LevelType
+
+func (l levelTypes) Len() int           { return len(l) }
+func (l levelTypes) Less(i, j int) bool { return l[i] < l[j] }
+func (l levelTypes) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
+
+// writeJSON writes v as json response
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	data, err := json.Marshal(v)
+	if nil != err {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegisterHTTPHandler(t *testing.T) {
+	err := NewBaseFileWriter("/tmp/http.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		Close()
+		os.Remove("/tmp/http.log")
+		os.Remove("/tmp/http.log.1")
+	}()
+
+	SetRetentions(1)
+	SetRotateSize(MB)
+	mux := http.NewServeMux()
+	RegisterHTTPHandler("/debug/blog4go/", mux)
+
+	var configs []writerConfig
+	get(t, mux, "/debug/blog4go/config", &configs)
+	if 1 != len(configs) || "/tmp/http.log" != configs[0].FileName || MB != configs[0].RotateSize || 1 != configs[0].Retentions {
+		t.Errorf("config wrong. configs: %v", configs)
+	}
+
+	var infos []map[string]interface{}
+	get(t, mux, "/debug/blog4go/status", &infos)
+	if 1 != len(infos) || "/tmp/http.log" != infos[0]["file_name"] {
+		t.Errorf("status wrong. infos: %v", infos)
+	}
+
+	var stats []Stats
+	get(t, mux, "/debug/blog4go/stats", &stats)
+	if 1 != len(stats) || "/tmp/http.log" != stats[0].FileName {
+		t.Errorf("stats wrong. stats: %v", stats)
+	}
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/blog4go/rotate", nil))
+	if http.StatusMethodNotAllowed != recorder.Code {
+		t.Errorf("rotate should only accept POST. code: %d", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/debug/blog4go/rotate", nil))
+	if http.StatusNoContent != recorder.Code {
+		t.Errorf("rotate failed. code: %d, body: %s", recorder.Code, recorder.Body.String())
+	}
+	if _, err = os.Stat("/tmp/http.log.1"); nil != err {
+		t.Errorf("log should be rotated. err: %s", err.Error())
+	}
+
+	EnableTimeBucketStats(time.Second)
+	Error("counted")
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/blog4go/histogram", nil))
+	if body := recorder.Body.String(); http.StatusOK != recorder.Code || !strings.HasPrefix(body, "ERROR\n") || !strings.Contains(body, "|"+strings.Repeat("#", HistogramWidth)+" 1\n") {
+		t.Errorf("histogram wrong. code: %d, body: %s", recorder.Code, body)
+	}
+}
+
+func TestWriteHistogram(t *testing.T) {
+	from := time.Date(2017, 6, 30, 12, 0, 0, 0, time.Local)
+	stats := map[time.Time]map[LevelType]int64{
+		from:                       {INFO: 4, ERROR: 1},
+		from.Add(30 * time.Second): {INFO: 4},
+		from.Add(2 * time.Minute):  {INFO: 2},
+		from.Add(3 * time.Minute):  {INFO: 100},
+		from.Add(-time.Minute):     {WARNING: 100},
+	}
+
+	buffer := new(bytes.Buffer)
+	writeHistogram(buffer, stats, from, 3)
+
+	width := HistogramWidth
+	expected := strings.Join([]string{
+		"INFO",
+		fmt.Sprintf("12:00 |%s 8", strings.Repeat("#", width)),
+		fmt.Sprintf("12:01 |%s 0", strings.Repeat(" ", width)),
+		fmt.Sprintf("12:02 |%-*s 2", width, strings.Repeat("#", width/4)),
+		"ERROR",
+		fmt.Sprintf("12:00 |%-*s 1", width, strings.Repeat("#", width/8)),
+		fmt.Sprintf("12:01 |%s 0", strings.Repeat(" ", width)),
+		fmt.Sprintf("12:02 |%s 0", strings.Repeat(" ", width)),
+	}, "\n") + "\n"
+	if expected != buffer.String() {
+		t.Errorf("histogram wrong. histogram:\n%s", buffer.String())
+	}
+
+	buffer.Reset()
+	writeHistogram(buffer, nil, from, 3)
+	if !strings.HasPrefix(buffer.String(), "no messages counted") {
+		t.Errorf("empty histogram wrong. histogram: %s", buffer.String())
+	}
+}
+
+// get serves a GET request of path and decodes the json response into v
+func get(t *testing.T, handler http.Handler, path string, v interface{}) {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	if http.StatusOK != recorder.Code {
+		t.Fatalf("request %s failed. code: %d", path, recorder.Code)
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), v); nil != err {
+		t.Fatalf("decode response of %s failed. err: %s", path, err.Error())
+	}
+}
@@ -0,0 +1,25 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+// +build darwin dragonfly freebsd netbsd openbsd
+
+package blog4go
+
+import (
+	"os"
+	"syscall"
+)
+
+// setAppendOnly makes writes to file always appended with O_APPEND, the
+// file itself can still be truncated or deleted on this platform
+func setAppendOnly(file *os.File) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_FCNTL, file.Fd(), syscall.F_SETFL, syscall.O_APPEND); 0 != errno {
+		return errno
+	}
+	return nil
+}
+
+// clearAppendOnly do nothing, no attribute is set to the file
+func clearAppendOnly(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+//go:build linux && (386 || amd64 || arm || arm64 || loong64 || riscv64 || s390x)
+// +build linux
+// +build 386 amd64 arm arm64 loong64 riscv64 s390x
+
+package blog4go
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	// fsAppendFL is FS_APPEND_FL, the append-only attribute of inode
+	fsAppendFL = 0x20
+)
+
+var (
+	// ioctl requests FS_IOC_GETFLAGS and FS_IOC_SETFLAGS, encoded with
+	// size of long as asm-generic ioctl.h does, which is not the encoding of
+	// mips, ppc or sparc
+	fsIocGetFlags = 0x80006601 | uintptr(unsafe.Sizeof(uintptr(0)))<<16
+	fsIocSetFlags = 0x40006602 | uintptr(unsafe.Sizeof(uintptr(0)))<<16
+)
+
+// setAppendOnly sets the append-only attribute of file, the same as
+// chattr +a, which requires CAP_LINUX_IMMUTABLE
+func setAppendOnly(file *os.File) error {
+	return changeInodeFlags(file, fsAppendFL, 0)
+}
+
+// clearAppendOnly clears the append-only attribute of file, the same as
+// chattr -a, which requires CAP_LINUX_IMMUTABLE
+func clearAppendOnly(file *os.File) error {
+	return changeInodeFlags(file, 0, fsAppendFL)
+}
+
+// changeInodeFlags sets and clears inode flags of file
+func changeInodeFlags(file *os.File, set int32, clear int32) error {
+	var flags int32
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, file.Fd(), fsIocGetFlags, uintptr(unsafe.Pointer(&flags))); 0 != errno {
+		return errno
+	}
+
+	flags = flags&^clear | set
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, file.Fd(), fsIocSetFlags, uintptr(unsafe.Pointer(&flags))); 0 != errno {
+		return errno
+	}
+	return nil
+}
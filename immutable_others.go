@@ -0,0 +1,25 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+//go:build !darwin && !dragonfly && !freebsd && !netbsd && !openbsd && (!linux || !(386 || amd64 || arm || arm64 || loong64 || riscv64 || s390x))
+// +build !darwin
+// +build !dragonfly
+// +build !freebsd
+// +build !netbsd
+// +build !openbsd
+// +build !linux !386,!amd64,!arm,!arm64,!loong64,!riscv64,!s390x
+
+package blog4go
+
+import (
+	"os"
+)
+
+// setAppendOnly is not supported on this platform
+func setAppendOnly(file *os.File) error {
+	return ErrNotSupported
+}
+
+// clearAppendOnly is not supported on this platform
+func clearAppendOnly(file *os.File) error {
+	return ErrNotSupported
+}
@@ -0,0 +1,224 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+const (
+	// IndexIntervalLines is the max number of lines between index points
+	IndexIntervalLines = 1000
+	// IndexIntervalBytes is the max number of bytes between index points
+	IndexIntervalBytes = 1024 * 1024
+
+	// indexMagic is the header of index file
+	indexMagic = "B4GI"
+)
+
+var (
+	// ErrInvalidIndex show that the index file is broken or not for the log
+	ErrInvalidIndex = errors.New("Invalid index")
+)
+
+// indexPoint is a record in index file, a big endian int64 byte offset of
+// an entry in the log file followed by big endian int64 unix time of the entry
+type indexPoint struct {
+	Offset int64
+	Time   int64
+}
+
+// BuildIndex reads the log file and writes index to indexPath. An index point
+// is recorded for the first entry after every IndexIntervalLines lines or
+// IndexIntervalBytes bytes, so that SearchByTime can seek near to the entries
+// wanted instead of scanning from the beginning.
+func BuildIndex(logPath string, indexPath string) error {
+	log, err := os.Open(logPath)
+	if nil != err {
+		return err
+	}
+	defer log.Close()
+
+	index, err := os.Create(indexPath)
+	if nil != err {
+		return err
+	}
+	defer index.Close()
+
+	out := bufio.NewWriter(index)
+	out.WriteString(indexMagic)
+
+	reader := bufio.NewReader(log)
+	var offset, last int64
+	var lines int
+	var first = true
+	for {
+		line, err := reader.ReadBytes(EOL)
+		if 0 != len(line) {
+			if t, ok := lineTime(line); ok && (first || lines >= IndexIntervalLines || offset-last >= IndexIntervalBytes) {
+				if err := binary.Write(out, binary.BigEndian, indexPoint{Offset: offset, Time: t.Unix()}); nil != err {
+					return err
+				}
+				first = false
+				last = offset
+				lines = 0
+			}
+			offset += int64(len(line))
+			lines++
+		}
+
+		if io.EOF == err {
+			break
+		} else if nil != err {
+			return err
+		}
+	}
+
+	return out.Flush()
+}
+
+// readIndex reads index points in indexPath
+func readIndex(indexPath string) (points []indexPoint, err error) {
+	data, err := ioutil.ReadFile(indexPath)
+	if nil != err {
+		return nil, err
+	}
+
+	if !bytes.HasPrefix(data, []byte(indexMagic)) || 0 != (len(data)-len(indexMagic))%binary.Size(indexPoint{}) {
+		return nil, ErrInvalidIndex
+	}
+
+	points = make([]indexPoint, (len(data)-len(indexMagic))/binary.Size(indexPoint{}))
+	if err = binary.Read(bytes.NewReader(data[len(indexMagic):]), binary.BigEndian, points); nil != err {
+		return nil, err
+	}
+	return points, nil
+}
+
+// SearchByTime return lines of entries written between from and to inclusive
+// in the log file, using index built by BuildIndex to seek to the last index
+// point before from. Continuation lines, like stack traces, are returned
+// with their entries. Entries are supposed to be written in chronological
+// order, reading stops at the first entry after to.
+func SearchByTime(logPath, indexPath string, from, to time.Time) (io.ReadCloser, error) {
+	points, err := readIndex(indexPath)
+	if nil != err {
+		return nil, err
+	}
+
+	log, err := os.Open(logPath)
+	if nil != err {
+		return nil, err
+	}
+
+	info, err := log.Stat()
+	if nil != err {
+		log.Close()
+		return nil, err
+	}
+
+	var offset int64
+	for _, point := range points {
+		if point.Offset > info.Size() {
+			log.Close()
+			return nil, ErrInvalidIndex
+		}
+
+		// timestamps are in seconds, entries in the same second as from may
+		// be written before the point
+		if !time.Unix(point.Time, 0).Before(from.Truncate(time.Second)) {
+			break
+		}
+		offset = point.Offset
+	}
+
+	if _, err = log.Seek(offset, io.SeekStart); nil != err {
+		log.Close()
+		return nil, err
+	}
+
+	return &timeRangeReader{file: log, reader: bufio.NewReader(log), from: from.Truncate(time.Second), to: to}, nil
+}
+
+// timeRangeReader reads lines of entries written between from and to
+type timeRangeReader struct {
+	file   *os.File
+	reader *bufio.Reader
+
+	from time.Time
+	to   time.Time
+
+	// the current entry is between from and to
+	inRange bool
+	// lines read but not returned yet
+	pending []byte
+	// reading stopped
+	done bool
+}
+
+// Read reads lines of entries wanted
+func (r *timeRangeReader) Read(p []byte) (n int, err error) {
+	for 0 == len(r.pending) {
+		if r.done {
+			return 0, io.EOF
+		}
+
+		line, err := r.reader.ReadBytes(EOL)
+		if 0 != len(line) {
+			if t, ok := lineTime(line); ok {
+				if t.After(r.to) {
+					r.done = true
+					return 0, io.EOF
+				}
+				r.inRange = !t.Before(r.from)
+			}
+
+			if r.inRange {
+				r.pending = line
+			}
+		}
+
+		if io.EOF == err {
+			r.done = true
+		} else if nil != err {
+			return 0, err
+		}
+	}
+
+	n = copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// Close closes the log file
+func (r *timeRangeReader) Close() error {
+	return r.file.Close()
+}
+
+// lineTime return timestamp of a line written by blog4go, false if the line
+// does not start with a timestamp
+func lineTime(line []byte) (time.Time, bool) {
+	// color of level ahead if full line colored
+	n := len(line)
+	if n > maxColorSize {
+		n = maxColorSize
+	}
+	line = line[len(leadingColor(string(line[:n]))):]
+
+	if len(line) < len(PrefixTimeFormat) {
+		return time.Time{}, false
+	}
+
+	t, err := time.ParseInLocation(PrefixTimeFormat, string(line[:len(PrefixTimeFormat)]), time.Local)
+	if nil != err {
+		return time.Time{}, false
+	}
+	return t, true
+}
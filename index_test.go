@@ -0,0 +1,68 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSearchByTime(t *testing.T) {
+	defer func() {
+		os.Remove("/tmp/index.log")
+		os.Remove("/tmp/index.log.idx")
+	}()
+
+	// one entry per second with a continuation line every 100 entries
+	begin := time.Date(2017, 6, 30, 12, 0, 0, 0, time.Local)
+	var buffer bytes.Buffer
+	for i := 0; i < 3000; i++ {
+		fmt.Fprintf(&buffer, "%s [INFO] message %d\n", begin.Add(time.Duration(i)*time.Second).Format(PrefixTimeFormat), i)
+		if 0 == i%100 {
+			fmt.Fprintf(&buffer, "continuation %d\n", i)
+		}
+	}
+	ioutil.WriteFile("/tmp/index.log", buffer.Bytes(), 0644)
+
+	if err := BuildIndex("/tmp/index.log", "/tmp/index.log.idx"); nil != err {
+		t.Fatalf("build index failed. err: %s", err.Error())
+	}
+
+	points, err := readIndex("/tmp/index.log.idx")
+	if nil != err || 4 != len(points) || 0 != points[0].Offset || begin.Unix() != points[0].Time {
+		t.Fatalf("index built wrong. points: %v, err: %v", points, err)
+	}
+
+	r, err := SearchByTime("/tmp/index.log", "/tmp/index.log.idx", begin.Add(1500*time.Second), begin.Add(1600*time.Second))
+	if nil != err {
+		t.Fatalf("search by time failed. err: %s", err.Error())
+	}
+	data, _ := ioutil.ReadAll(r)
+	r.Close()
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if 103 != len(lines) || !strings.HasSuffix(lines[0], "] message 1500") || "continuation 1500" != lines[1] ||
+		"continuation 1600" != lines[len(lines)-1] {
+		t.Errorf("search by time return wrong lines. count: %d, first: %s, last: %s", len(lines), lines[0], lines[len(lines)-1])
+	}
+
+	// range out of the log
+	r, err = SearchByTime("/tmp/index.log", "/tmp/index.log.idx", begin.Add(time.Hour), begin.Add(2*time.Hour))
+	if nil != err {
+		t.Fatalf("search by time failed. err: %s", err.Error())
+	}
+	if data, _ = ioutil.ReadAll(r); 0 != len(data) {
+		t.Errorf("search out of range should return nothing. data: %s", data)
+	}
+	r.Close()
+
+	ioutil.WriteFile("/tmp/index.log.idx", []byte("broken"), 0644)
+	if _, err = SearchByTime("/tmp/index.log", "/tmp/index.log.idx", begin, begin); ErrInvalidIndex != err {
+		t.Error("broken index should fail")
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+var (
+	// MaxScanLineLength is the max length of a line without EOL, longer ones
+	// are reported as corrupt by ScanIntegrity
+	MaxScanLineLength = 64 * 1024
+)
+
+// CorruptionEvent describes a line reported by ScanIntegrity
+type CorruptionEvent struct {
+	// line number, starts from 1
+	Line        int
+	Description string
+}
+
+// IntegrityReport is the result of ScanIntegrity
+type IntegrityReport struct {
+	LinesOK         int
+	LinesCorrupt    int
+	LinesOutOfOrder int
+	Errors          []CorruptionEvent
+}
+
+// ScanIntegrity scans every line in logPath, detects lines not starting with
+// a timestamp in PrefixTimeFormat, lines longer than MaxScanLineLength, the
+// last line truncated without EOL, and lines with timestamp earlier than
+// the previous one. Schema header lines are skipped. Continuation lines of
+// multi-line messages have no timestamp, so they are reported as corrupt as
+// well.
+func ScanIntegrity(logPath string) (*IntegrityReport, error) {
+	file, err := os.Open(logPath)
+	if nil != err {
+		return nil, err
+	}
+	defer file.Close()
+
+	report := new(IntegrityReport)
+	reader := bufio.NewReader(file)
+
+	var last time.Time
+	for line := 1; ; line++ {
+		content, err := reader.ReadBytes(EOL)
+		if io.EOF == err && 0 == len(content) {
+			break
+		} else if nil != err && io.EOF != err {
+			return nil, err
+		}
+
+		if io.EOF == err {
+			report.corrupt(line, "truncated without EOL")
+			break
+		}
+
+		content = bytes.TrimSuffix(bytes.TrimSuffix(content, []byte{EOL}), []byte{'\r'})
+		if len(content) > MaxScanLineLength {
+			report.corrupt(line, fmt.Sprintf("length %d exceeds %d", len(content), MaxScanLineLength))
+			continue
+		}
+
+		// header line written by SetSchemaVersion
+		if _, ok := parseSchemaHeader(string(content)); ok {
+			continue
+		}
+
+		t, ok := lineTime(content)
+		if !ok {
+			report.corrupt(line, "timestamp not found")
+			continue
+		}
+
+		if t.Before(last) {
+			report.LinesOutOfOrder++
+			report.Errors = append(report.Errors, CorruptionEvent{Line: line, Description: fmt.Sprintf("timestamp %s earlier than %s", t.Format(PrefixTimeFormat), last.Format(PrefixTimeFormat))})
+			continue
+		}
+
+		last = t
+		report.LinesOK++
+	}
+	return report, nil
+}
+
+// corrupt records a corrupt line
+func (report *IntegrityReport) corrupt(line int, description string) {
+	report.LinesCorrupt++
+	report.Errors = append(report.Errors, CorruptionEvent{Line: line, Description: description})
+}
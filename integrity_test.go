@@ -0,0 +1,53 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestScanIntegrity(t *testing.T) {
+	if _, err := ScanIntegrity("/tmp/not_exists.log"); nil == err {
+		t.Error("scan not existed file should fail")
+	}
+
+	content := "[2017/06/30:12:00:00] [INFO] first\n" +
+		"[2017/06/30:12:00:02] [INFO] second\r\n" +
+		"corrupt\n" +
+		"[2017/06/30:12:00:01] [INFO] out of order\n" +
+		"[2017/06/30:12:00:02] [INFO] " + strings.Repeat("x", 100) + "\n" +
+		"[2017/06/30:12:00:03] [INFO] third\n" +
+		"[2017/06/30:12:00:04] [INFO] trunc"
+	if err := ioutil.WriteFile("/tmp/integrity.log", []byte(content), 0644); nil != err {
+		t.Fatalf("write log failed. err: %s", err.Error())
+	}
+	defer os.Remove("/tmp/integrity.log")
+
+	maxLength := MaxScanLineLength
+	MaxScanLineLength = 100
+	defer func() {
+		MaxScanLineLength = maxLength
+	}()
+
+	report, err := ScanIntegrity("/tmp/integrity.log")
+	if nil != err {
+		t.Fatalf("scan integrity failed. err: %s", err.Error())
+	}
+
+	if 3 != report.LinesOK || 3 != report.LinesCorrupt || 1 != report.LinesOutOfOrder {
+		t.Errorf("report wrong. report: %+v", report)
+	}
+
+	lines := []int{3, 4, 5, 7}
+	if len(lines) != len(report.Errors) {
+		t.Fatalf("errors wrong. errors: %+v", report.Errors)
+	}
+	for i, event := range report.Errors {
+		if lines[i] != event.Line || "" == event.Description {
+			t.Errorf("corruption event wrong. event: %+v", event)
+		}
+	}
+}
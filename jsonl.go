@@ -0,0 +1,86 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// jsonEntry is the json form of Entry, like
+// {"time":"2017-06-30T12:00:00+08:00","level":"INFO","message":"hello"}
+type jsonEntry struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+}
+
+// newJSONEntry convert entry to the json form
+func newJSONEntry(entry *Entry) jsonEntry {
+	return jsonEntry{entry.Time, entry.Level.String(), entry.Message}
+}
+
+// entry convert the json form back to Entry, level is invalid if unknown
+func (e jsonEntry) entry() *Entry {
+	return &Entry{Time: e.Time, Level: LevelFromString(e.Level), Message: e.Message}
+}
+
+// JSONLReader decodes stream of json lines, one entry in json form every
+// line, into entries
+type JSONLReader struct {
+	decoder *json.Decoder
+}
+
+// NewJSONLReader create a JSONLReader reading json lines from r
+func NewJSONLReader(r io.Reader) *JSONLReader {
+	reader := new(JSONLReader)
+	reader.decoder = json.NewDecoder(r)
+	return reader
+}
+
+// Next return the next entry in the stream.
+// io.EOF will be returned at the end of the stream.
+func (reader *JSONLReader) Next() (*Entry, error) {
+	entry, _, err := reader.next()
+	return entry, err
+}
+
+// next return the next entry and the raw json line of it
+func (reader *JSONLReader) next() (*Entry, json.RawMessage, error) {
+	var raw json.RawMessage
+	if err := reader.decoder.Decode(&raw); nil != err {
+		return nil, nil, err
+	}
+
+	var e jsonEntry
+	if err := json.Unmarshal(raw, &e); nil != err {
+		return nil, nil, err
+	}
+	return e.entry(), raw, nil
+}
+
+// FilterJSONL reads json lines from in, writes lines of entries matching f
+// to out. nil f matches every entry.
+func FilterJSONL(in io.Reader, out io.Writer, f Filter) error {
+	reader := NewJSONLReader(in)
+	for {
+		entry, raw, err := reader.next()
+		if io.EOF == err {
+			return nil
+		} else if nil != err {
+			return err
+		}
+
+		if nil != f && !f.Match(entry) {
+			continue
+		}
+
+		if _, err = out.Write(raw); nil != err {
+			return err
+		}
+		if _, err = out.Write(EOLUnix); nil != err {
+			return err
+		}
+	}
+}
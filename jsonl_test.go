@@ -0,0 +1,67 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestJSONLReader(t *testing.T) {
+	stream := `{"time":"2017-06-30T12:00:00Z","level":"INFO","message":"first"}
+{"time":"2017-06-30T12:00:01Z","level":"ERROR","message":"db timeout"}
+{"time":"2017-06-30T12:00:02Z","level":"UNKNOWN","message":"last"}
+`
+
+	reader := NewJSONLReader(strings.NewReader(stream))
+	entry, err := reader.Next()
+	if nil != err || INFO != entry.Level || "first" != entry.Message || 2017 != entry.Time.Year() {
+		t.Errorf("first entry decoded wrong. entry: %v, err: %v", entry, err)
+	}
+
+	entry, err = reader.Next()
+	if nil != err || ERROR != entry.Level || "db timeout" != entry.Message {
+		t.Errorf("second entry decoded wrong. entry: %v, err: %v", entry, err)
+	}
+
+	entry, err = reader.Next()
+	if nil != err || entry.Level.valid() {
+		t.Errorf("entry with unknown level decoded wrong. entry: %v, err: %v", entry, err)
+	}
+
+	if _, err = reader.Next(); io.EOF != err {
+		t.Error("reader should return io.EOF at the end of stream")
+	}
+
+	if _, err = NewJSONLReader(strings.NewReader("not json\n")).Next(); nil == err {
+		t.Error("invalid json should fail")
+	}
+}
+
+func TestFilterJSONL(t *testing.T) {
+	stream := `{"time":"2017-06-30T12:00:00Z","level":"INFO","message":"db connected"}
+{"time":"2017-06-30T12:00:01Z","level":"ERROR","message":"db timeout"}
+{"time":"2017-06-30T12:00:02Z","level":"ERROR","message":"cache timeout"}
+`
+
+	filter, err := ParseQuery(`level >= ERROR AND msg CONTAINS "db"`)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	out := new(bytes.Buffer)
+	if err = FilterJSONL(strings.NewReader(stream), out, filter); nil != err {
+		t.Fatalf("filter json lines failed. err: %s", err.Error())
+	}
+
+	if expected := `{"time":"2017-06-30T12:00:01Z","level":"ERROR","message":"db timeout"}` + "\n"; expected != out.String() {
+		t.Errorf("json lines filtered wrong. out: %s", out.String())
+	}
+
+	out.Reset()
+	if err = FilterJSONL(strings.NewReader(stream), out, nil); nil != err || 3 != strings.Count(out.String(), "\n") {
+		t.Errorf("nil filter should match every entry. out: %s", out.String())
+	}
+}
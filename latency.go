@@ -0,0 +1,91 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// LatencySamples is the number of the latest write latency samples kept
+	// by latency tracking
+	LatencySamples = 10000
+)
+
+// latencyTracker keeps the latest samples of write latency in a ring
+type latencyTracker struct {
+	// circular buffer of samples
+	samples []time.Duration
+	// position of the next sample
+	next int
+	// number of samples kept
+	n int
+
+	lock *sync.Mutex
+}
+
+// newLatencyTracker create a latencyTracker keeping size samples
+func newLatencyTracker(size int) *latencyTracker {
+	if size < 1 {
+		size = 1
+	}
+
+	tracker := new(latencyTracker)
+	tracker.samples = make([]time.Duration, size)
+	tracker.lock = new(sync.Mutex)
+	return tracker
+}
+
+// record add a sample, the oldest one is overwritten if full
+func (tracker *latencyTracker) record(d time.Duration) {
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+
+	tracker.samples[tracker.next] = d
+	tracker.next = (tracker.next + 1) % len(tracker.samples)
+	if tracker.n < len(tracker.samples) {
+		tracker.n++
+	}
+}
+
+// percentile return the sample at percentile p of samples kept, p is
+// between 0 and 100, like 99.9. 0 is returned if no sample kept.
+func (tracker *latencyTracker) percentile(p float64) time.Duration {
+	tracker.lock.Lock()
+	sorted := make([]time.Duration, tracker.n)
+	copy(sorted, tracker.samples[:tracker.n])
+	tracker.lock.Unlock()
+
+	if 0 == len(sorted) {
+		return 0
+	}
+	sort.Sort(durations(sorted))
+
+	if p <= 0 {
+		return sorted[0]
+	}
+	// nearest rank
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+// reset drops all samples
+func (tracker *latencyTracker) reset() {
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+	tracker.next = 0
+	tracker.n = 0
+}
+
+// durations implements sort.Interface
+type durations []time.Duration
+
+func (d durations) Len() int           { return len(d) }
+func (d durations) Less(i, j int) bool { return d[i] < d[j] }
+func (d durations) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
@@ -0,0 +1,69 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLatencyTracker(t *testing.T) {
+	tracker := newLatencyTracker(100)
+	if 0 != tracker.percentile(99) {
+		t.Error("percentile should be 0 without samples")
+	}
+
+	// 1ms ... 200ms, only the latest 100 are kept
+	for i := 200; i > 0; i-- {
+		tracker.record(time.Duration(i) * time.Millisecond)
+	}
+	if p := tracker.percentile(99); 99*time.Millisecond != p {
+		t.Errorf("p99 wrong. p99: %s", p)
+	}
+	if p := tracker.percentile(99.9); 100*time.Millisecond != p {
+		t.Errorf("p999 wrong. p999: %s", p)
+	}
+	if p := tracker.percentile(0); time.Millisecond != p {
+		t.Errorf("p0 wrong. p0: %s", p)
+	}
+
+	tracker.reset()
+	if 0 != tracker.percentile(50) {
+		t.Error("samples should be dropped after reset")
+	}
+}
+
+func TestBaseFileWriterLatencyTracking(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/latency.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/latency.log")
+	}()
+
+	writer.Info("untracked")
+	if 0 != writer.Percentile(99) {
+		t.Error("latency should not be tracked by default")
+	}
+
+	writer.SetLatencyTracking(true)
+	writer.Info("tracked")
+	writer.Infof("tracked %d", 2)
+	if 0 == writer.Percentile(100) {
+		t.Error("latency should be tracked")
+	}
+
+	writer.ResetLatency()
+	if 0 != writer.Percentile(100) {
+		t.Error("samples should be dropped after reset")
+	}
+
+	writer.SetLatencyTracking(false)
+	writer.Info("untracked")
+	if 0 != writer.Percentile(100) {
+		t.Error("latency should not be tracked after disabled")
+	}
+}
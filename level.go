@@ -4,7 +4,10 @@ package blog4go
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 // LevelType type defined for logging level
@@ -36,6 +39,9 @@ const (
 	PrefixFormat = " [%s] " // pure format
 	// ColoredPrefixFormat is the colored level format adhead every message
 	ColoredPrefixFormat = " [\x1b[%dm%s\x1b[0m] " // colored format
+	// coloredCodePrefixFormat is the colored level format of levels
+	// registered, with color code
+	coloredCodePrefixFormat = " [\x1b[%sm%s\x1b[0m] "
 
 	// color enum used in formating color bytes
 
@@ -51,72 +57,204 @@ const (
 	BLUE = 34
 	// GRAY gray color
 	GRAY = 37
+
+	// colorReset resets color
+	colorReset = "\x1b[0m"
 )
 
 var (
 	// LevelStrings is string present for each level
 	LevelStrings = [...]string{"TRACE", "DEBUG", "INFO", "WARN", "ERROR", "CRITICAL"}
 
-	// StringLevels is map, level strings to levels
-	StringLevels = map[string]LevelType{"TRACE": TRACE, "DEBUG": DEBUG, "INFO": INFO, "WARN": WARNING, "ERROR": ERROR, "CRITICAL": CRITICAL}
+	// StringLevels is map, level strings to levels. It is replaced as a
+	// whole when levels registered, do not modify it
+	StringLevels map[string]LevelType
 
 	// Levels is a slice consist of all levels
 	Levels = [...]LevelType{TRACE, DEBUG, INFO, WARNING, ERROR, CRITICAL}
 
 	// Prefix is preformatted level prefix string
-	// help reduce string formatted burden in realtime logging
-	Prefix = make(map[LevelType]string)
+	// help reduce string formatted burden in realtime logging.
+	// It is replaced as a whole when levels registered or prefix colored,
+	// do not modify it
+	Prefix map[LevelType]string
+
+	// levelTables holds the current *levelTable, loaded without lock for
+	// every message
+	levelTables = new(atomic.Value)
+	// levelLock serializes replacing of the level table
+	levelLock = new(sync.Mutex)
 )
 
+// levelTable is a snapshot of levels registered and prefixes preformatted,
+// never modified after stored, copied on changes instead
+type levelTable struct {
+	// names is levels registered by RegisterLevel to levels' strings
+	names map[LevelType]string
+	// colors is levels registered by RegisterLevel to color codes
+	colors map[LevelType]string
+
+	// levels is level strings to levels, built-in levels included
+	levels map[string]LevelType
+	// prefixes is preformatted level prefix string of every level
+	prefixes map[LevelType]string
+	// whether prefixes are preformatted in colored format
+	colored bool
+}
+
 func init() {
-	initPrefix(false) // preformat level prefix string
+	storeLevels(newLevelTable(nil, nil, false)) // preformat level prefix string
+}
+
+// newLevelTable return a level table of levels registered, with prefixes
+// preformatted in colored format if colored is true
+func newLevelTable(names map[LevelType]string, colors map[LevelType]string, colored bool) *levelTable {
+	table := &levelTable{
+		names:    make(map[LevelType]string),
+		colors:   make(map[LevelType]string),
+		levels:   make(map[string]LevelType),
+		prefixes: make(map[LevelType]string),
+		colored:  colored,
+	}
+
+	for _, level := range Levels {
+		table.levels[level.String()] = level
+		if colored {
+			table.prefixes[level] = fmt.Sprintf(coloredCodePrefixFormat, level.colorCode(), level.String())
+		} else {
+			table.prefixes[level] = fmt.Sprintf(PrefixFormat, level.String())
+		}
+	}
+
+	for level, str := range names {
+		table.names[level] = str
+		table.colors[level] = colors[level]
+		table.levels[str] = level
+		if colored {
+			table.prefixes[level] = fmt.Sprintf(coloredCodePrefixFormat, colors[level], str)
+		} else {
+			table.prefixes[level] = fmt.Sprintf(PrefixFormat, str)
+		}
+	}
+	return table
+}
+
+// loadLevels return the current level table
+func loadLevels() *levelTable {
+	return levelTables.Load().(*levelTable)
+}
+
+// storeLevels replaces the current level table with table, levelLock must be
+// held except in init
+func storeLevels(table *levelTable) {
+	levelTables.Store(table)
+	StringLevels = table.levels
+	Prefix = table.prefixes
 }
 
 // initPrefix is designed to preformat level prefix string for each level.
 // colored decide whether preformat in colored format or not.
 // if colored is true, preformat level prefix string in colored format
 func initPrefix(colored bool) {
-	if colored {
-		Prefix[TRACE] = fmt.Sprintf(ColoredPrefixFormat, GRAY, TRACE.String())
-		Prefix[DEBUG] = fmt.Sprintf(ColoredPrefixFormat, GREEN, DEBUG.String())
-		Prefix[INFO] = fmt.Sprintf(ColoredPrefixFormat, BLUE, INFO.String())
-		Prefix[WARNING] = fmt.Sprintf(ColoredPrefixFormat, YELLOW, WARNING.String())
-		Prefix[ERROR] = fmt.Sprintf(ColoredPrefixFormat, RED, ERROR.String())
-		Prefix[CRITICAL] = fmt.Sprintf(ColoredPrefixFormat, RED, CRITICAL.String())
-	} else {
-		Prefix[TRACE] = fmt.Sprintf(PrefixFormat, TRACE.String())
-		Prefix[DEBUG] = fmt.Sprintf(PrefixFormat, DEBUG.String())
-		Prefix[INFO] = fmt.Sprintf(PrefixFormat, INFO.String())
-		Prefix[WARNING] = fmt.Sprintf(PrefixFormat, WARNING.String())
-		Prefix[ERROR] = fmt.Sprintf(PrefixFormat, ERROR.String())
-		Prefix[CRITICAL] = fmt.Sprintf(PrefixFormat, CRITICAL.String())
+	levelLock.Lock()
+	defer levelLock.Unlock()
+
+	table := loadLevels()
+	storeLevels(newLevelTable(table.names, table.colors, colored))
+}
+
+// RegisterLevel registers a user defined level with name, value and color
+// code of SGR, like "35" or "1;35", and returns it. Levels are compared by
+// value. Built-in levels keep values from 0 (TRACE) to 5 (CRITICAL), so
+// levels registered take their own range around them, like VERBOSE with
+// value -10 ranks below TRACE and AUDIT with value 10 ranks above CRITICAL.
+// value must not be taken by any built-in or registered level, also -1 is
+// reserved for invalid level. LevelType(-1) will be returned when value or
+// name is taken.
+func RegisterLevel(name string, value int, colorCode string) LevelType {
+	level := LevelType(value)
+	name = strings.ToUpper(name)
+
+	levelLock.Lock()
+	defer levelLock.Unlock()
+
+	table := loadLevels()
+	_, nameTaken := table.levels[name]
+	if -1 == value || level.builtin() || nameTaken {
+		return LevelType(-1)
+	}
+	if _, ok := table.names[level]; ok {
+		return LevelType(-1)
 	}
+
+	names := map[LevelType]string{level: name}
+	colors := map[LevelType]string{level: colorCode}
+	for registered, str := range table.names {
+		names[registered] = str
+		colors[registered] = table.colors[registered]
+	}
+	storeLevels(newLevelTable(names, colors, table.colored))
+	return level
+}
+
+// builtin determines whether level is a built-in level
+func (level LevelType) builtin() bool {
+	return TRACE <= level && CRITICAL >= level
 }
 
 // valid determines whether a Level instance is valid or not
 func (level LevelType) valid() bool {
-	if TRACE > level || CRITICAL < level {
-		return false
+	if level.builtin() {
+		return true
 	}
-	return true
+
+	_, ok := loadLevels().names[level]
+	return ok
 }
 
 // String return string format associate with a Level instance
 func (level LevelType) String() string {
-	if !level.valid() {
-		return UNKNOWN
+	if level.builtin() {
+		return LevelStrings[level]
 	}
-	return LevelStrings[level]
+
+	if str, ok := loadLevels().names[level]; ok {
+		return str
+	}
+	return UNKNOWN
+}
+
+// colorCode return color code of level
+func (level LevelType) colorCode() string {
+	switch level {
+	case TRACE:
+		return strconv.Itoa(GRAY)
+	case DEBUG:
+		return strconv.Itoa(GREEN)
+	case INFO:
+		return strconv.Itoa(BLUE)
+	case WARNING:
+		return strconv.Itoa(YELLOW)
+	case ERROR, CRITICAL:
+		return strconv.Itoa(RED)
+	}
+	return loadLevels().colors[level]
+}
+
+// levelsColored determines whether level prefix is preformatted in colored
+// format
+func levelsColored() bool {
+	return loadLevels().colored
 }
 
 // prefix return formatted prefix string associate with a Level instance
 func (level LevelType) prefix() string {
-	return Prefix[level]
+	return loadLevels().prefixes[level]
 }
 
 // LevelFromString return Level according to given string
 func LevelFromString(str string) LevelType {
-	level, ok := StringLevels[strings.ToUpper(str)]
+	level, ok := loadLevels().levels[strings.ToUpper(str)]
 	if !ok {
 		return LevelType(-1)
 	}
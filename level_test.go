@@ -50,6 +50,7 @@ func TestLevelStringFormat(t *testing.T) {
 	}
 
 	initPrefix(true)
+	defer initPrefix(false)
 
 	if " [\x1b[37mTRACE\x1b[0m] " != TRACE.prefix() {
 		t.Error("TRACE Level with color to wrong prefix string format.")
@@ -92,3 +93,79 @@ func TestStringToLevel(t *testing.T) {
 		t.Error("Empty string to level invalid.")
 	}
 }
+
+func TestRegisterLevel(t *testing.T) {
+	table := loadLevels()
+	audit := RegisterLevel("audit", 10, "32")
+	verbose := RegisterLevel("verbose", -10, "37")
+	defer func() {
+		levelLock.Lock()
+		storeLevels(table)
+		levelLock.Unlock()
+	}()
+
+	if !audit.valid() || "AUDIT" != audit.String() {
+		t.Errorf("registered level invalid. level: %d", audit)
+	}
+
+	if audit != LevelFromString("Audit") {
+		t.Error("registered level should be parsed from string")
+	}
+
+	if !(CRITICAL < audit) || !(verbose < TRACE) || "VERBOSE" != verbose.String() {
+		t.Error("registered level should be compared by value")
+	}
+
+	// built-in levels keep their values
+	if 0 != TRACE || 2 != INFO || 5 != CRITICAL {
+		t.Error("built-in levels should not be changed")
+	}
+
+	initPrefix(false)
+	if " [AUDIT] " != audit.prefix() {
+		t.Errorf("registered level to wrong prefix string format. prefix: %s", audit.prefix())
+	}
+
+	initPrefix(true)
+	if " [\x1b[32mAUDIT\x1b[0m] " != audit.prefix() {
+		t.Errorf("registered level with color to wrong prefix string format. prefix: %s", audit.prefix())
+	}
+	initPrefix(false)
+
+	// value or name taken
+	if RegisterLevel("verbose", int(INFO), "37").valid() {
+		t.Error("level with value taken should not be registered")
+	}
+	if RegisterLevel("audit", 11, "37").valid() {
+		t.Error("level with name taken should not be registered")
+	}
+}
+
+func TestLogWithLevel(t *testing.T) {
+	table := loadLevels()
+	audit := RegisterLevel("audit", 10, "35")
+	singleton := blog
+	defer func() {
+		blog = singleton
+		levelLock.Lock()
+		storeLevels(table)
+		levelLock.Unlock()
+	}()
+
+	writer, err := newRingBufferWriter(10)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	writer.SetLevel(INFO)
+	blog = writer
+
+	Log(audit, "login ", "tom")
+	Logf(audit, "logout %s", "tom")
+	Log(DEBUG, "below logging level")
+	Log(LevelType(-1), "invalid level")
+
+	entries := writer.Entries()
+	if 2 != len(entries) || audit != entries[0].Level || "login tom" != entries[0].Message || "logout tom" != entries[1].Message {
+		t.Errorf("log with level wrong. entries: %v", entries)
+	}
+}
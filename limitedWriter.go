@@ -0,0 +1,198 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// LimitedWriter wraps a writer, messages are dropped silently once total
+// bytes of messages written reach the budget. Prefix and EOL added by the
+// writer wrapped are not counted.
+type LimitedWriter struct {
+	writerWrapper
+
+	maxBytes int64
+	// bytes of messages written and dropped since the last Reset
+	used int64
+	// 1 if onLimitReached is called since the last Reset
+	reached int32
+
+	// called once when the budget is reached
+	onLimitReached func()
+
+	lock *sync.RWMutex
+}
+
+// NewLimitedWriter create a LimitedWriter wrapping writer with a budget of
+// maxBytes
+func NewLimitedWriter(writer Writer, maxBytes int64) *LimitedWriter {
+	limited := new(LimitedWriter)
+	limited.Writer = writer
+	limited.self = limited
+	limited.maxBytes = maxBytes
+	limited.lock = new(sync.RWMutex)
+	return limited
+}
+
+// SetOnLimitReached set fn called once when the budget is reached, it is
+// called again after Reset
+func (limited *LimitedWriter) SetOnLimitReached(fn func()) {
+	limited.lock.Lock()
+	defer limited.lock.Unlock()
+	limited.onLimitReached = fn
+}
+
+// BytesRemaining get bytes of messages can be written before the budget is
+// reached
+func (limited *LimitedWriter) BytesRemaining() int64 {
+	if remaining := limited.maxBytes - atomic.LoadInt64(&limited.used); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// Reset resets bytes counted, messages are written again
+func (limited *LimitedWriter) Reset() {
+	atomic.StoreInt64(&limited.used, 0)
+	atomic.StoreInt32(&limited.reached, 0)
+}
+
+// allow counts message, false is returned if the budget is reached
+func (limited *LimitedWriter) allow(message string) bool {
+	if atomic.AddInt64(&limited.used, int64(len(message))) <= limited.maxBytes {
+		return true
+	}
+
+	if atomic.CompareAndSwapInt32(&limited.reached, 0, 1) {
+		limited.lock.RLock()
+		fn := limited.onLimitReached
+		limited.lock.RUnlock()
+		if nil != fn {
+			fn()
+		}
+	}
+	return false
+}
+
+func (limited *LimitedWriter) write(level LevelType, args ...interface{}) {
+	if message := fmt.Sprint(args...); limited.allow(message) {
+		limited.Writer.write(level, message)
+	}
+}
+
+func (limited *LimitedWriter) writef(level LevelType, format string, args ...interface{}) {
+	if message := fmt.Sprintf(format, args...); limited.allow(message) {
+		limited.Writer.write(level, message)
+	}
+}
+
+// Trace trace
+func (limited *LimitedWriter) Trace(args ...interface{}) {
+	if TRACE < limited.Level() {
+		return
+	}
+
+	limited.write(TRACE, args...)
+}
+
+// Tracef tracef
+func (limited *LimitedWriter) Tracef(format string, args ...interface{}) {
+	if TRACE < limited.Level() {
+		return
+	}
+
+	limited.writef(TRACE, format, args...)
+}
+
+// Debug debug
+func (limited *LimitedWriter) Debug(args ...interface{}) {
+	if DEBUG < limited.Level() {
+		return
+	}
+
+	limited.write(DEBUG, args...)
+}
+
+// Debugf debugf
+func (limited *LimitedWriter) Debugf(format string, args ...interface{}) {
+	if DEBUG < limited.Level() {
+		return
+	}
+
+	limited.writef(DEBUG, format, args...)
+}
+
+// Info info
+func (limited *LimitedWriter) Info(args ...interface{}) {
+	if INFO < limited.Level() {
+		return
+	}
+
+	limited.write(INFO, args...)
+}
+
+// Infof infof
+func (limited *LimitedWriter) Infof(format string, args ...interface{}) {
+	if INFO < limited.Level() {
+		return
+	}
+
+	limited.writef(INFO, format, args...)
+}
+
+// Warn warn
+func (limited *LimitedWriter) Warn(args ...interface{}) {
+	if WARNING < limited.Level() {
+		return
+	}
+
+	limited.write(WARNING, args...)
+}
+
+// Warnf warnf
+func (limited *LimitedWriter) Warnf(format string, args ...interface{}) {
+	if WARNING < limited.Level() {
+		return
+	}
+
+	limited.writef(WARNING, format, args...)
+}
+
+// Error error
+func (limited *LimitedWriter) Error(args ...interface{}) {
+	if ERROR < limited.Level() {
+		return
+	}
+
+	limited.write(ERROR, args...)
+}
+
+// Errorf errorf
+func (limited *LimitedWriter) Errorf(format string, args ...interface{}) {
+	if ERROR < limited.Level() {
+		return
+	}
+
+	limited.writef(ERROR, format, args...)
+}
+
+// Critical critical
+func (limited *LimitedWriter) Critical(args ...interface{}) {
+	if CRITICAL < limited.Level() {
+		return
+	}
+
+	limited.write(CRITICAL, args...)
+}
+
+// Criticalf criticalf
+func (limited *LimitedWriter) Criticalf(format string, args ...interface{}) {
+	if CRITICAL < limited.Level() {
+		return
+	}
+
+	limited.writef(CRITICAL, format, args...)
+}
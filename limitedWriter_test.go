@@ -0,0 +1,45 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"testing"
+)
+
+func TestLimitedWriter(t *testing.T) {
+	writer, err := newRingBufferWriter(10)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	reached := 0
+	limited := NewLimitedWriter(writer, 10)
+	limited.SetOnLimitReached(func() { reached++ })
+
+	limited.Info("12345")
+	limited.Infof("%d", 6789)
+	if 1 != limited.BytesRemaining() {
+		t.Errorf("bytes remaining wrong. remaining: %d", limited.BytesRemaining())
+	}
+
+	limited.Info("dropped")
+	limited.Info("x")
+	if 2 != len(writer.Entries()) || 1 != reached {
+		t.Errorf("messages should be dropped once the budget is reached. entries: %v, reached: %d", writer.Lines(), reached)
+	}
+	if 0 != limited.BytesRemaining() {
+		t.Errorf("no bytes should remain. remaining: %d", limited.BytesRemaining())
+	}
+
+	limited.Reset()
+	limited.Info("again")
+	limited.Info("budget exceeded")
+	if 3 != len(writer.Entries()) || 2 != reached {
+		t.Errorf("messages should be written after reset. entries: %v, reached: %d", writer.Lines(), reached)
+	}
+
+	limited.Close()
+	if !writer.Closed() {
+		t.Error("writer wrapped should be closed")
+	}
+}
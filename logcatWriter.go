@@ -0,0 +1,344 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// LogcatWriter is a logger writing to android logcat with
+// __android_log_write, for applications built with gomobile. Levels are
+// mapped to android log priorities, TRACE to ANDROID_LOG_VERBOSE, DEBUG to
+// ANDROID_LOG_DEBUG, INFO to ANDROID_LOG_INFO, WARNING to ANDROID_LOG_WARN,
+// ERROR to ANDROID_LOG_ERROR and CRITICAL to ANDROID_LOG_FATAL.
+// On other platforms messages are written to os.Stderr prefixed with tag.
+type LogcatWriter struct {
+	writerBase
+
+	level LevelType
+
+	closed bool
+
+	// log hook
+	hook      Hook
+	hookLevel LevelType
+	hookAsync bool
+
+	// middlewares applied to message before written
+	middlewareList
+
+	// logcat with tag
+	log *logcat
+
+	lock *sync.Mutex
+}
+
+// NewLogcatWriter creates a logcat writer with tag, singlton
+func NewLogcatWriter(tag string) (err error) {
+	singltonLock.Lock()
+	defer singltonLock.Unlock()
+	if nil != blog {
+		return ErrAlreadyInit
+	}
+
+	logcatWriter, err := newLogcatWriter(tag)
+	if nil != err {
+		return err
+	}
+
+	blog = logcatWriter
+	return nil
+}
+
+// newLogcatWriter creates a logcat writer, not singlton
+func newLogcatWriter(tag string) (logcatWriter *LogcatWriter, err error) {
+	log, err := openLogcat(tag)
+	if nil != err {
+		return nil, err
+	}
+
+	logcatWriter = new(LogcatWriter)
+	logcatWriter.self = logcatWriter
+	logcatWriter.level = DEBUG
+	logcatWriter.closed = false
+	logcatWriter.log = log
+	logcatWriter.lock = new(sync.Mutex)
+
+	// log hook
+	logcatWriter.hook = nil
+	logcatWriter.hookLevel = DEBUG
+	logcatWriter.hookAsync = true
+
+	return logcatWriter, nil
+}
+
+// push applies middlewares to message and writes it to logcat
+func (writer *LogcatWriter) push(level LevelType, message string) {
+	message = writer.applyMiddlewares(level, message)
+
+	writer.log.write(level, message)
+}
+
+func (writer *LogcatWriter) write(level LevelType, args ...interface{}) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	if writer.closed {
+		return
+	}
+
+	defer func() {
+		// call log hook
+		if nil != writer.hook && !(level < writer.hookLevel) {
+			if writer.hookAsync {
+				go func(level LevelType, args ...interface{}) {
+					writer.hook.Fire(level, args...)
+				}(level, args...)
+
+			} else {
+				writer.hook.Fire(level, args...)
+			}
+		}
+	}()
+
+	writer.push(level, fmt.Sprint(args...))
+}
+
+func (writer *LogcatWriter) writef(level LevelType, format string, args ...interface{}) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	if writer.closed {
+		return
+	}
+
+	defer func() {
+		// call log hook
+		if nil != writer.hook && !(level < writer.hookLevel) {
+			if writer.hookAsync {
+				go func(level LevelType, format string, args ...interface{}) {
+					writer.hook.Fire(level, fmt.Sprintf(format, args...))
+				}(level, format, args...)
+
+			} else {
+				writer.hook.Fire(level, fmt.Sprintf(format, args...))
+			}
+		}
+	}()
+
+	writer.push(level, fmt.Sprintf(format, args...))
+}
+
+// Level get level
+func (writer *LogcatWriter) Level() LevelType {
+	return writer.level
+}
+
+// SetLevel set logger level
+func (writer *LogcatWriter) SetLevel(level LevelType) {
+	writer.level = level
+}
+
+// SetHook set hook for logging action
+func (writer *LogcatWriter) SetHook(hook Hook) {
+	writer.hook = hook
+}
+
+// SetHookAsync set hook async for logcat writer
+func (writer *LogcatWriter) SetHookAsync(async bool) {
+	writer.hookAsync = async
+}
+
+// SetHookLevel set when hook will be called
+func (writer *LogcatWriter) SetHookLevel(level LevelType) {
+	writer.hookLevel = level
+}
+
+// TimeRotated do nothing
+func (writer *LogcatWriter) TimeRotated() bool {
+	return false
+}
+
+// SetTimeRotated do nothing
+func (writer *LogcatWriter) SetTimeRotated(timeRotated bool) {
+	return
+}
+
+// Retentions do nothing
+func (writer *LogcatWriter) Retentions() int64 {
+	return 0
+}
+
+// SetRetentions do nothing
+func (writer *LogcatWriter) SetRetentions(retentions int64) {
+	return
+}
+
+// RotateSize do nothing
+func (writer *LogcatWriter) RotateSize() int64 {
+	return 0
+}
+
+// SetRotateSize do nothing
+func (writer *LogcatWriter) SetRotateSize(rotateSize int64) {
+	return
+}
+
+// RotateLines do nothing
+func (writer *LogcatWriter) RotateLines() int {
+	return 0
+}
+
+// SetRotateLines do nothing
+func (writer *LogcatWriter) SetRotateLines(rotateLines int) {
+	return
+}
+
+// Colored do nothing
+func (writer *LogcatWriter) Colored() bool {
+	return false
+}
+
+// SetColored do nothing
+func (writer *LogcatWriter) SetColored(colored bool) {
+	return
+}
+
+// PipeFromWithContext logs every line read from r with given level until r
+// reaches EOF or ctx is done
+func (writer *LogcatWriter) PipeFromWithContext(ctx context.Context, r io.Reader, level LevelType) error {
+	if writer.closed {
+		return ErrWriterClosed
+	}
+
+	go pipe(ctx, writer, r, level)
+	return nil
+}
+
+// Close close the writer
+func (writer *LogcatWriter) Close() {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if writer.closed {
+		return
+	}
+
+	writer.closed = true
+	writer.log.close()
+}
+
+// flush do nothing, messages are written to logcat at once
+func (writer *LogcatWriter) flush() {
+	return
+}
+
+// Trace trace
+func (writer *LogcatWriter) Trace(args ...interface{}) {
+	if TRACE < writer.level {
+		return
+	}
+
+	writer.write(TRACE, args...)
+}
+
+// Tracef tracef
+func (writer *LogcatWriter) Tracef(format string, args ...interface{}) {
+	if TRACE < writer.level {
+		return
+	}
+
+	writer.writef(TRACE, format, args...)
+}
+
+// Debug debug
+func (writer *LogcatWriter) Debug(args ...interface{}) {
+	if DEBUG < writer.level {
+		return
+	}
+
+	writer.write(DEBUG, args...)
+}
+
+// Debugf debugf
+func (writer *LogcatWriter) Debugf(format string, args ...interface{}) {
+	if DEBUG < writer.level {
+		return
+	}
+
+	writer.writef(DEBUG, format, args...)
+}
+
+// Info info
+func (writer *LogcatWriter) Info(args ...interface{}) {
+	if INFO < writer.level {
+		return
+	}
+
+	writer.write(INFO, args...)
+}
+
+// Infof infof
+func (writer *LogcatWriter) Infof(format string, args ...interface{}) {
+	if INFO < writer.level {
+		return
+	}
+
+	writer.writef(INFO, format, args...)
+}
+
+// Warn warn
+func (writer *LogcatWriter) Warn(args ...interface{}) {
+	if WARNING < writer.level {
+		return
+	}
+
+	writer.write(WARNING, args...)
+}
+
+// Warnf warnf
+func (writer *LogcatWriter) Warnf(format string, args ...interface{}) {
+	if WARNING < writer.level {
+		return
+	}
+
+	writer.writef(WARNING, format, args...)
+}
+
+// Error error
+func (writer *LogcatWriter) Error(args ...interface{}) {
+	if ERROR < writer.level {
+		return
+	}
+
+	writer.write(ERROR, args...)
+}
+
+// Errorf errorf
+func (writer *LogcatWriter) Errorf(format string, args ...interface{}) {
+	if ERROR < writer.level {
+		return
+	}
+
+	writer.writef(ERROR, format, args...)
+}
+
+// Critical critical
+func (writer *LogcatWriter) Critical(args ...interface{}) {
+	if CRITICAL < writer.level {
+		return
+	}
+
+	writer.write(CRITICAL, args...)
+}
+
+// Criticalf criticalf
+func (writer *LogcatWriter) Criticalf(format string, args ...interface{}) {
+	if CRITICAL < writer.level {
+		return
+	}
+
+	writer.writef(CRITICAL, format, args...)
+}
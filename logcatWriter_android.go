@@ -0,0 +1,58 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+//go:build android && cgo
+// +build android,cgo
+
+package blog4go
+
+/*
+#cgo LDFLAGS: -llog
+#include <stdlib.h>
+#include <android/log.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+// logcat writes to android logcat with tag
+type logcat struct {
+	tag *C.char
+}
+
+// openLogcat creates a logcat with tag
+func openLogcat(tag string) (*logcat, error) {
+	return &logcat{tag: C.CString(tag)}, nil
+}
+
+// write writes message with android log priority mapped from level.
+// Calls are serialized by the writer.
+func (log *logcat) write(level LevelType, message string) {
+	var priority C.int
+	switch level {
+	case TRACE:
+		priority = C.ANDROID_LOG_VERBOSE
+	case DEBUG:
+		priority = C.ANDROID_LOG_DEBUG
+	case INFO:
+		priority = C.ANDROID_LOG_INFO
+	case WARNING:
+		priority = C.ANDROID_LOG_WARN
+	case ERROR:
+		priority = C.ANDROID_LOG_ERROR
+	case CRITICAL:
+		priority = C.ANDROID_LOG_FATAL
+	default:
+		priority = C.ANDROID_LOG_DEFAULT
+	}
+
+	cMessage := C.CString(message)
+	defer C.free(unsafe.Pointer(cMessage))
+	C.__android_log_write(priority, log.tag, cMessage)
+}
+
+// close frees the tag
+func (log *logcat) close() {
+	C.free(unsafe.Pointer(log.tag))
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+//go:build !android || !cgo
+// +build !android !cgo
+
+package blog4go
+
+import (
+	"io"
+	"os"
+)
+
+// logcat falls back to os.Stderr when logcat is not available
+type logcat struct {
+	tag string
+	out io.Writer
+}
+
+// openLogcat creates a logcat writing to os.Stderr
+func openLogcat(tag string) (*logcat, error) {
+	return &logcat{tag: tag, out: os.Stderr}, nil
+}
+
+// write writes message prefixed with timestamp, level and tag.
+// Calls are serialized by the writer.
+func (log *logcat) write(level LevelType, message string) {
+	line := make([]byte, 0, len(PrefixTimeFormat)+len(log.tag)+len(message)+16)
+	line = append(line, timeCache.Format()...)
+	line = append(line, level.prefix()...)
+	line = append(line, log.tag...)
+	line = append(line, ": "...)
+	line = append(line, message...)
+	line = append(line, EOL)
+	log.out.Write(line)
+}
+
+// close do nothing, os.Stderr is kept open
+func (log *logcat) close() {
+	return
+}
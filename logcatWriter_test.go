@@ -0,0 +1,77 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+//go:build !android || !cgo
+// +build !android !cgo
+
+package blog4go
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLogcatWriter(t *testing.T) {
+	writer, err := newLogcatWriter("blog4go")
+	if nil != err {
+		t.Fatalf("initialize logcat writer failed. err: %s", err.Error())
+	}
+
+	// fallback writes to os.Stderr
+	var buffer bytes.Buffer
+	writer.log.out = &buffer
+
+	hook := NewMyHook()
+	writer.SetHook(hook)
+	writer.SetHookAsync(false)
+	writer.SetHookLevel(ERROR)
+	writer.AddMiddleware(func(level LevelType, message string) string {
+		return strings.ToUpper(message)
+	})
+
+	// do nothing operations
+	writer.SetColored(true)
+	writer.SetTimeRotated(true)
+	writer.SetRetentions(7)
+	writer.SetRotateSize(1024)
+	writer.SetRotateLines(100)
+	writer.SetEOL(EOLWindows)
+	if writer.Colored() || writer.TimeRotated() || 0 != writer.Retentions() || 0 != writer.RotateSize() || 0 != writer.RotateLines() {
+		t.Error("logcat writer should ignore file settings")
+	}
+
+	writer.SetLevel(TRACE)
+	if TRACE != writer.Level() {
+		t.Error("logcat writer level wrong")
+	}
+
+	writer.BeginShutdown()
+	writer.flush()
+	writer.Trace("trace")
+	writer.Tracef("%s", "trace")
+	writer.Debug("debug")
+	writer.Debugf("%s", "debug")
+	writer.Info("info")
+	writer.Infof("%s", "info")
+	writer.Warn("warn")
+	writer.Warnf("%s", "warn")
+	writer.Error("error")
+	writer.Errorf("%s", "error")
+	writer.Critical("critical")
+	writer.Criticalf("%s", "critical")
+	if 4 != hook.Cnt() || "critical" != hook.Message() {
+		t.Errorf("hook called wrong. count: %d, message: %s", hook.Cnt(), hook.Message())
+	}
+
+	writer.Close()
+	writer.Close()
+	writer.Info("closed")
+	if ErrWriterClosed != writer.PipeFrom(strings.NewReader(""), INFO) {
+		t.Error("pipe from closed writer should fail")
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if 12 != len(lines) || !strings.HasSuffix(lines[0], "] blog4go: TRACE") || !strings.HasSuffix(lines[11], "] blog4go: CRITICAL") {
+		t.Errorf("logcat writer wrote wrong messages. lines: %q", lines)
+	}
+}
@@ -0,0 +1,260 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/json"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// mergeItem is an entry read from one of the streams merged
+type mergeItem struct {
+	time time.Time
+	// json line, or lines of the entry in text
+	raw []byte
+	// index of the stream
+	source int
+}
+
+// mergeHeap is a min-heap of the head entries of streams, ordered by time
+// then index of the stream
+type mergeHeap []mergeItem
+
+func (h mergeHeap) Len() int { return len(h) }
+func (h mergeHeap) Less(i, j int) bool {
+	if h[i].time.Equal(h[j].time) {
+		return h[i].source < h[j].source
+	}
+	return h[i].time.Before(h[j].time)
+}
+func (h mergeHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *mergeHeap) Push(x interface{}) { *h = append(*h, x.(mergeItem)) }
+func (h *mergeHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// MergeLogStreams merges json lines read from readers into output, index of
+// the stream read from is added to every entry as field source, like
+// {"level":"INFO","message":"hello","source":1,"time":"..."}. If
+// sortByTime, entries are written in chronological order, every stream
+// must be in chronological order itself. Otherwise entries are written as
+// they are read.
+func MergeLogStreams(readers []io.Reader, output io.Writer, sortByTime bool) error {
+	if sortByTime {
+		return mergeByTime(readers, output)
+	}
+	return mergeByArrival(readers, output)
+}
+
+// mergeByTime keeps the head entry of every stream in a min-heap, writes
+// the earliest one and replaces it with the next entry of the same stream
+func mergeByTime(readers []io.Reader, output io.Writer) error {
+	jsonlReaders := make([]*JSONLReader, len(readers))
+	h := make(mergeHeap, 0, len(readers))
+
+	// next pushes the next entry of stream i, nothing is pushed at the end
+	// of the stream
+	next := func(i int) error {
+		entry, raw, err := jsonlReaders[i].next()
+		if io.EOF == err {
+			return nil
+		} else if nil != err {
+			return err
+		}
+		heap.Push(&h, mergeItem{entry.Time, raw, i})
+		return nil
+	}
+
+	for i, r := range readers {
+		jsonlReaders[i] = NewJSONLReader(r)
+		if err := next(i); nil != err {
+			return err
+		}
+	}
+
+	for h.Len() > 0 {
+		item := heap.Pop(&h).(mergeItem)
+		if err := writeMerged(output, item); nil != err {
+			return err
+		}
+		if err := next(item.source); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeByArrival reads every stream in its own goroutine, entries are
+// written as they are read. The first error is returned after every stream
+// stops.
+func mergeByArrival(readers []io.Reader, output io.Writer) error {
+	items := make(chan mergeItem)
+	errs := make(chan error, len(readers))
+	// closed to stop reading if output fails
+	done := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i, r := range readers {
+		wg.Add(1)
+		go func(i int, r io.Reader) {
+			defer wg.Done()
+
+			reader := NewJSONLReader(r)
+			for {
+				entry, raw, err := reader.next()
+				if io.EOF == err {
+					return
+				} else if nil != err {
+					errs <- err
+					return
+				}
+
+				select {
+				case items <- mergeItem{entry.Time, raw, i}:
+				case <-done:
+					return
+				}
+			}
+		}(i, r)
+	}
+
+	go func() {
+		wg.Wait()
+		close(items)
+	}()
+
+	var err error
+	for item := range items {
+		if nil != err {
+			continue
+		}
+		if err = writeMerged(output, item); nil != err {
+			close(done)
+		}
+	}
+
+	if nil == err {
+		select {
+		case err = <-errs:
+		default:
+		}
+	}
+	return err
+}
+
+// writeMerged writes json line of item with field source added
+func writeMerged(output io.Writer, item mergeItem) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(item.raw, &fields); nil != err {
+		return err
+	}
+	fields["source"] = json.RawMessage(strconv.Itoa(item.source))
+
+	line, err := json.Marshal(fields)
+	if nil != err {
+		return err
+	}
+	if _, err = output.Write(line); nil != err {
+		return err
+	}
+	_, err = output.Write(EOLUnix)
+	return err
+}
+
+// MergeLogFiles merges logs written by blog4go read from readers into
+// output in chronological order with a n-way merge, like files written by
+// different writers at the same time. Every stream must be in chronological
+// order itself. Lines without timestamp, like continuation lines of
+// multi-line messages, are kept with the line ahead of them.
+func MergeLogFiles(readers []io.Reader, output io.Writer) error {
+	streams := make([]*textStream, len(readers))
+	h := make(mergeHeap, 0, len(readers))
+
+	// next pushes the next entry of stream i, nothing is pushed at the end
+	// of the stream
+	next := func(i int) error {
+		t, lines, err := streams[i].next()
+		if io.EOF == err {
+			return nil
+		} else if nil != err {
+			return err
+		}
+		heap.Push(&h, mergeItem{t, lines, i})
+		return nil
+	}
+
+	for i, r := range readers {
+		streams[i] = &textStream{reader: bufio.NewReader(r)}
+		if err := next(i); nil != err {
+			return err
+		}
+	}
+
+	for h.Len() > 0 {
+		item := heap.Pop(&h).(mergeItem)
+		if _, err := output.Write(item.raw); nil != err {
+			return err
+		}
+		if err := next(item.source); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// textStream reads entries written by blog4go, an entry is a line starting
+// with timestamp and lines without timestamp after it
+type textStream struct {
+	reader *bufio.Reader
+	// line read ahead, starting the next entry
+	pending []byte
+}
+
+// next return timestamp and lines of the next entry, zero time if lines at
+// the beginning of the stream have no timestamp. io.EOF is returned at the
+// end of the stream.
+func (stream *textStream) next() (time.Time, []byte, error) {
+	lines := stream.pending
+	stream.pending = nil
+	if nil == lines {
+		line, err := stream.readLine()
+		if nil != err {
+			return time.Time{}, nil, err
+		}
+		lines = line
+	}
+	t, _ := lineTime(lines)
+
+	for {
+		line, err := stream.readLine()
+		if io.EOF == err {
+			return t, lines, nil
+		} else if nil != err {
+			return time.Time{}, nil, err
+		}
+
+		if _, ok := lineTime(line); ok {
+			stream.pending = line
+			return t, lines, nil
+		}
+		lines = append(lines, line...)
+	}
+}
+
+// readLine return the next line with EOL, EOL is added to the last line if
+// missing
+func (stream *textStream) readLine() ([]byte, error) {
+	line, err := stream.reader.ReadBytes(EOL)
+	if io.EOF == err && len(line) > 0 {
+		return append(line, EOL), nil
+	}
+	return line, err
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestMergeLogStreams(t *testing.T) {
+	streams := func() []io.Reader {
+		return []io.Reader{
+			strings.NewReader(`{"time":"2017-06-30T12:00:01Z","level":"INFO","message":"a1","host":"a"}
+{"time":"2017-06-30T12:00:03Z","level":"INFO","message":"a2","host":"a"}
+`),
+			strings.NewReader(`{"time":"2017-06-30T12:00:00Z","level":"ERROR","message":"b1"}
+{"time":"2017-06-30T12:00:03Z","level":"INFO","message":"b2"}
+{"time":"2017-06-30T12:00:04Z","level":"INFO","message":"b3"}
+`),
+		}
+	}
+
+	output := new(bytes.Buffer)
+	if err := MergeLogStreams(streams(), output, true); nil != err {
+		t.Fatalf("merge failed. err: %s", err.Error())
+	}
+
+	expected := []string{
+		`{"level":"ERROR","message":"b1","source":1,"time":"2017-06-30T12:00:00Z"}`,
+		`{"host":"a","level":"INFO","message":"a1","source":0,"time":"2017-06-30T12:00:01Z"}`,
+		`{"host":"a","level":"INFO","message":"a2","source":0,"time":"2017-06-30T12:00:03Z"}`,
+		`{"level":"INFO","message":"b2","source":1,"time":"2017-06-30T12:00:03Z"}`,
+		`{"level":"INFO","message":"b3","source":1,"time":"2017-06-30T12:00:04Z"}`,
+	}
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if strings.Join(expected, "\n") != strings.Join(lines, "\n") {
+		t.Errorf("streams merged wrong. lines: %q", lines)
+	}
+
+	// every entry is written without sorting
+	output.Reset()
+	if err := MergeLogStreams(streams(), output, false); nil != err {
+		t.Fatalf("merge failed. err: %s", err.Error())
+	}
+	lines = strings.Split(strings.TrimSpace(output.String()), "\n")
+	sort.Strings(lines)
+	sort.Strings(expected)
+	if strings.Join(expected, "\n") != strings.Join(lines, "\n") {
+		t.Errorf("streams multiplexed wrong. lines: %q", lines)
+	}
+
+	if err := MergeLogStreams([]io.Reader{strings.NewReader("not json")}, output, true); nil == err {
+		t.Error("merge should fail with invalid json")
+	}
+	if err := MergeLogStreams([]io.Reader{strings.NewReader("not json")}, output, false); nil == err {
+		t.Error("multiplex should fail with invalid json")
+	}
+}
+
+func TestMergeLogFiles(t *testing.T) {
+	a := `[2017/06/30:12:00:01] [INFO] a1
+[2017/06/30:12:00:03] [ERROR] a2 multi-line
+  continued
+`
+	b := `no timestamp
+[2017/06/30:12:00:00] [INFO] b1
+[2017/06/30:12:00:02] [INFO] b2
+[2017/06/30:12:00:03] [INFO] b3 without EOL`
+
+	output := new(bytes.Buffer)
+	if err := MergeLogFiles([]io.Reader{strings.NewReader(a), strings.NewReader(b)}, output); nil != err {
+		t.Fatalf("merge failed. err: %s", err.Error())
+	}
+
+	expected := `no timestamp
+[2017/06/30:12:00:00] [INFO] b1
+[2017/06/30:12:00:01] [INFO] a1
+[2017/06/30:12:00:02] [INFO] b2
+[2017/06/30:12:00:03] [ERROR] a2 multi-line
+  continued
+[2017/06/30:12:00:03] [INFO] b3 without EOL
+`
+	if expected != output.String() {
+		t.Errorf("files merged wrong. output: %q", output.String())
+	}
+}
@@ -0,0 +1,11 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+// Middleware is a function applied to message before it is written.
+// level is the level associate with that logging action.
+// message is the formatted string going to be written, and the returned
+// string will be written or passed to the next middleware instead.
+// Unlike Hook, which is called after message written, middleware may
+// change the message, like adding request id or removing sensitive data.
+type Middleware func(level LevelType, message string) string
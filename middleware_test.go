@@ -0,0 +1,44 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestBLogMiddleware(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	blog := NewBLog(buffer)
+
+	blog.AddMiddleware(func(level LevelType, message string) string {
+		return "[req:1] " + message
+	})
+	blog.AddMiddleware(func(level LevelType, message string) string {
+		if ERROR == level {
+			return strings.Replace(message, "secret", "******", -1)
+		}
+		return message
+	})
+
+	size := blog.write(INFO, "hello secret")
+	size += blog.writef(ERROR, "password %s, %d\\%%", "secret", 100)
+	blog.flush()
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if 2 != len(lines) || size != buffer.Len() {
+		t.Fatalf("middleware wrote wrong lines. content: %s", buffer.String())
+	}
+
+	if !strings.HasSuffix(lines[0], "] [req:1] hello secret") {
+		t.Errorf("middleware applied wrong. line: %s", lines[0])
+	}
+
+	// the same as formatted without middleware
+	plain := new(bytes.Buffer)
+	formatTo(plain, "[req:1] password %s, %d\\%%", "******", 100)
+	if !strings.HasSuffix(lines[1], "] "+plain.String()) {
+		t.Errorf("middleware applied wrong. line: %s", lines[1])
+	}
+}
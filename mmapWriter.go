@@ -0,0 +1,506 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// ErrInvalidMapSize invalid size of memory mapped region
+	ErrInvalidMapSize = errors.New("Map size must be greater than 0")
+)
+
+// MMapWriter is a file logger writing to a memory mapped region of the file
+// for low latency, messages are copied to the region with only an atomic
+// cursor update, and flushed to disk by the kernel. When the region is
+// full, the file is truncated to the size written and rotated with suffix
+// xxx.1, xxx.2, then a new region is mapped. Zero bytes may be left at the
+// end of the file if the program crashed.
+// It is supported only on unix like platforms.
+type MMapWriter struct {
+	writerBase
+
+	level LevelType
+
+	closed bool
+
+	// log hook
+	hook      Hook
+	hookLevel LevelType
+	hookAsync bool
+
+	// end of every line
+	eol []byte
+
+	// middlewares applied to message before written
+	middlewareList
+
+	// full path of the file
+	fileName string
+	file     *os.File
+	// size of the memory mapped region
+	maxSize int64
+	// number of files keeped after rotation
+	retentions int64
+
+	// memory mapped region
+	region []byte
+	// size reserved in the region, may exceed the region when full
+	cursor int64
+	// size written in the region when full
+	used int64
+
+	// messages are copied under read lock, region is remapped under write
+	// lock
+	lock *sync.RWMutex
+}
+
+// NewMMapWriter creates a memory mapped file writer, singlton.
+// maxSize is the size of the region mapped, also the max size of a file.
+func NewMMapWriter(fileName string, maxSize int64) (err error) {
+	singltonLock.Lock()
+	defer singltonLock.Unlock()
+	if nil != blog {
+		return ErrAlreadyInit
+	}
+
+	mmapWriter, err := newMMapWriter(fileName, maxSize)
+	if nil != err {
+		return err
+	}
+
+	blog = mmapWriter
+	return nil
+}
+
+// newMMapWriter creates a memory mapped file writer, not singlton
+func newMMapWriter(fileName string, maxSize int64) (mmapWriter *MMapWriter, err error) {
+	if maxSize < 1 {
+		return nil, ErrInvalidMapSize
+	}
+
+	mmapWriter = new(MMapWriter)
+	mmapWriter.self = mmapWriter
+	mmapWriter.level = DEBUG
+	mmapWriter.closed = false
+	mmapWriter.eol = EOLUnix
+	mmapWriter.fileName = fileName
+	mmapWriter.maxSize = maxSize
+	mmapWriter.retentions = DefaultLogRetentionCount
+	mmapWriter.lock = new(sync.RWMutex)
+
+	// log hook
+	mmapWriter.hook = nil
+	mmapWriter.hookLevel = DEBUG
+	mmapWriter.hookAsync = true
+
+	if err = mmapWriter.mapFile(); nil != err {
+		return nil, err
+	}
+	return mmapWriter, nil
+}
+
+// mapFile opens the file and maps a new region after data already written
+func (writer *MMapWriter) mapFile() (err error) {
+	file, err := os.OpenFile(writer.fileName, os.O_RDWR|os.O_CREATE, os.FileMode(0644))
+	if nil != err {
+		return err
+	}
+
+	info, err := file.Stat()
+	if nil != err {
+		file.Close()
+		return err
+	}
+
+	// region must start at a page boundary, rotate the file if not enough
+	// space left after data already written
+	if 0 != info.Size() {
+		file.Close()
+		if err = writer.rotateFiles(); nil != err {
+			return err
+		}
+		return writer.mapFile()
+	}
+
+	if err = file.Truncate(writer.maxSize); nil != err {
+		file.Close()
+		return err
+	}
+
+	region, err := mmap(file, int(writer.maxSize))
+	if nil != err {
+		file.Close()
+		return err
+	}
+
+	writer.file = file
+	writer.region = region
+	writer.used = 0
+	atomic.StoreInt64(&writer.cursor, 0)
+	return nil
+}
+
+// unmapFile unmaps the region and truncates the file to size written
+func (writer *MMapWriter) unmapFile() {
+	size := atomic.LoadInt64(&writer.cursor)
+	if size > writer.maxSize {
+		size = writer.used
+	}
+
+	munmap(writer.region)
+	writer.region = nil
+	writer.file.Truncate(size)
+	writer.file.Close()
+}
+
+// rotateFiles renames files with suffix xxx.1, xxx.2 like size base
+// logrotate of file writer
+func (writer *MMapWriter) rotateFiles() error {
+	os.Remove(fmt.Sprintf("%s.%d", writer.fileName, writer.retentions))
+	for i := writer.retentions - 1; i > 0; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", writer.fileName, i), fmt.Sprintf("%s.%d", writer.fileName, i+1))
+	}
+	return os.Rename(writer.fileName, writer.fileName+".1")
+}
+
+// append formats and copies message to the region, remaps if the region is
+// full. Lines larger than the region are dropped.
+func (writer *MMapWriter) append(level LevelType, message string) {
+	writer.lock.RLock()
+	line := writer.format(level, message)
+	writer.lock.RUnlock()
+
+	size := int64(len(line))
+	if size > writer.maxSize {
+		return
+	}
+
+	for {
+		writer.lock.RLock()
+		if writer.closed {
+			writer.lock.RUnlock()
+			return
+		}
+
+		end := atomic.AddInt64(&writer.cursor, size)
+		if end <= writer.maxSize {
+			copy(writer.region[end-size:end], line)
+			writer.lock.RUnlock()
+			return
+		}
+
+		// only the first reservation overflowed knows size written
+		if end-size <= writer.maxSize {
+			writer.used = end - size
+		}
+		writer.lock.RUnlock()
+
+		writer.remap()
+	}
+}
+
+// remap rotates the file and maps a new region if the region is full
+func (writer *MMapWriter) remap() {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	// remapped by others already
+	if writer.closed || atomic.LoadInt64(&writer.cursor) <= writer.maxSize {
+		return
+	}
+
+	writer.unmapFile()
+	if err := writer.mapFile(); nil != err {
+		writer.closed = true
+	}
+}
+
+// format formats message into a line
+func (writer *MMapWriter) format(level LevelType, message string) []byte {
+	message = writer.applyMiddlewares(level, message)
+
+	buffer := bytes.NewBuffer(make([]byte, 0, len(timeCache.Format())+len(level.prefix())+len(message)+len(writer.eol)))
+	buffer.Write(timeCache.Format())
+	buffer.WriteString(level.prefix())
+	buffer.WriteString(message)
+	buffer.Write(writer.eol)
+	return buffer.Bytes()
+}
+
+func (writer *MMapWriter) write(level LevelType, args ...interface{}) {
+	if writer.closed {
+		return
+	}
+
+	defer func() {
+		// call log hook
+		if nil != writer.hook && !(level < writer.hookLevel) {
+			if writer.hookAsync {
+				go func(level LevelType, args ...interface{}) {
+					writer.hook.Fire(level, args...)
+				}(level, args...)
+
+			} else {
+				writer.hook.Fire(level, args...)
+			}
+		}
+	}()
+
+	writer.append(level, fmt.Sprint(args...))
+}
+
+func (writer *MMapWriter) writef(level LevelType, format string, args ...interface{}) {
+	if writer.closed {
+		return
+	}
+
+	defer func() {
+		// call log hook
+		if nil != writer.hook && !(level < writer.hookLevel) {
+			if writer.hookAsync {
+				go func(level LevelType, format string, args ...interface{}) {
+					writer.hook.Fire(level, fmt.Sprintf(format, args...))
+				}(level, format, args...)
+
+			} else {
+				writer.hook.Fire(level, fmt.Sprintf(format, args...))
+			}
+		}
+	}()
+
+	writer.append(level, fmt.Sprintf(format, args...))
+}
+
+// Level get level
+func (writer *MMapWriter) Level() LevelType {
+	return writer.level
+}
+
+// SetLevel set logger level
+func (writer *MMapWriter) SetLevel(level LevelType) {
+	writer.level = level
+}
+
+// SetHook set hook for logging action
+func (writer *MMapWriter) SetHook(hook Hook) {
+	writer.hook = hook
+}
+
+// SetHookAsync set hook async for memory mapped file writer
+func (writer *MMapWriter) SetHookAsync(async bool) {
+	writer.hookAsync = async
+}
+
+// SetHookLevel set when hook will be called
+func (writer *MMapWriter) SetHookLevel(level LevelType) {
+	writer.hookLevel = level
+}
+
+// TimeRotated do nothing
+func (writer *MMapWriter) TimeRotated() bool {
+	return false
+}
+
+// SetTimeRotated do nothing
+func (writer *MMapWriter) SetTimeRotated(timeRotated bool) {
+	return
+}
+
+// Retentions get number of files keeped after rotation
+func (writer *MMapWriter) Retentions() int64 {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.retentions
+}
+
+// SetRetentions set number of files keeped after rotation
+func (writer *MMapWriter) SetRetentions(retentions int64) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if retentions < 1 {
+		return
+	}
+	writer.retentions = retentions
+}
+
+// RotateSize get size of the memory mapped region
+func (writer *MMapWriter) RotateSize() int64 {
+	return writer.maxSize
+}
+
+// SetRotateSize do nothing, size of the region is decided on creation
+func (writer *MMapWriter) SetRotateSize(rotateSize int64) {
+	return
+}
+
+// RotateLines do nothing
+func (writer *MMapWriter) RotateLines() int {
+	return 0
+}
+
+// SetRotateLines do nothing
+func (writer *MMapWriter) SetRotateLines(rotateLines int) {
+	return
+}
+
+// Colored do nothing
+func (writer *MMapWriter) Colored() bool {
+	return false
+}
+
+// SetColored do nothing
+func (writer *MMapWriter) SetColored(colored bool) {
+	return
+}
+
+// SetEOL set end of every line
+func (writer *MMapWriter) SetEOL(eol []byte) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.eol = append([]byte(nil), eol...)
+}
+
+// Close unmaps the region and truncates the file to size written
+func (writer *MMapWriter) Close() {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if writer.closed {
+		return
+	}
+
+	writer.closed = true
+	writer.unmapFile()
+}
+
+// PipeFromWithContext logs every line read from r with given level until r
+// reaches EOF or ctx is done
+func (writer *MMapWriter) PipeFromWithContext(ctx context.Context, r io.Reader, level LevelType) error {
+	if writer.closed {
+		return ErrWriterClosed
+	}
+
+	go pipe(ctx, writer, r, level)
+	return nil
+}
+
+// flush do nothing, messages are flushed by the kernel
+func (writer *MMapWriter) flush() {
+	return
+}
+
+// Trace trace
+func (writer *MMapWriter) Trace(args ...interface{}) {
+	if TRACE < writer.level {
+		return
+	}
+
+	writer.write(TRACE, args...)
+}
+
+// Tracef tracef
+func (writer *MMapWriter) Tracef(format string, args ...interface{}) {
+	if TRACE < writer.level {
+		return
+	}
+
+	writer.writef(TRACE, format, args...)
+}
+
+// Debug debug
+func (writer *MMapWriter) Debug(args ...interface{}) {
+	if DEBUG < writer.level {
+		return
+	}
+
+	writer.write(DEBUG, args...)
+}
+
+// Debugf debugf
+func (writer *MMapWriter) Debugf(format string, args ...interface{}) {
+	if DEBUG < writer.level {
+		return
+	}
+
+	writer.writef(DEBUG, format, args...)
+}
+
+// Info info
+func (writer *MMapWriter) Info(args ...interface{}) {
+	if INFO < writer.level {
+		return
+	}
+
+	writer.write(INFO, args...)
+}
+
+// Infof infof
+func (writer *MMapWriter) Infof(format string, args ...interface{}) {
+	if INFO < writer.level {
+		return
+	}
+
+	writer.writef(INFO, format, args...)
+}
+
+// Warn warn
+func (writer *MMapWriter) Warn(args ...interface{}) {
+	if WARNING < writer.level {
+		return
+	}
+
+	writer.write(WARNING, args...)
+}
+
+// Warnf warnf
+func (writer *MMapWriter) Warnf(format string, args ...interface{}) {
+	if WARNING < writer.level {
+		return
+	}
+
+	writer.writef(WARNING, format, args...)
+}
+
+// Error error
+func (writer *MMapWriter) Error(args ...interface{}) {
+	if ERROR < writer.level {
+		return
+	}
+
+	writer.write(ERROR, args...)
+}
+
+// Errorf errorf
+func (writer *MMapWriter) Errorf(format string, args ...interface{}) {
+	if ERROR < writer.level {
+		return
+	}
+
+	writer.writef(ERROR, format, args...)
+}
+
+// Critical critical
+func (writer *MMapWriter) Critical(args ...interface{}) {
+	if CRITICAL < writer.level {
+		return
+	}
+
+	writer.write(CRITICAL, args...)
+}
+
+// Criticalf criticalf
+func (writer *MMapWriter) Criticalf(format string, args ...interface{}) {
+	if CRITICAL < writer.level {
+		return
+	}
+
+	writer.writef(CRITICAL, format, args...)
+}
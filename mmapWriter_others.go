@@ -0,0 +1,20 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package blog4go
+
+import (
+	"os"
+)
+
+// mmap is not supported on this platform
+func mmap(file *os.File, size int) ([]byte, error) {
+	return nil, ErrNotSupported
+}
+
+// munmap is not supported on this platform
+func munmap(region []byte) error {
+	return ErrNotSupported
+}
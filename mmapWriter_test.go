@@ -0,0 +1,156 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMMapWriter(t *testing.T) {
+	if "windows" == runtime.GOOS {
+		if _, err := newMMapWriter("/tmp/mmap.log", 4096); ErrNotSupported != err {
+			t.Error("mmap should not be supported on windows")
+		}
+		return
+	}
+
+	if _, err := newMMapWriter("/tmp/mmap.log", 0); ErrInvalidMapSize != err {
+		t.Error("zero map size should fail")
+	}
+
+	defer func() {
+		os.Remove("/tmp/mmap.log")
+		os.Remove("/tmp/mmap.log.1")
+		os.Remove("/tmp/mmap.log.2")
+	}()
+
+	writer, err := newMMapWriter("/tmp/mmap.log", 4096)
+	if nil != err {
+		t.Fatalf("initialize mmap writer failed. err: %s", err.Error())
+	}
+	writer.SetRetentions(2)
+	if 2 != writer.Retentions() || 4096 != writer.RotateSize() {
+		t.Error("mmap writer settings wrong")
+	}
+
+	// larger than the region, dropped
+	writer.Info(strings.Repeat("x", 4096))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				writer.Infof("goroutine %d message %d", i, j)
+			}
+		}(i)
+	}
+	wg.Wait()
+	writer.Close()
+
+	lines := 0
+	for _, fileName := range []string{"/tmp/mmap.log", "/tmp/mmap.log.1"} {
+		data, err := ioutil.ReadFile(fileName)
+		if nil != err {
+			t.Fatalf("read %s failed. err: %s", fileName, err.Error())
+		}
+		if bytes.IndexByte(data, 0) >= 0 {
+			t.Errorf("%s should be truncated to size written", fileName)
+		}
+
+		for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+			if !strings.Contains(line, "] goroutine ") {
+				t.Errorf("mmap writer wrote wrong line: %q", line)
+			}
+			lines++
+		}
+	}
+	if 100 != lines {
+		t.Errorf("mmap writer lost messages. lines: %d", lines)
+	}
+
+	// not written after closed
+	writer.Info("closed")
+	if ErrWriterClosed != writer.PipeFrom(strings.NewReader(""), INFO) {
+		t.Error("pipe from closed writer should fail")
+	}
+}
+
+func TestMMapWriterRotate(t *testing.T) {
+	if "windows" == runtime.GOOS {
+		return
+	}
+
+	defer func() {
+		os.Remove("/tmp/mmap_rotate.log")
+		os.Remove("/tmp/mmap_rotate.log.1")
+		os.Remove("/tmp/mmap_rotate.log.2")
+	}()
+
+	// existing file is rotated before mapped
+	ioutil.WriteFile("/tmp/mmap_rotate.log", []byte("old\n"), 0644)
+
+	writer, err := newMMapWriter("/tmp/mmap_rotate.log", 256)
+	if nil != err {
+		t.Fatalf("initialize mmap writer failed. err: %s", err.Error())
+	}
+
+	hook := NewMyHook()
+	writer.SetHook(hook)
+	writer.SetHookAsync(false)
+	writer.SetHookLevel(ERROR)
+	writer.SetEOL(EOLWindows)
+	writer.AddMiddleware(func(level LevelType, message string) string {
+		return strings.ToUpper(message)
+	})
+
+	// do nothing operations
+	writer.SetColored(true)
+	writer.SetTimeRotated(true)
+	writer.SetRotateSize(1024)
+	writer.SetRotateLines(100)
+	if writer.Colored() || writer.TimeRotated() || 256 != writer.RotateSize() || 0 != writer.RotateLines() {
+		t.Error("mmap writer should ignore rotate settings")
+	}
+
+	writer.SetLevel(TRACE)
+	if TRACE != writer.Level() {
+		t.Error("mmap writer level wrong")
+	}
+
+	writer.BeginShutdown()
+	writer.flush()
+	writer.Trace("trace")
+	writer.Tracef("%s", "trace")
+	writer.Debug("debug")
+	writer.Debugf("%s", "debug")
+	writer.Info("info")
+	writer.Infof("%s", "info")
+	writer.Warn("warn")
+	writer.Warnf("%s", "warn")
+	writer.Error("error")
+	writer.Errorf("%s", "error")
+	writer.Critical("critical")
+	writer.Criticalf("%s", "critical")
+	if 4 != hook.Cnt() || "critical" != hook.Message() {
+		t.Errorf("hook called wrong. count: %d, message: %s", hook.Cnt(), hook.Message())
+	}
+	writer.Close()
+
+	if data, err := ioutil.ReadFile("/tmp/mmap_rotate.log.2"); nil != err || "old\n" != string(data) {
+		t.Error("existing file should be rotated")
+	}
+
+	data, err := ioutil.ReadFile("/tmp/mmap_rotate.log")
+	if nil != err || !strings.HasSuffix(string(data), fmt.Sprintf("] CRITICAL%s", EOLWindows)) {
+		t.Errorf("mmap writer wrote wrong messages. data: %q", data)
+	}
+}
@@ -0,0 +1,21 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package blog4go
+
+import (
+	"os"
+	"syscall"
+)
+
+// mmap maps size bytes of file for read and write, shared with other processes
+func mmap(file *os.File, size int) ([]byte, error) {
+	return syscall.Mmap(int(file.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+}
+
+// munmap unmaps region mapped by mmap
+func munmap(region []byte) error {
+	return syscall.Munmap(region)
+}
@@ -3,8 +3,10 @@
 package blog4go
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 )
 
 var (
@@ -18,6 +20,8 @@ var (
 
 // MultiWriter struct defines an instance for multi writers with different message level
 type MultiWriter struct {
+	writerBase
+
 	level LevelType
 
 	// file writers
@@ -34,6 +38,8 @@ type MultiWriter struct {
 	hookLevel LevelType
 	// it determines whether hook is called async, default true
 	hookAsync bool
+	// hooks added or removed on the fly, called with hook
+	hooks *HookManager
 
 	// logrotate
 	timeRotated bool
@@ -111,11 +117,64 @@ func (writer *MultiWriter) SetColored(colored bool) {
 	}
 }
 
+// SetEOL set end of every line
+func (writer *MultiWriter) SetEOL(eol []byte) {
+	for _, fileWriter := range writer.writers {
+		fileWriter.SetEOL(eol)
+	}
+}
+
+// AddMiddleware add a middleware applied to every message before written
+func (writer *MultiWriter) AddMiddleware(middleware Middleware) {
+	// writers or blogs of writers may be shared by levels, add only once
+	added := make(map[interface{}]bool)
+	for _, fileWriter := range writer.writers {
+		var key interface{} = fileWriter
+		if base, ok := fileWriter.(*baseFileWriter); ok {
+			key = base.blog
+		}
+		if added[key] {
+			continue
+		}
+
+		fileWriter.AddMiddleware(middleware)
+		added[key] = true
+	}
+}
+
 // SetHook set hook for every logging actions
 func (writer *MultiWriter) SetHook(hook Hook) {
 	writer.hook = hook
 }
 
+// AddHook add hook with id, called along with hook set by SetHook
+func (writer *MultiWriter) AddHook(id string, hook Hook) {
+	writer.hooks.AddHook(id, hook)
+}
+
+// RemoveHook remove hook with id
+func (writer *MultiWriter) RemoveHook(id string) {
+	writer.hooks.RemoveHook(id)
+}
+
+// ListHooks return sorted ids of hooks added
+func (writer *MultiWriter) ListHooks() []string {
+	return writer.hooks.ListHooks()
+}
+
+// hooksFired snapshots hooks to be called for logging action with level
+func (writer *MultiWriter) hooksFired(level LevelType) []Hook {
+	if level < writer.hookLevel {
+		return nil
+	}
+
+	hooks := writer.hooks.snapshot()
+	if nil != writer.hook {
+		hooks = append(hooks, writer.hook)
+	}
+	return hooks
+}
+
 // SetHookAsync set hook async for base file writer
 func (writer *MultiWriter) SetHookAsync(async bool) {
 	writer.hookAsync = async
@@ -150,38 +209,76 @@ func (writer *MultiWriter) Close() {
 func (writer *MultiWriter) write(level LevelType, args ...interface{}) {
 	defer func() {
 		// 异步调用log hook
-		if nil != writer.hook && !(level < writer.hookLevel) {
+		if hooks := writer.hooksFired(level); len(hooks) > 0 {
 			if writer.hookAsync {
-				go func(level LevelType, args ...interface{}) {
-					writer.hook.Fire(level, args...)
-				}(level, args...)
+				go fireHooks(hooks, level, args...)
+			} else {
+				fireHooks(hooks, level, args...)
+			}
+		}
+	}()
+
+	if levelWriter, ok := writer.writers[level]; ok {
+		levelWriter.write(level, args...)
+	}
+}
 
+func (writer *MultiWriter) writeBytes(level LevelType, message []byte) {
+	defer func() {
+		// 异步调用log hook
+		if hooks := writer.hooksFired(level); len(hooks) > 0 {
+			if writer.hookAsync {
+				go fireHooks(hooks, level, string(message))
 			} else {
-				writer.hook.Fire(level, args...)
+				fireHooks(hooks, level, string(message))
 			}
 		}
 	}()
 
-	writer.writers[level].write(level, args...)
+	levelWriter, ok := writer.writers[level]
+	if !ok {
+		return
+	}
+	if bytesLevelWriter, ok := levelWriter.(bytesWriter); ok {
+		bytesLevelWriter.writeBytes(level, message)
+	} else {
+		levelWriter.write(level, string(message))
+	}
 }
 
 func (writer *MultiWriter) writef(level LevelType, format string, args ...interface{}) {
 	defer func() {
 		// 异步调用log hook
-		if nil != writer.hook && !(level < writer.hookLevel) {
+		if hooks := writer.hooksFired(level); len(hooks) > 0 {
 			if writer.hookAsync {
-				go func(level LevelType, format string, args ...interface{}) {
-					writer.hook.Fire(level, fmt.Sprintf(format, args...))
-				}(level, format, args...)
-
+				go fireHooks(hooks, level, fmt.Sprintf(format, args...))
 			} else {
-				writer.hook.Fire(level, fmt.Sprintf(format, args...))
-
+				fireHooks(hooks, level, fmt.Sprintf(format, args...))
 			}
 		}
 	}()
 
-	writer.writers[level].writef(level, format, args...)
+	if levelWriter, ok := writer.writers[level]; ok {
+		levelWriter.writef(level, format, args...)
+	}
+}
+
+// PipeFromWithContext logs every line read from r with given level until r
+// reaches EOF or ctx is done
+func (writer *MultiWriter) PipeFromWithContext(ctx context.Context, r io.Reader, level LevelType) error {
+	if writer.closed {
+		return ErrWriterClosed
+	}
+
+	go pipe(ctx, writer, r, level)
+	return nil
+}
+
+// BeginShutdown makes every following write flushed synchronously
+func (writer *MultiWriter) BeginShutdown() {
+	for _, fileWriter := range writer.writers {
+		fileWriter.BeginShutdown()
+	}
 }
 
 // flush flush logs to disk
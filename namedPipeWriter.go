@@ -0,0 +1,386 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// NamedPipeWriter is a logger writing to a windows named pipe, like
+// \\.\pipe\name. It is not supported on other platforms.
+type NamedPipeWriter struct {
+	writerBase
+
+	// the pipe
+	pipe io.WriteCloser
+	blog *BLog
+
+	closed bool
+
+	// flush after every write when shutting down
+	shutdown bool
+
+	// log hook
+	hook      Hook
+	hookLevel LevelType
+	hookAsync bool
+
+	lock *sync.RWMutex
+}
+
+// NewNamedPipeWriter creates a named pipe writer, singlton
+func NewNamedPipeWriter(pipeName string) (err error) {
+	singltonLock.Lock()
+	defer singltonLock.Unlock()
+	if nil != blog {
+		return ErrAlreadyInit
+	}
+
+	namedPipeWriter, err := newNamedPipeWriter(pipeName)
+	if nil != err {
+		return err
+	}
+
+	blog = namedPipeWriter
+	return nil
+}
+
+// newNamedPipeWriter creates a named pipe writer, not singlton
+func newNamedPipeWriter(pipeName string) (namedPipeWriter *NamedPipeWriter, err error) {
+	pipe, err := openNamedPipe(pipeName)
+	if nil != err {
+		return nil, err
+	}
+
+	return newNamedPipeWriterFrom(pipe), nil
+}
+
+// newNamedPipeWriterFrom creates a named pipe writer writing to pipe opened
+func newNamedPipeWriterFrom(pipe io.WriteCloser) (namedPipeWriter *NamedPipeWriter) {
+	namedPipeWriter = new(NamedPipeWriter)
+	namedPipeWriter.self = namedPipeWriter
+	namedPipeWriter.pipe = pipe
+	namedPipeWriter.blog = NewBLog(pipe)
+	namedPipeWriter.closed = false
+	namedPipeWriter.lock = new(sync.RWMutex)
+
+	// log hook
+	namedPipeWriter.hook = nil
+	namedPipeWriter.hookLevel = DEBUG
+	namedPipeWriter.hookAsync = true
+
+	go namedPipeWriter.daemon()
+
+	return namedPipeWriter
+}
+
+// daemon flushes writer buffer every 1 second
+func (writer *NamedPipeWriter) daemon() {
+	f := time.Tick(1 * time.Second)
+
+DaemonLoop:
+	for {
+		select {
+		case <-f:
+			if writer.Closed() {
+				break DaemonLoop
+			}
+
+			writer.flush()
+		}
+	}
+}
+
+func (writer *NamedPipeWriter) write(level LevelType, args ...interface{}) {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+
+	if writer.closed {
+		return
+	}
+
+	defer func() {
+		// call log hook
+		if nil != writer.hook && !(level < writer.hookLevel) {
+			if writer.hookAsync {
+				go func(level LevelType, args ...interface{}) {
+					writer.hook.Fire(level, args...)
+				}(level, args...)
+
+			} else {
+				writer.hook.Fire(level, args...)
+			}
+		}
+	}()
+
+	writer.blog.write(level, args...)
+	if writer.shutdown {
+		writer.blog.flush()
+	}
+}
+
+func (writer *NamedPipeWriter) writef(level LevelType, format string, args ...interface{}) {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+
+	if writer.closed {
+		return
+	}
+
+	defer func() {
+		// call log hook
+		if nil != writer.hook && !(level < writer.hookLevel) {
+			if writer.hookAsync {
+				go func(level LevelType, format string, args ...interface{}) {
+					writer.hook.Fire(level, fmt.Sprintf(format, args...))
+				}(level, format, args...)
+
+			} else {
+				writer.hook.Fire(level, fmt.Sprintf(format, args...))
+			}
+		}
+	}()
+
+	writer.blog.writef(level, format, args...)
+	if writer.shutdown {
+		writer.blog.flush()
+	}
+}
+
+// Level get level
+func (writer *NamedPipeWriter) Level() LevelType {
+	return writer.blog.Level()
+}
+
+// SetLevel set logger level
+func (writer *NamedPipeWriter) SetLevel(level LevelType) {
+	writer.blog.SetLevel(level)
+}
+
+// SetHook set hook for logging action
+func (writer *NamedPipeWriter) SetHook(hook Hook) {
+	writer.hook = hook
+}
+
+// SetHookAsync set hook async for named pipe writer
+func (writer *NamedPipeWriter) SetHookAsync(async bool) {
+	writer.hookAsync = async
+}
+
+// SetHookLevel set when hook will be called
+func (writer *NamedPipeWriter) SetHookLevel(level LevelType) {
+	writer.hookLevel = level
+}
+
+// TimeRotated do nothing
+func (writer *NamedPipeWriter) TimeRotated() bool {
+	return false
+}
+
+// SetTimeRotated do nothing
+func (writer *NamedPipeWriter) SetTimeRotated(timeRotated bool) {
+	return
+}
+
+// Retentions do nothing
+func (writer *NamedPipeWriter) Retentions() int64 {
+	return 0
+}
+
+// SetRetentions do nothing
+func (writer *NamedPipeWriter) SetRetentions(retentions int64) {
+	return
+}
+
+// RotateSize do nothing
+func (writer *NamedPipeWriter) RotateSize() int64 {
+	return 0
+}
+
+// SetRotateSize do nothing
+func (writer *NamedPipeWriter) SetRotateSize(rotateSize int64) {
+	return
+}
+
+// RotateLines do nothing
+func (writer *NamedPipeWriter) RotateLines() int {
+	return 0
+}
+
+// SetRotateLines do nothing
+func (writer *NamedPipeWriter) SetRotateLines(rotateLines int) {
+	return
+}
+
+// Colored do nothing
+func (writer *NamedPipeWriter) Colored() bool {
+	return false
+}
+
+// SetColored do nothing
+func (writer *NamedPipeWriter) SetColored(colored bool) {
+	return
+}
+
+// SetEOL set end of every line
+func (writer *NamedPipeWriter) SetEOL(eol []byte) {
+	writer.blog.SetEOL(eol)
+}
+
+// AddMiddleware add a middleware applied to every message before written
+func (writer *NamedPipeWriter) AddMiddleware(middleware Middleware) {
+	writer.blog.AddMiddleware(middleware)
+}
+
+// Closed get writer status
+func (writer *NamedPipeWriter) Closed() bool {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.closed
+}
+
+// Close flushes buffer and closes the pipe
+func (writer *NamedPipeWriter) Close() {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if writer.closed {
+		return
+	}
+
+	writer.closed = true
+	writer.blog.Close()
+	writer.pipe.Close()
+}
+
+// PipeFromWithContext logs every line read from r with given level until r
+// reaches EOF or ctx is done
+func (writer *NamedPipeWriter) PipeFromWithContext(ctx context.Context, r io.Reader, level LevelType) error {
+	if writer.Closed() {
+		return ErrWriterClosed
+	}
+
+	go pipe(ctx, writer, r, level)
+	return nil
+}
+
+// BeginShutdown makes every following write flushed synchronously
+func (writer *NamedPipeWriter) BeginShutdown() {
+	writer.shutdown = true
+}
+
+// flush flush buffer to the pipe
+func (writer *NamedPipeWriter) flush() {
+	writer.blog.flush()
+}
+
+// Trace trace
+func (writer *NamedPipeWriter) Trace(args ...interface{}) {
+	if TRACE < writer.blog.Level() {
+		return
+	}
+
+	writer.write(TRACE, args...)
+}
+
+// Tracef tracef
+func (writer *NamedPipeWriter) Tracef(format string, args ...interface{}) {
+	if TRACE < writer.blog.Level() {
+		return
+	}
+
+	writer.writef(TRACE, format, args...)
+}
+
+// Debug debug
+func (writer *NamedPipeWriter) Debug(args ...interface{}) {
+	if DEBUG < writer.blog.Level() {
+		return
+	}
+
+	writer.write(DEBUG, args...)
+}
+
+// Debugf debugf
+func (writer *NamedPipeWriter) Debugf(format string, args ...interface{}) {
+	if DEBUG < writer.blog.Level() {
+		return
+	}
+
+	writer.writef(DEBUG, format, args...)
+}
+
+// Info info
+func (writer *NamedPipeWriter) Info(args ...interface{}) {
+	if INFO < writer.blog.Level() {
+		return
+	}
+
+	writer.write(INFO, args...)
+}
+
+// Infof infof
+func (writer *NamedPipeWriter) Infof(format string, args ...interface{}) {
+	if INFO < writer.blog.Level() {
+		return
+	}
+
+	writer.writef(INFO, format, args...)
+}
+
+// Warn warn
+func (writer *NamedPipeWriter) Warn(args ...interface{}) {
+	if WARNING < writer.blog.Level() {
+		return
+	}
+
+	writer.write(WARNING, args...)
+}
+
+// Warnf warnf
+func (writer *NamedPipeWriter) Warnf(format string, args ...interface{}) {
+	if WARNING < writer.blog.Level() {
+		return
+	}
+
+	writer.writef(WARNING, format, args...)
+}
+
+// Error error
+func (writer *NamedPipeWriter) Error(args ...interface{}) {
+	if ERROR < writer.blog.Level() {
+		return
+	}
+
+	writer.write(ERROR, args...)
+}
+
+// Errorf error
+func (writer *NamedPipeWriter) Errorf(format string, args ...interface{}) {
+	if ERROR < writer.blog.Level() {
+		return
+	}
+
+	writer.writef(ERROR, format, args...)
+}
+
+// Critical critical
+func (writer *NamedPipeWriter) Critical(args ...interface{}) {
+	if CRITICAL < writer.blog.Level() {
+		return
+	}
+
+	writer.write(CRITICAL, args...)
+}
+
+// Criticalf criticalf
+func (writer *NamedPipeWriter) Criticalf(format string, args ...interface{}) {
+	if CRITICAL < writer.blog.Level() {
+		return
+	}
+
+	writer.writef(CRITICAL, format, args...)
+}
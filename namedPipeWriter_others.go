@@ -0,0 +1,15 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+//go:build !windows
+// +build !windows
+
+package blog4go
+
+import (
+	"io"
+)
+
+// openNamedPipe named pipe is supported only on windows
+func openNamedPipe(pipeName string) (pipe io.WriteCloser, err error) {
+	return nil, ErrNotSupported
+}
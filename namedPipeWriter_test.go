@@ -0,0 +1,105 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bufio"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestNamedPipeWriter(t *testing.T) {
+	if "windows" != runtime.GOOS {
+		if _, err := newNamedPipeWriter(`\\.\pipe\blog4go`); ErrNotSupported != err {
+			t.Error("named pipe should not be supported on other platforms")
+		}
+	}
+
+	r, w, err := os.Pipe()
+	if nil != err {
+		t.Fatalf("create pipe failed. err: %s", err.Error())
+	}
+	defer r.Close()
+
+	writer := newNamedPipeWriterFrom(w)
+	writer.SetLevel(INFO)
+	writer.Debug("ignored")
+	writer.Info("piped")
+	writer.Errorf("%s", "piped")
+	writer.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	if 2 != len(lines) || !strings.HasSuffix(lines[0], "] piped") || !strings.HasSuffix(lines[1], "] piped") {
+		t.Errorf("named pipe writer wrote wrong messages. lines: %v", lines)
+	}
+
+	if ErrWriterClosed != writer.PipeFrom(strings.NewReader(""), INFO) {
+		t.Error("pipe from closed writer should fail")
+	}
+}
+
+func TestNamedPipeWriterOperations(t *testing.T) {
+	r, w, err := os.Pipe()
+	if nil != err {
+		t.Fatalf("create pipe failed. err: %s", err.Error())
+	}
+	defer r.Close()
+
+	writer := newNamedPipeWriterFrom(w)
+
+	hook := NewMyHook()
+	writer.SetHook(hook)
+	writer.SetHookAsync(false)
+	writer.SetHookLevel(ERROR)
+	writer.SetEOL(EOLWindows)
+	writer.AddMiddleware(func(level LevelType, message string) string {
+		return strings.ToUpper(message)
+	})
+
+	// do nothing operations
+	writer.SetColored(true)
+	writer.SetTimeRotated(true)
+	writer.SetRetentions(7)
+	writer.SetRotateSize(1024)
+	writer.SetRotateLines(100)
+	if writer.Colored() || writer.TimeRotated() || 0 != writer.Retentions() || 0 != writer.RotateSize() || 0 != writer.RotateLines() {
+		t.Error("named pipe writer should ignore file settings")
+	}
+
+	writer.SetLevel(TRACE)
+	if TRACE != writer.Level() {
+		t.Error("named pipe writer level wrong")
+	}
+
+	writer.BeginShutdown()
+	writer.Trace("trace")
+	writer.Tracef("%s", "trace")
+	writer.Debugf("%s", "debug")
+	writer.Infof("%s", "info")
+	writer.Warn("warn")
+	writer.Warnf("%s", "warn")
+	writer.Error("error")
+	writer.Critical("critical")
+	writer.Criticalf("%s", "critical")
+	if 3 != hook.Cnt() || "critical" != hook.Message() {
+		t.Errorf("hook called wrong. count: %d, message: %s", hook.Cnt(), hook.Message())
+	}
+	writer.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	if 9 != len(lines) || !strings.HasSuffix(lines[0], "] TRACE") || !strings.HasSuffix(lines[8], "] CRITICAL") {
+		t.Errorf("named pipe writer wrote wrong messages. lines: %q", lines)
+	}
+}
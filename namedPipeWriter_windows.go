@@ -0,0 +1,46 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+//go:build windows
+// +build windows
+
+package blog4go
+
+import (
+	"io"
+	"os"
+	"syscall"
+	"time"
+)
+
+const (
+	// errorPipeBusy ERROR_PIPE_BUSY, all instances of the pipe are busy
+	errorPipeBusy = syscall.Errno(231)
+
+	// times to retry opening a busy pipe
+	namedPipeRetries = 10
+	// interval between retries opening a busy pipe
+	namedPipeRetryInterval = 100 * time.Millisecond
+)
+
+// openNamedPipe opens the client end of a named pipe for writing.
+// It retries if all instances of the pipe are busy.
+func openNamedPipe(pipeName string) (pipe io.WriteCloser, err error) {
+	name, err := syscall.UTF16PtrFromString(pipeName)
+	if nil != err {
+		return nil, err
+	}
+
+	var handle syscall.Handle
+	for i := 0; i < namedPipeRetries; i++ {
+		handle, err = syscall.CreateFile(name, syscall.GENERIC_WRITE, 0, nil, syscall.OPEN_EXISTING, 0, 0)
+		if errorPipeBusy != err {
+			break
+		}
+		time.Sleep(namedPipeRetryInterval)
+	}
+	if nil != err {
+		return nil, &os.PathError{Op: "open", Path: pipeName, Err: err}
+	}
+
+	return os.NewFile(uintptr(handle), pipeName), nil
+}
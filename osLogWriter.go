@@ -0,0 +1,345 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// OSLogWriter is a logger writing to macOS unified logging system with
+// os_log, messages can be viewed in Console.app or by log stream.
+// Levels are mapped to os_log types, TRACE and DEBUG to OS_LOG_TYPE_DEBUG,
+// INFO to OS_LOG_TYPE_INFO, WARNING to OS_LOG_TYPE_DEFAULT, ERROR to
+// OS_LOG_TYPE_ERROR and CRITICAL to OS_LOG_TYPE_FAULT.
+// It is supported only on darwin with cgo enabled.
+type OSLogWriter struct {
+	writerBase
+
+	level LevelType
+
+	closed bool
+
+	// log hook
+	hook      Hook
+	hookLevel LevelType
+	hookAsync bool
+
+	// middlewares applied to message before written
+	middlewareList
+
+	// log object created by os_log_create
+	log *osLog
+
+	lock *sync.Mutex
+}
+
+// NewOSLogWriter creates an os_log writer with subsystem, like
+// com.example.app, and category, singlton
+func NewOSLogWriter(subsystem, category string) (err error) {
+	singltonLock.Lock()
+	defer singltonLock.Unlock()
+	if nil != blog {
+		return ErrAlreadyInit
+	}
+
+	osLogWriter, err := newOSLogWriter(subsystem, category)
+	if nil != err {
+		return err
+	}
+
+	blog = osLogWriter
+	return nil
+}
+
+// newOSLogWriter creates an os_log writer, not singlton
+func newOSLogWriter(subsystem, category string) (osLogWriter *OSLogWriter, err error) {
+	log, err := openOSLog(subsystem, category)
+	if nil != err {
+		return nil, err
+	}
+
+	osLogWriter = new(OSLogWriter)
+	osLogWriter.self = osLogWriter
+	osLogWriter.level = DEBUG
+	osLogWriter.closed = false
+	osLogWriter.log = log
+	osLogWriter.lock = new(sync.Mutex)
+
+	// log hook
+	osLogWriter.hook = nil
+	osLogWriter.hookLevel = DEBUG
+	osLogWriter.hookAsync = true
+
+	return osLogWriter, nil
+}
+
+// push applies middlewares to message and writes it to os_log
+func (writer *OSLogWriter) push(level LevelType, message string) {
+	message = writer.applyMiddlewares(level, message)
+
+	writer.log.write(level, message)
+}
+
+func (writer *OSLogWriter) write(level LevelType, args ...interface{}) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	if writer.closed {
+		return
+	}
+
+	defer func() {
+		// call log hook
+		if nil != writer.hook && !(level < writer.hookLevel) {
+			if writer.hookAsync {
+				go func(level LevelType, args ...interface{}) {
+					writer.hook.Fire(level, args...)
+				}(level, args...)
+
+			} else {
+				writer.hook.Fire(level, args...)
+			}
+		}
+	}()
+
+	writer.push(level, fmt.Sprint(args...))
+}
+
+func (writer *OSLogWriter) writef(level LevelType, format string, args ...interface{}) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	if writer.closed {
+		return
+	}
+
+	defer func() {
+		// call log hook
+		if nil != writer.hook && !(level < writer.hookLevel) {
+			if writer.hookAsync {
+				go func(level LevelType, format string, args ...interface{}) {
+					writer.hook.Fire(level, fmt.Sprintf(format, args...))
+				}(level, format, args...)
+
+			} else {
+				writer.hook.Fire(level, fmt.Sprintf(format, args...))
+			}
+		}
+	}()
+
+	writer.push(level, fmt.Sprintf(format, args...))
+}
+
+// Level get level
+func (writer *OSLogWriter) Level() LevelType {
+	return writer.level
+}
+
+// SetLevel set logger level
+func (writer *OSLogWriter) SetLevel(level LevelType) {
+	writer.level = level
+}
+
+// SetHook set hook for logging action
+func (writer *OSLogWriter) SetHook(hook Hook) {
+	writer.hook = hook
+}
+
+// SetHookAsync set hook async for os_log writer
+func (writer *OSLogWriter) SetHookAsync(async bool) {
+	writer.hookAsync = async
+}
+
+// SetHookLevel set when hook will be called
+func (writer *OSLogWriter) SetHookLevel(level LevelType) {
+	writer.hookLevel = level
+}
+
+// TimeRotated do nothing
+func (writer *OSLogWriter) TimeRotated() bool {
+	return false
+}
+
+// SetTimeRotated do nothing
+func (writer *OSLogWriter) SetTimeRotated(timeRotated bool) {
+	return
+}
+
+// Retentions do nothing
+func (writer *OSLogWriter) Retentions() int64 {
+	return 0
+}
+
+// SetRetentions do nothing
+func (writer *OSLogWriter) SetRetentions(retentions int64) {
+	return
+}
+
+// RotateSize do nothing
+func (writer *OSLogWriter) RotateSize() int64 {
+	return 0
+}
+
+// SetRotateSize do nothing
+func (writer *OSLogWriter) SetRotateSize(rotateSize int64) {
+	return
+}
+
+// RotateLines do nothing
+func (writer *OSLogWriter) RotateLines() int {
+	return 0
+}
+
+// SetRotateLines do nothing
+func (writer *OSLogWriter) SetRotateLines(rotateLines int) {
+	return
+}
+
+// Colored do nothing
+func (writer *OSLogWriter) Colored() bool {
+	return false
+}
+
+// SetColored do nothing
+func (writer *OSLogWriter) SetColored(colored bool) {
+	return
+}
+
+// PipeFromWithContext logs every line read from r with given level until r
+// reaches EOF or ctx is done
+func (writer *OSLogWriter) PipeFromWithContext(ctx context.Context, r io.Reader, level LevelType) error {
+	if writer.closed {
+		return ErrWriterClosed
+	}
+
+	go pipe(ctx, writer, r, level)
+	return nil
+}
+
+// Close releases the log object
+func (writer *OSLogWriter) Close() {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if writer.closed {
+		return
+	}
+
+	writer.closed = true
+	writer.log.close()
+}
+
+// flush do nothing, messages are written to os_log at once
+func (writer *OSLogWriter) flush() {
+	return
+}
+
+// Trace trace
+func (writer *OSLogWriter) Trace(args ...interface{}) {
+	if TRACE < writer.level {
+		return
+	}
+
+	writer.write(TRACE, args...)
+}
+
+// Tracef tracef
+func (writer *OSLogWriter) Tracef(format string, args ...interface{}) {
+	if TRACE < writer.level {
+		return
+	}
+
+	writer.writef(TRACE, format, args...)
+}
+
+// Debug debug
+func (writer *OSLogWriter) Debug(args ...interface{}) {
+	if DEBUG < writer.level {
+		return
+	}
+
+	writer.write(DEBUG, args...)
+}
+
+// Debugf debugf
+func (writer *OSLogWriter) Debugf(format string, args ...interface{}) {
+	if DEBUG < writer.level {
+		return
+	}
+
+	writer.writef(DEBUG, format, args...)
+}
+
+// Info info
+func (writer *OSLogWriter) Info(args ...interface{}) {
+	if INFO < writer.level {
+		return
+	}
+
+	writer.write(INFO, args...)
+}
+
+// Infof infof
+func (writer *OSLogWriter) Infof(format string, args ...interface{}) {
+	if INFO < writer.level {
+		return
+	}
+
+	writer.writef(INFO, format, args...)
+}
+
+// Warn warn
+func (writer *OSLogWriter) Warn(args ...interface{}) {
+	if WARNING < writer.level {
+		return
+	}
+
+	writer.write(WARNING, args...)
+}
+
+// Warnf warnf
+func (writer *OSLogWriter) Warnf(format string, args ...interface{}) {
+	if WARNING < writer.level {
+		return
+	}
+
+	writer.writef(WARNING, format, args...)
+}
+
+// Error error
+func (writer *OSLogWriter) Error(args ...interface{}) {
+	if ERROR < writer.level {
+		return
+	}
+
+	writer.write(ERROR, args...)
+}
+
+// Errorf errorf
+func (writer *OSLogWriter) Errorf(format string, args ...interface{}) {
+	if ERROR < writer.level {
+		return
+	}
+
+	writer.writef(ERROR, format, args...)
+}
+
+// Critical critical
+func (writer *OSLogWriter) Critical(args ...interface{}) {
+	if CRITICAL < writer.level {
+		return
+	}
+
+	writer.write(CRITICAL, args...)
+}
+
+// Criticalf criticalf
+func (writer *OSLogWriter) Criticalf(format string, args ...interface{}) {
+	if CRITICAL < writer.level {
+		return
+	}
+
+	writer.writef(CRITICAL, format, args...)
+}
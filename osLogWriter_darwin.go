@@ -0,0 +1,66 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+//go:build darwin && cgo
+// +build darwin,cgo
+
+package blog4go
+
+/*
+#include <stdlib.h>
+#include <os/log.h>
+
+// os_log_with_type requires a constant format string
+static void blog4go_os_log(os_log_t log, os_log_type_t type, const char *message) {
+	os_log_with_type(log, type, "%{public}s", message);
+}
+
+static void blog4go_os_release(os_log_t log) {
+	os_release(log);
+}
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+// osLog is a log object created by os_log_create
+type osLog struct {
+	log C.os_log_t
+}
+
+// openOSLog creates a log object with subsystem and category
+func openOSLog(subsystem, category string) (*osLog, error) {
+	cSubsystem := C.CString(subsystem)
+	defer C.free(unsafe.Pointer(cSubsystem))
+	cCategory := C.CString(category)
+	defer C.free(unsafe.Pointer(cCategory))
+
+	return &osLog{log: C.os_log_create(cSubsystem, cCategory)}, nil
+}
+
+// write writes message with os_log type mapped from level
+func (log *osLog) write(level LevelType, message string) {
+	var logType C.os_log_type_t
+	switch level {
+	case TRACE, DEBUG:
+		logType = C.OS_LOG_TYPE_DEBUG
+	case INFO:
+		logType = C.OS_LOG_TYPE_INFO
+	case ERROR:
+		logType = C.OS_LOG_TYPE_ERROR
+	case CRITICAL:
+		logType = C.OS_LOG_TYPE_FAULT
+	default:
+		logType = C.OS_LOG_TYPE_DEFAULT
+	}
+
+	cMessage := C.CString(message)
+	defer C.free(unsafe.Pointer(cMessage))
+	C.blog4go_os_log(log.log, logType, cMessage)
+}
+
+// close releases the log object
+func (log *osLog) close() {
+	C.blog4go_os_release(log.log)
+}
@@ -0,0 +1,24 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+//go:build !darwin || !cgo
+// +build !darwin !cgo
+
+package blog4go
+
+// osLog os_log is supported only on darwin with cgo enabled
+type osLog struct{}
+
+// openOSLog os_log is supported only on darwin with cgo enabled
+func openOSLog(subsystem, category string) (*osLog, error) {
+	return nil, ErrNotSupported
+}
+
+// write do nothing
+func (log *osLog) write(level LevelType, message string) {
+	return
+}
+
+// close do nothing
+func (log *osLog) close() {
+	return
+}
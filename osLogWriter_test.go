@@ -0,0 +1,48 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestOSLogWriter(t *testing.T) {
+	writer, err := newOSLogWriter("com.github.blog4go", "test")
+	if "darwin" != runtime.GOOS {
+		if ErrNotSupported != err {
+			t.Error("os_log should not be supported on other platforms")
+		}
+		return
+	}
+	if ErrNotSupported == err {
+		// built without cgo
+		return
+	}
+
+	hook := NewMyHook()
+	writer.SetHook(hook)
+	writer.SetHookAsync(false)
+	writer.SetHookLevel(ERROR)
+	writer.AddMiddleware(func(level LevelType, message string) string {
+		return strings.ToUpper(message)
+	})
+
+	writer.SetLevel(TRACE)
+	writer.Trace("trace")
+	writer.Debugf("%s", "debug")
+	writer.Info("info")
+	writer.Warnf("%s", "warn")
+	writer.Error("error")
+	writer.Criticalf("%s", "critical")
+	if 2 != hook.Cnt() || "critical" != hook.Message() {
+		t.Errorf("hook called wrong. count: %d, message: %s", hook.Cnt(), hook.Message())
+	}
+
+	writer.Close()
+	writer.Close()
+	if ErrWriterClosed != writer.PipeFrom(strings.NewReader(""), INFO) {
+		t.Error("pipe from closed writer should fail")
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// maxColorSize is the max size of ANSI color escape sequence recognized
+	// ahead of timestamp
+	maxColorSize = 16
+)
+
+// Entry is a logging record decoded from log written by blog4go
+type Entry struct {
+	// time when the message was written
+	Time time.Time
+	// logging level of the message, invalid if level prefix not found
+	Level LevelType
+	// message body, lines are joined with EOL for multi-line message
+	Message string
+	// sign of annotation line written by Annotate, Message is the annotation
+	Annotation bool
+	// file:line of the caller, only set by ChannelWriter
+	Caller string
+
+	// sign of full line colored, color is reset at the end of the last line
+	colored bool
+}
+
+// Parser decodes log stream written by blog4go into entries.
+// Lines not starting with a timestamp, like stack traces, are grouped into
+// the message of the previous entry.
+type Parser struct {
+	// lines are read without limit of size, like long messages written by
+	// writers without max message size
+	reader *bufio.Reader
+
+	// entry already read ahead while looking for continuation lines
+	pending *Entry
+
+	// text around %s in format of annotation lines
+	annotationPrefix string
+	annotationSuffix string
+
+	// schema version of the last header line read, 0 if none
+	schemaVersion int
+}
+
+// NewParser create a parser reading log stream from r
+func NewParser(r io.Reader) *Parser {
+	parser := new(Parser)
+	parser.reader = bufio.NewReader(r)
+	parser.SetAnnotationFormat(DefaultAnnotationFormat)
+	return parser
+}
+
+// SetAnnotationFormat set format of annotation lines to recognize, the same
+// as the one set to writer
+func (parser *Parser) SetAnnotationFormat(format string) error {
+	if !validAnnotationFormat(format) {
+		return ErrInvalidFormat
+	}
+
+	i := strings.Index(format, "%s")
+	parser.annotationPrefix = format[:i]
+	parser.annotationSuffix = format[i+2:]
+	return nil
+}
+
+// SchemaVersion return schema version in the last header line read, 0 if
+// no header line read, like files written by older versions
+func (parser *Parser) SchemaVersion() int {
+	return parser.schemaVersion
+}
+
+// Next return the next entry in the log stream.
+// io.EOF will be returned at the end of the stream.
+func (parser *Parser) Next() (*Entry, error) {
+	entry := parser.pending
+	parser.pending = nil
+
+	for {
+		line, err := parser.reader.ReadString('\n')
+		if nil != err && io.EOF != err {
+			return nil, err
+		}
+		if "" == line {
+			break
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		// header line of a log file, log stream may be concatenated files
+		if version, ok := parseSchemaHeader(line); ok {
+			parser.schemaVersion = version
+			continue
+		}
+
+		next, ok := parser.parseLine(line)
+		if !ok {
+			// continuation line
+			if nil == entry {
+				entry = &Entry{Level: LevelType(-1), Message: line}
+			} else {
+				entry.appendLine(line)
+			}
+			continue
+		}
+
+		if nil == entry {
+			entry = next
+			continue
+		}
+
+		parser.pending = next
+		return entry, nil
+	}
+
+	if nil == entry {
+		return nil, io.EOF
+	}
+	return entry, nil
+}
+
+// parseLine decodes a line starting with a timestamp into an entry.
+// false will be returned if the line does not start with a timestamp.
+func (parser *Parser) parseLine(line string) (*Entry, bool) {
+	// color of level ahead if full line colored
+	color := leadingColor(line)
+	line = line[len(color):]
+
+	if len(line) < len(PrefixTimeFormat) {
+		return nil, false
+	}
+
+	t, err := time.ParseInLocation(PrefixTimeFormat, line[:len(PrefixTimeFormat)], time.Local)
+	if nil != err {
+		return nil, false
+	}
+
+	entry := &Entry{Time: t, Level: LevelType(-1)}
+	rest := line[len(PrefixTimeFormat):]
+
+	// level prefix, may be colored
+	if strings.HasPrefix(rest, " [") {
+		if end := strings.Index(rest, "] "); end > 0 {
+			if level := LevelFromString(stripColor(rest[2:end])); level.valid() {
+				entry.Level = level
+				entry.Message = rest[end+2:]
+				if "" != color {
+					entry.colored = true
+					entry.Message = strings.TrimSuffix(strings.TrimPrefix(entry.Message, color), colorReset)
+				}
+				return entry, true
+			}
+		}
+	}
+
+	entry.Message = strings.TrimPrefix(rest, " ")
+
+	// annotation line
+	if len(entry.Message) >= len(parser.annotationPrefix)+len(parser.annotationSuffix) &&
+		strings.HasPrefix(entry.Message, parser.annotationPrefix) && strings.HasSuffix(entry.Message, parser.annotationSuffix) {
+		entry.Annotation = true
+		entry.Message = entry.Message[len(parser.annotationPrefix) : len(entry.Message)-len(parser.annotationSuffix)]
+	}
+	return entry, true
+}
+
+// appendLine joins continuation line to message
+func (entry *Entry) appendLine(line string) {
+	if entry.colored {
+		line = strings.TrimSuffix(line, colorReset)
+	}
+	entry.Message += string(EOL) + line
+}
+
+// leadingColor return the ANSI color escape sequence line starts with, like
+// lines full line colored, empty if none
+func leadingColor(line string) string {
+	if !strings.HasPrefix(line, "\x1b[") {
+		return ""
+	}
+
+	end := strings.IndexByte(line, 'm')
+	if end < 0 || end >= maxColorSize {
+		return ""
+	}
+	return line[:end+1]
+}
+
+// stripColor removes ANSI color escape sequences in str
+func stripColor(str string) string {
+	for {
+		begin := strings.Index(str, "\x1b[")
+		if begin < 0 {
+			return str
+		}
+
+		end := strings.IndexByte(str[begin:], 'm')
+		if end < 0 {
+			return str
+		}
+		str = str[:begin] + str[begin+end+1:]
+	}
+}
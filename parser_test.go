@@ -0,0 +1,176 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParser(t *testing.T) {
+	stream := "[2017/06/30:12:00:00] [INFO] first message\n" +
+		"[2017/06/30:12:00:01] [\x1b[31mERROR\x1b[0m] panic: something wrong\n" +
+		"goroutine 1 [running]:\n" +
+		"main.main()\n" +
+		"[2017/06/30:12:00:02] [WARN] last message\n"
+
+	parser := NewParser(strings.NewReader(stream))
+
+	entry, err := parser.Next()
+	if nil != err {
+		t.Fatalf("parse first entry failed. err: %s", err.Error())
+	}
+	if INFO != entry.Level || "first message" != entry.Message {
+		t.Errorf("first entry parsed wrong. level: %s, message: %s", entry.Level.String(), entry.Message)
+	}
+	if !entry.Time.Equal(time.Date(2017, 6, 30, 12, 0, 0, 0, time.Local)) {
+		t.Errorf("first entry time parsed wrong. time: %s", entry.Time)
+	}
+
+	entry, err = parser.Next()
+	if nil != err {
+		t.Fatalf("parse second entry failed. err: %s", err.Error())
+	}
+	if ERROR != entry.Level || "panic: something wrong\ngoroutine 1 [running]:\nmain.main()" != entry.Message {
+		t.Errorf("multi-line entry parsed wrong. level: %s, message: %s", entry.Level.String(), entry.Message)
+	}
+
+	entry, err = parser.Next()
+	if nil != err {
+		t.Fatalf("parse last entry failed. err: %s", err.Error())
+	}
+	if WARNING != entry.Level || "last message" != entry.Message {
+		t.Errorf("last entry parsed wrong. level: %s, message: %s", entry.Level.String(), entry.Message)
+	}
+
+	if _, err = parser.Next(); io.EOF != err {
+		t.Error("parser should return io.EOF at the end of stream")
+	}
+}
+
+func TestParserLongLine(t *testing.T) {
+	long := strings.Repeat("x", 100*1024)
+	stream := "[2017/06/30:12:00:00] [INFO] " + long + "\r\n" +
+		"[2017/06/30:12:00:01] [WARN] after long line"
+
+	parser := NewParser(strings.NewReader(stream))
+	entry, err := parser.Next()
+	if nil != err {
+		t.Fatalf("parse long entry failed. err: %s", err.Error())
+	}
+	if INFO != entry.Level || long != entry.Message {
+		t.Errorf("long entry parsed wrong. level: %s, size: %d", entry.Level.String(), len(entry.Message))
+	}
+
+	entry, err = parser.Next()
+	if nil != err || WARNING != entry.Level || "after long line" != entry.Message {
+		t.Errorf("entry after long line parsed wrong. entry: %+v, err: %v", entry, err)
+	}
+	if _, err = parser.Next(); io.EOF != err {
+		t.Errorf("end of stream wrong. err: %v", err)
+	}
+}
+
+func TestParserWithoutLevel(t *testing.T) {
+	parser := NewParser(strings.NewReader("orphan line\n[2017/06/30:12:00:00] no level\n"))
+
+	entry, err := parser.Next()
+	if nil != err || "orphan line" != entry.Message || entry.Level.valid() {
+		t.Error("orphan continuation line parsed wrong")
+	}
+
+	entry, err = parser.Next()
+	if nil != err || "no level" != entry.Message || entry.Level.valid() {
+		t.Error("entry without level parsed wrong")
+	}
+}
+
+func TestParserAnnotation(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/annotation.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/annotation.log")
+	}()
+
+	if ErrInvalidFormat != writer.SetAnnotationFormat("%s %d") {
+		t.Error("annotation format with other placeholders should fail")
+	}
+
+	writer.SetLevel(CRITICAL)
+	writer.Annotate("starting TestFoo")
+	writer.Info("filtered")
+	writer.SetAnnotationFormat("## %s")
+	writer.Annotate("finished TestFoo")
+	writer.flush()
+
+	file, err := os.Open("/tmp/annotation.log")
+	if nil != err {
+		t.Fatalf("open log failed. err: %s", err.Error())
+	}
+	defer file.Close()
+
+	parser := NewParser(file)
+	entry, err := parser.Next()
+	if nil != err || !entry.Annotation || "starting TestFoo" != entry.Message {
+		t.Errorf("annotation parsed wrong. entry: %+v", entry)
+	}
+
+	// annotation with format not set to parser is a plain entry
+	entry, err = parser.Next()
+	if nil != err || entry.Annotation || "## finished TestFoo" != entry.Message {
+		t.Errorf("annotation with another format parsed wrong. entry: %+v", entry)
+	}
+
+	if _, err = parser.Next(); io.EOF != err {
+		t.Error("parser should return io.EOF at the end of stream")
+	}
+
+	parser = NewParser(strings.NewReader("[2017/06/30:12:00:00] ## finished\n"))
+	if ErrInvalidFormat != parser.SetAnnotationFormat("no placeholder") {
+		t.Error("annotation format without placeholder should fail")
+	}
+	parser.SetAnnotationFormat("## %s")
+	if entry, err = parser.Next(); nil != err || !entry.Annotation || "finished" != entry.Message {
+		t.Errorf("annotation parsed wrong with format. entry: %+v", entry)
+	}
+}
+
+func TestParserFullLineColor(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	blog := NewBLog(buffer)
+	blog.SetFullLineColor(true)
+
+	initPrefix(true)
+	defer initPrefix(false)
+	blog.write(ERROR, "panic: something wrong\ngoroutine 1 [running]:")
+	blog.writef(INFO, "user %s", "tom")
+	blog.flush()
+
+	parser := NewParser(bytes.NewReader(buffer.Bytes()))
+	entry, err := parser.Next()
+	if nil != err {
+		t.Fatalf("parse first entry failed. err: %s", err.Error())
+	}
+	if ERROR != entry.Level || "panic: something wrong\ngoroutine 1 [running]:" != entry.Message {
+		t.Errorf("first entry parsed wrong. level: %s, message: %q", entry.Level.String(), entry.Message)
+	}
+
+	entry, err = parser.Next()
+	if nil != err {
+		t.Fatalf("parse second entry failed. err: %s", err.Error())
+	}
+	if INFO != entry.Level || "user tom" != entry.Message {
+		t.Errorf("second entry parsed wrong. level: %s, message: %q", entry.Level.String(), entry.Message)
+	}
+
+	if _, ok := lineTime(buffer.Bytes()); !ok {
+		t.Error("timestamp of full line colored line should be recognized")
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// pipeGuard is implemented by writers whose Close must not run while a line
+// piped is being written
+type pipeGuard interface {
+	// lockPipe return false if the writer is closed, lock is held otherwise
+	lockPipe() bool
+	unlockPipe()
+}
+
+// pipe reads r line by line and logs every line with specific level.
+// It returns when r reaches EOF, reading fails or ctx is done. If r is also
+// an io.Closer, r will be closed when ctx is done so that a blocking read
+// returns, or when the writer is closed. Read errors other than EOF are
+// written as a warning, regardless of level.
+func pipe(ctx context.Context, writer Writer, r io.Reader, level LevelType) {
+	closer, closable := r.(io.Closer)
+	if closable {
+		done := make(chan struct{})
+		defer close(done)
+
+		go func() {
+			select {
+			case <-ctx.Done():
+				closer.Close()
+			case <-done:
+			}
+		}()
+	}
+
+	guard, guarded := writer.(pipeGuard)
+	// sign of the writer closed while piping
+	stopped := false
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if nil != ctx.Err() {
+			break
+		}
+		if guarded && !guard.lockPipe() {
+			stopped = true
+			break
+		}
+
+		if "" != line {
+			logWithLevel(writer, level, strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"))
+		}
+		if nil != err && io.EOF != err {
+			writer.write(WARNING, fmt.Sprintf("blog4go: pipe stopped. err: %s", err.Error()))
+		}
+
+		if guarded {
+			guard.unlockPipe()
+		}
+		if nil != err {
+			break
+		}
+	}
+
+	// ensure r is closed if pipe ends by ctx or the writer closed
+	if closable && (stopped || nil != ctx.Err()) {
+		closer.Close()
+	}
+}
+
+// logWithLevel dispatch message to the logging function of specific level,
+// so that level filtering of the writer is respected
+func logWithLevel(writer Writer, level LevelType, args ...interface{}) {
+	switch level {
+	case TRACE:
+		writer.Trace(args...)
+	case DEBUG:
+		writer.Debug(args...)
+	case INFO:
+		writer.Info(args...)
+	case WARNING:
+		writer.Warn(args...)
+	case ERROR:
+		writer.Error(args...)
+	case CRITICAL:
+		writer.Critical(args...)
+	default:
+		// level registered by RegisterLevel
+		if level.valid() && !(level < writer.Level()) {
+			writer.write(level, args...)
+		}
+	}
+}
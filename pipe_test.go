@@ -0,0 +1,155 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// eofReader closes eof once r reaches EOF, lines read before are written
+// by then
+type eofReader struct {
+	r   io.Reader
+	eof chan struct{}
+}
+
+func newEOFReader(r io.Reader) *eofReader {
+	return &eofReader{r: r, eof: make(chan struct{})}
+}
+
+func (reader *eofReader) Read(p []byte) (int, error) {
+	n, err := reader.r.Read(p)
+	if io.EOF == err {
+		close(reader.eof)
+	}
+	return n, err
+}
+
+// closeNotifyReader closes closed once the pipe reader is closed
+type closeNotifyReader struct {
+	*io.PipeReader
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newCloseNotifyReader() (*closeNotifyReader, *io.PipeWriter) {
+	r, w := io.Pipe()
+	return &closeNotifyReader{PipeReader: r, closed: make(chan struct{})}, w
+}
+
+func (reader *closeNotifyReader) Close() error {
+	err := reader.PipeReader.Close()
+	reader.once.Do(func() { close(reader.closed) })
+	return err
+}
+
+func TestPipeFrom(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/pipe.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer os.Remove("/tmp/pipe.log")
+
+	// longer than the default token size of bufio.Scanner
+	long := strings.Repeat("x", 100*1024)
+
+	writer.SetLevel(INFO)
+	// hook is called after every line written
+	hook := NewMyHook()
+	writer.SetHook(hook)
+	writer.SetHookAsync(false)
+
+	lines := newEOFReader(strings.NewReader("first line\r\nsecond line\n" + long + "\nlast line"))
+	if err = writer.PipeFrom(lines, INFO); nil != err {
+		t.Errorf("pipe from reader failed. err: %s", err.Error())
+	}
+	// filtered by level
+	filtered := newEOFReader(strings.NewReader("debug line\n"))
+	if err = writer.PipeFrom(filtered, DEBUG); nil != err {
+		t.Errorf("pipe from reader failed. err: %s", err.Error())
+	}
+
+	// the last line is written after EOF read, wait until every line written
+	<-lines.eof
+	<-filtered.eof
+	deadline := time.Now().Add(time.Second)
+	for hook.Cnt() < 4 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	writer.flush()
+
+	content, err := ioutil.ReadFile("/tmp/pipe.log")
+	if nil != err {
+		t.Fatalf("read log failed. err: %s", err.Error())
+	}
+
+	written := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if 4 != len(written) {
+		t.Fatalf("pipe wrote wrong lines. lines: %d", len(written))
+	}
+	if !strings.HasSuffix(written[0], "] first line") || !strings.HasSuffix(written[1], "] second line") ||
+		!strings.HasSuffix(written[2], "] "+long) || !strings.HasSuffix(written[3], "] last line") {
+		t.Errorf("pipe wrote wrong message. first lines: %v", written[:2])
+	}
+
+	writer.Close()
+	if err = writer.PipeFrom(strings.NewReader("closed\n"), INFO); ErrWriterClosed != err {
+		t.Error("pipe from closed writer should fail")
+	}
+}
+
+func TestPipeFromWithContext(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/pipe.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/pipe.log")
+	}()
+
+	r, w := newCloseNotifyReader()
+	ctx, cancel := context.WithCancel(context.Background())
+	if err = writer.PipeFromWithContext(ctx, r, INFO); nil != err {
+		t.Errorf("pipe from reader failed. err: %s", err.Error())
+	}
+
+	if _, err = w.Write([]byte("line\n")); nil != err {
+		t.Errorf("write to pipe failed. err: %s", err.Error())
+	}
+
+	cancel()
+	<-r.closed
+	if _, err = w.Write([]byte("line\n")); io.ErrClosedPipe != err {
+		t.Error("reader should be closed when context canceled")
+	}
+}
+
+func TestPipeFromStopsOnClose(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/pipe.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer os.Remove("/tmp/pipe.log")
+
+	r, w := newCloseNotifyReader()
+	if err = writer.PipeFrom(r, INFO); nil != err {
+		t.Errorf("pipe from reader failed. err: %s", err.Error())
+	}
+	if _, err = w.Write([]byte("line\n")); nil != err {
+		t.Errorf("write to pipe failed. err: %s", err.Error())
+	}
+
+	writer.Close()
+	<-r.closed
+	if _, err = w.Write([]byte("line\n")); io.ErrClosedPipe != err {
+		t.Error("reader should be closed when writer closed")
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Predicate decides whether message with level is written
+type Predicate func(level LevelType, message string) bool
+
+// PredicateWriter wraps a writer, only messages predicate returns true for
+// are written, like messages of queries slower than 100ms. Messages are
+// filtered by level first, so predicate is not called for messages below
+// level.
+type PredicateWriter struct {
+	writerWrapper
+
+	predicate Predicate
+
+	lock *sync.RWMutex
+}
+
+// NewPredicateWriter create a PredicateWriter wrapping writer, every message
+// is written if predicate is nil
+func NewPredicateWriter(writer Writer, predicate Predicate) *PredicateWriter {
+	predicated := new(PredicateWriter)
+	predicated.Writer = writer
+	predicated.self = predicated
+	predicated.predicate = predicate
+	predicated.lock = new(sync.RWMutex)
+	return predicated
+}
+
+// SetPredicate replace predicate on the fly
+func (predicated *PredicateWriter) SetPredicate(predicate Predicate) {
+	predicated.lock.Lock()
+	defer predicated.lock.Unlock()
+	predicated.predicate = predicate
+}
+
+// allow return true if message should be written
+func (predicated *PredicateWriter) allow(level LevelType, message string) bool {
+	predicated.lock.RLock()
+	predicate := predicated.predicate
+	predicated.lock.RUnlock()
+	return nil == predicate || predicate(level, message)
+}
+
+func (predicated *PredicateWriter) write(level LevelType, args ...interface{}) {
+	if message := fmt.Sprint(args...); predicated.allow(level, message) {
+		predicated.Writer.write(level, message)
+	}
+}
+
+func (predicated *PredicateWriter) writef(level LevelType, format string, args ...interface{}) {
+	if message := fmt.Sprintf(format, args...); predicated.allow(level, message) {
+		predicated.Writer.write(level, message)
+	}
+}
+
+// Trace trace
+func (predicated *PredicateWriter) Trace(args ...interface{}) {
+	if TRACE < predicated.Level() {
+		return
+	}
+
+	predicated.write(TRACE, args...)
+}
+
+// Tracef tracef
+func (predicated *PredicateWriter) Tracef(format string, args ...interface{}) {
+	if TRACE < predicated.Level() {
+		return
+	}
+
+	predicated.writef(TRACE, format, args...)
+}
+
+// Debug debug
+func (predicated *PredicateWriter) Debug(args ...interface{}) {
+	if DEBUG < predicated.Level() {
+		return
+	}
+
+	predicated.write(DEBUG, args...)
+}
+
+// Debugf debugf
+func (predicated *PredicateWriter) Debugf(format string, args ...interface{}) {
+	if DEBUG < predicated.Level() {
+		return
+	}
+
+	predicated.writef(DEBUG, format, args...)
+}
+
+// Info info
+func (predicated *PredicateWriter) Info(args ...interface{}) {
+	if INFO < predicated.Level() {
+		return
+	}
+
+	predicated.write(INFO, args...)
+}
+
+// Infof infof
+func (predicated *PredicateWriter) Infof(format string, args ...interface{}) {
+	if INFO < predicated.Level() {
+		return
+	}
+
+	predicated.writef(INFO, format, args...)
+}
+
+// Warn warn
+func (predicated *PredicateWriter) Warn(args ...interface{}) {
+	if WARNING < predicated.Level() {
+		return
+	}
+
+	predicated.write(WARNING, args...)
+}
+
+// Warnf warnf
+func (predicated *PredicateWriter) Warnf(format string, args ...interface{}) {
+	if WARNING < predicated.Level() {
+		return
+	}
+
+	predicated.writef(WARNING, format, args...)
+}
+
+// Error error
+func (predicated *PredicateWriter) Error(args ...interface{}) {
+	if ERROR < predicated.Level() {
+		return
+	}
+
+	predicated.write(ERROR, args...)
+}
+
+// Errorf errorf
+func (predicated *PredicateWriter) Errorf(format string, args ...interface{}) {
+	if ERROR < predicated.Level() {
+		return
+	}
+
+	predicated.writef(ERROR, format, args...)
+}
+
+// Critical critical
+func (predicated *PredicateWriter) Critical(args ...interface{}) {
+	if CRITICAL < predicated.Level() {
+		return
+	}
+
+	predicated.write(CRITICAL, args...)
+}
+
+// Criticalf criticalf
+func (predicated *PredicateWriter) Criticalf(format string, args ...interface{}) {
+	if CRITICAL < predicated.Level() {
+		return
+	}
+
+	predicated.writef(CRITICAL, format, args...)
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPredicateWriter(t *testing.T) {
+	writer, err := newRingBufferWriter(10)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	predicated := NewPredicateWriter(writer, func(level LevelType, message string) bool {
+		return strings.HasPrefix(message, "slow")
+	})
+	predicated.Info("fast query 10ms")
+	predicated.Infof("slow query %dms", 300)
+	predicated.Warn("slow", " query")
+
+	entries := writer.Entries()
+	if 2 != len(entries) || "slow query 300ms" != entries[0].Message || WARNING != entries[1].Level {
+		t.Errorf("messages written wrong. entries: %v", writer.Lines())
+	}
+
+	predicated.SetPredicate(func(level LevelType, message string) bool {
+		return !(level < ERROR)
+	})
+	predicated.Info("slow info")
+	predicated.Error("error")
+	if entries = writer.Entries(); 3 != len(entries) || "error" != entries[2].Message {
+		t.Errorf("predicate should be replaced. entries: %v", writer.Lines())
+	}
+
+	predicated.SetPredicate(nil)
+	predicated.Debug("any")
+	if 4 != len(writer.Entries()) {
+		t.Errorf("every message should be written without predicate. entries: %v", writer.Lines())
+	}
+}
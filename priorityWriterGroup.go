@@ -0,0 +1,421 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// errorWriter is implemented by writers able to report failure of a write,
+// the message failed is neither written nor kept
+type errorWriter interface {
+	writeErr(level LevelType, message string) error
+}
+
+// closedWriter is implemented by writers reporting whether closed
+type closedWriter interface {
+	Closed() bool
+}
+
+// PriorityWriterGroup writes messages to the primary writer, messages failed
+// to write are written to the secondary writer instead. Failure is detected
+// by writers reporting errors like SocketWriter, or writers closed. Health
+// of writers is updated by the result of every write.
+type PriorityWriterGroup struct {
+	writerBase
+
+	level LevelType
+
+	primary   Writer
+	secondary Writer
+
+	// 1 if the last write succeeded, accessed atomically
+	primaryOK   int32
+	secondaryOK int32
+	// messages failed on both writers, accessed atomically
+	failures int64
+
+	closed bool
+
+	// log hook
+	hook      Hook
+	hookLevel LevelType
+	hookAsync bool
+
+	lock *sync.RWMutex
+}
+
+// NewPriorityWriterGroup create a PriorityWriterGroup without writers
+func NewPriorityWriterGroup() *PriorityWriterGroup {
+	group := new(PriorityWriterGroup)
+	group.self = group
+	group.level = DEBUG
+	group.primaryOK = 1
+	group.secondaryOK = 1
+	group.closed = false
+	group.lock = new(sync.RWMutex)
+
+	// log hook
+	group.hook = nil
+	group.hookLevel = DEBUG
+	group.hookAsync = true
+	return group
+}
+
+// SetPrimary set the writer messages are written to by default
+func (group *PriorityWriterGroup) SetPrimary(w Writer) {
+	group.lock.Lock()
+	defer group.lock.Unlock()
+	group.primary = w
+	atomic.StoreInt32(&group.primaryOK, 1)
+}
+
+// SetSecondary set the writer messages failed on primary writer are written to
+func (group *PriorityWriterGroup) SetSecondary(w Writer) {
+	group.lock.Lock()
+	defer group.lock.Unlock()
+	group.secondary = w
+	atomic.StoreInt32(&group.secondaryOK, 1)
+}
+
+// HealthStatus return whether the last write to primary and secondary writer
+// succeeded, a writer not written yet is healthy
+func (group *PriorityWriterGroup) HealthStatus() (primaryOK, secondaryOK bool) {
+	return 1 == atomic.LoadInt32(&group.primaryOK), 1 == atomic.LoadInt32(&group.secondaryOK)
+}
+
+// Failures return number of messages failed on both writers
+func (group *PriorityWriterGroup) Failures() int64 {
+	return atomic.LoadInt64(&group.failures)
+}
+
+// writeTo writes message to w, ErrNotSupported is returned if w is nil
+func writeTo(w Writer, level LevelType, message string) error {
+	if nil == w {
+		return ErrNotSupported
+	}
+
+	if ew, ok := w.(errorWriter); ok {
+		return ew.writeErr(level, message)
+	}
+
+	if cw, ok := w.(closedWriter); ok && cw.Closed() {
+		return ErrWriterClosed
+	}
+	w.write(level, message)
+	return nil
+}
+
+// setHealth store health of a writer
+func setHealth(health *int32, err error) {
+	if nil == err {
+		atomic.StoreInt32(health, 1)
+	} else {
+		atomic.StoreInt32(health, 0)
+	}
+}
+
+// dispatch writes message to primary writer, or secondary writer if failed
+func (group *PriorityWriterGroup) dispatch(level LevelType, message string) {
+	group.lock.RLock()
+	defer group.lock.RUnlock()
+
+	if group.closed {
+		return
+	}
+
+	defer func() {
+		// call log hook
+		if nil != group.hook && !(level < group.hookLevel) {
+			if group.hookAsync {
+				go group.hook.Fire(level, message)
+			} else {
+				group.hook.Fire(level, message)
+			}
+		}
+	}()
+
+	err := writeTo(group.primary, level, message)
+	setHealth(&group.primaryOK, err)
+	if nil == err {
+		return
+	}
+
+	err = writeTo(group.secondary, level, message)
+	setHealth(&group.secondaryOK, err)
+	if nil != err {
+		atomic.AddInt64(&group.failures, 1)
+	}
+}
+
+// each calls fn with every writer set
+func (group *PriorityWriterGroup) each(fn func(w Writer)) {
+	group.lock.RLock()
+	defer group.lock.RUnlock()
+
+	for _, w := range []Writer{group.primary, group.secondary} {
+		if nil != w {
+			fn(w)
+		}
+	}
+}
+
+func (group *PriorityWriterGroup) write(level LevelType, args ...interface{}) {
+	group.dispatch(level, fmt.Sprint(args...))
+}
+
+func (group *PriorityWriterGroup) writef(level LevelType, format string, args ...interface{}) {
+	group.dispatch(level, fmt.Sprintf(format, args...))
+}
+
+// Level get level
+func (group *PriorityWriterGroup) Level() LevelType {
+	return group.level
+}
+
+// SetLevel set logger level
+func (group *PriorityWriterGroup) SetLevel(level LevelType) {
+	group.level = level
+}
+
+// SetHook set hook for logging action
+func (group *PriorityWriterGroup) SetHook(hook Hook) {
+	group.hook = hook
+}
+
+// SetHookAsync set hook async for the group
+func (group *PriorityWriterGroup) SetHookAsync(async bool) {
+	group.hookAsync = async
+}
+
+// SetHookLevel set when hook will be called
+func (group *PriorityWriterGroup) SetHookLevel(level LevelType) {
+	group.hookLevel = level
+}
+
+// TimeRotated get timeRotated of primary writer
+func (group *PriorityWriterGroup) TimeRotated() bool {
+	group.lock.RLock()
+	defer group.lock.RUnlock()
+	if nil == group.primary {
+		return false
+	}
+	return group.primary.TimeRotated()
+}
+
+// SetTimeRotated toggle time base logrotate of writers
+func (group *PriorityWriterGroup) SetTimeRotated(timeRotated bool) {
+	group.each(func(w Writer) { w.SetTimeRotated(timeRotated) })
+}
+
+// Retentions get retentions of primary writer
+func (group *PriorityWriterGroup) Retentions() int64 {
+	group.lock.RLock()
+	defer group.lock.RUnlock()
+	if nil == group.primary {
+		return 0
+	}
+	return group.primary.Retentions()
+}
+
+// SetRetentions set how many logs writers keep after logrotate
+func (group *PriorityWriterGroup) SetRetentions(retentions int64) {
+	group.each(func(w Writer) { w.SetRetentions(retentions) })
+}
+
+// RotateSize get rotateSize of primary writer
+func (group *PriorityWriterGroup) RotateSize() int64 {
+	group.lock.RLock()
+	defer group.lock.RUnlock()
+	if nil == group.primary {
+		return 0
+	}
+	return group.primary.RotateSize()
+}
+
+// SetRotateSize set size when writers logrotate
+func (group *PriorityWriterGroup) SetRotateSize(rotateSize int64) {
+	group.each(func(w Writer) { w.SetRotateSize(rotateSize) })
+}
+
+// RotateLines get rotateLines of primary writer
+func (group *PriorityWriterGroup) RotateLines() int {
+	group.lock.RLock()
+	defer group.lock.RUnlock()
+	if nil == group.primary {
+		return 0
+	}
+	return group.primary.RotateLines()
+}
+
+// SetRotateLines set line number when writers logrotate
+func (group *PriorityWriterGroup) SetRotateLines(rotateLines int) {
+	group.each(func(w Writer) { w.SetRotateLines(rotateLines) })
+}
+
+// Colored get colored of primary writer
+func (group *PriorityWriterGroup) Colored() bool {
+	group.lock.RLock()
+	defer group.lock.RUnlock()
+	if nil == group.primary {
+		return false
+	}
+	return group.primary.Colored()
+}
+
+// SetColored set logging color of writers
+func (group *PriorityWriterGroup) SetColored(colored bool) {
+	group.each(func(w Writer) { w.SetColored(colored) })
+}
+
+// SetEOL set end of every line of writers
+func (group *PriorityWriterGroup) SetEOL(eol []byte) {
+	group.each(func(w Writer) { w.SetEOL(eol) })
+}
+
+// AddMiddleware add a middleware to writers
+func (group *PriorityWriterGroup) AddMiddleware(middleware Middleware) {
+	group.each(func(w Writer) { w.AddMiddleware(middleware) })
+}
+
+// PipeFromWithContext logs every line read from r with given level until r
+// reaches EOF or ctx is done
+func (group *PriorityWriterGroup) PipeFromWithContext(ctx context.Context, r io.Reader, level LevelType) error {
+	if group.closed {
+		return ErrWriterClosed
+	}
+
+	go pipe(ctx, group, r, level)
+	return nil
+}
+
+// Close close the group and writers
+func (group *PriorityWriterGroup) Close() {
+	group.each(func(w Writer) { w.Close() })
+
+	group.lock.Lock()
+	defer group.lock.Unlock()
+	group.closed = true
+}
+
+// BeginShutdown begin shutdown of writers
+func (group *PriorityWriterGroup) BeginShutdown() {
+	group.each(func(w Writer) { w.BeginShutdown() })
+}
+
+// flush flush writers
+func (group *PriorityWriterGroup) flush() {
+	group.each(func(w Writer) { w.flush() })
+}
+
+// Trace trace
+func (group *PriorityWriterGroup) Trace(args ...interface{}) {
+	if TRACE < group.level {
+		return
+	}
+
+	group.write(TRACE, args...)
+}
+
+// Tracef tracef
+func (group *PriorityWriterGroup) Tracef(format string, args ...interface{}) {
+	if TRACE < group.level {
+		return
+	}
+
+	group.writef(TRACE, format, args...)
+}
+
+// Debug debug
+func (group *PriorityWriterGroup) Debug(args ...interface{}) {
+	if DEBUG < group.level {
+		return
+	}
+
+	group.write(DEBUG, args...)
+}
+
+// Debugf debugf
+func (group *PriorityWriterGroup) Debugf(format string, args ...interface{}) {
+	if DEBUG < group.level {
+		return
+	}
+
+	group.writef(DEBUG, format, args...)
+}
+
+// Info info
+func (group *PriorityWriterGroup) Info(args ...interface{}) {
+	if INFO < group.level {
+		return
+	}
+
+	group.write(INFO, args...)
+}
+
+// Infof infof
+func (group *PriorityWriterGroup) Infof(format string, args ...interface{}) {
+	if INFO < group.level {
+		return
+	}
+
+	group.writef(INFO, format, args...)
+}
+
+// Warn warn
+func (group *PriorityWriterGroup) Warn(args ...interface{}) {
+	if WARNING < group.level {
+		return
+	}
+
+	group.write(WARNING, args...)
+}
+
+// Warnf warnf
+func (group *PriorityWriterGroup) Warnf(format string, args ...interface{}) {
+	if WARNING < group.level {
+		return
+	}
+
+	group.writef(WARNING, format, args...)
+}
+
+// Error error
+func (group *PriorityWriterGroup) Error(args ...interface{}) {
+	if ERROR < group.level {
+		return
+	}
+
+	group.write(ERROR, args...)
+}
+
+// Errorf error
+func (group *PriorityWriterGroup) Errorf(format string, args ...interface{}) {
+	if ERROR < group.level {
+		return
+	}
+
+	group.writef(ERROR, format, args...)
+}
+
+// Critical critical
+func (group *PriorityWriterGroup) Critical(args ...interface{}) {
+	if CRITICAL < group.level {
+		return
+	}
+
+	group.write(CRITICAL, args...)
+}
+
+// Criticalf criticalf
+func (group *PriorityWriterGroup) Criticalf(format string, args ...interface{}) {
+	if CRITICAL < group.level {
+		return
+	}
+
+	group.writef(CRITICAL, format, args...)
+}
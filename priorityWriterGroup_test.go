@@ -0,0 +1,70 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"net"
+	"testing"
+)
+
+func TestPriorityWriterGroup(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatalf("listen failed. err: %s", err.Error())
+	}
+
+	primary, err := newSocketWriter("tcp", listener.Addr().String())
+	if nil != err {
+		t.Fatalf("initialize socket writer failed. err: %s", err.Error())
+	}
+	blog = nil
+
+	secondary, err := newRingBufferWriter(10)
+	if nil != err {
+		t.Fatalf("initialize ring buffer writer failed. err: %s", err.Error())
+	}
+
+	group := NewPriorityWriterGroup()
+	defer group.Close()
+	group.SetPrimary(primary)
+
+	group.Info("primary")
+	if primaryOK, _ := group.HealthStatus(); !primaryOK {
+		t.Error("primary writer should be healthy")
+	}
+	if 0 != len(secondary.Entries()) {
+		t.Error("message should not be written to secondary writer")
+	}
+
+	// disconnected and never redialed
+	listener.Close()
+	c := primary.writer
+	c.lock.Lock()
+	c.disconnect()
+	c.lock.Unlock()
+
+	// secondary writer not set
+	group.Info("lost")
+	if 1 != group.Failures() {
+		t.Errorf("failures wrong. failures: %d", group.Failures())
+	}
+
+	group.SetSecondary(secondary)
+	group.Infof("promoted %d", 1)
+	entries := secondary.Entries()
+	if 1 != len(entries) || "promoted 1" != entries[0].Message {
+		t.Errorf("message should be written to secondary writer. entries: %+v", entries)
+	}
+	if primaryOK, secondaryOK := group.HealthStatus(); primaryOK || !secondaryOK {
+		t.Errorf("health status wrong. primary: %t, secondary: %t", primaryOK, secondaryOK)
+	}
+
+	secondary.Close()
+	group.Error("failed")
+	if primaryOK, secondaryOK := group.HealthStatus(); primaryOK || secondaryOK {
+		t.Errorf("health status wrong. primary: %t, secondary: %t", primaryOK, secondaryOK)
+	}
+	if 2 != group.Failures() {
+		t.Errorf("failures wrong. failures: %d", group.Failures())
+	}
+}
@@ -0,0 +1,356 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Filter decides whether an entry is wanted
+type Filter interface {
+	Match(entry *Entry) bool
+}
+
+// FilterFunc is an adapter to use ordinary function as Filter
+type FilterFunc func(entry *Entry) bool
+
+// Match calls f(entry)
+func (f FilterFunc) Match(entry *Entry) bool {
+	return f(entry)
+}
+
+// ParseQuery parses query into a Filter.
+// A query is made up of comparisons combined with AND, OR, NOT and
+// parentheses, AND binds tighter than OR. Comparisons supported:
+// level =, !=, >, >=, <, <= a level string, like level >= ERROR
+// msg =, !=, CONTAINS, MATCHES a string, like msg MATCHES "timeout.*"
+// caller =, !=, CONTAINS, MATCHES file:line of the caller, like
+// caller CONTAINS "db", only set by writers looking up callers
+// tags CONTAINS a tag appended by LogBuilder.Tags, like tags CONTAINS "env:prod"
+// Strings may be quoted in double quotes. Keywords are case insensitive.
+func ParseQuery(query string) (Filter, error) {
+	tokens, err := tokenize(query)
+	if nil != err {
+		return nil, err
+	}
+
+	parser := &queryParser{tokens: tokens}
+	filter, err := parser.parseOr()
+	if nil != err {
+		return nil, err
+	}
+
+	if !parser.end() {
+		return nil, fmt.Errorf("Invalid query, unexpected %s", parser.peek().text)
+	}
+	return filter, nil
+}
+
+// queryToken is a token in query
+type queryToken struct {
+	text string
+	// a quoted string, never a keyword
+	quoted bool
+}
+
+// tokenize splits query into tokens
+func tokenize(query string) (tokens []queryToken, err error) {
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case ' ' == c || '\t' == c:
+			i++
+		case '(' == c || ')' == c:
+			tokens = append(tokens, queryToken{text: string(c)})
+			i++
+		case '"' == c:
+			// find the end of quoted string
+			j := i + 1
+			for ; j < len(query) && '"' != query[j]; j++ {
+				if ESCAPE == query[j] {
+					j++
+				}
+			}
+			if j >= len(query) {
+				return nil, fmt.Errorf("Invalid query, unterminated string %s", query[i:])
+			}
+
+			str, err := strconv.Unquote(query[i : j+1])
+			if nil != err {
+				return nil, fmt.Errorf("Invalid query, bad string %s", query[i:j+1])
+			}
+			tokens = append(tokens, queryToken{text: str, quoted: true})
+			i = j + 1
+		case strings.IndexByte("=!<>", c) >= 0:
+			j := i + 1
+			if j < len(query) && '=' == query[j] {
+				j++
+			}
+			tokens = append(tokens, queryToken{text: query[i:j]})
+			i = j
+		default:
+			j := i
+			for ; j < len(query) && isWordByte(query[j]); j++ {
+			}
+			if j == i {
+				return nil, fmt.Errorf("Invalid query, unexpected %c", c)
+			}
+			tokens = append(tokens, queryToken{text: query[i:j]})
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+// isWordByte determines whether c can be part of a word
+func isWordByte(c byte) bool {
+	return '_' == c || '.' == c || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+}
+
+// queryParser parses tokens in recursive descent
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (parser *queryParser) end() bool {
+	return parser.pos >= len(parser.tokens)
+}
+
+func (parser *queryParser) peek() queryToken {
+	return parser.tokens[parser.pos]
+}
+
+// keyword determines whether the next token is keyword, consumes it if true
+func (parser *queryParser) keyword(keyword string) bool {
+	if parser.end() || parser.peek().quoted || !strings.EqualFold(keyword, parser.peek().text) {
+		return false
+	}
+	parser.pos++
+	return true
+}
+
+// next consumes the next token
+func (parser *queryParser) next() (queryToken, error) {
+	if parser.end() {
+		return queryToken{}, fmt.Errorf("Invalid query, unexpected end")
+	}
+	token := parser.peek()
+	parser.pos++
+	return token, nil
+}
+
+// parseOr parses: and (OR and)*
+func (parser *queryParser) parseOr() (Filter, error) {
+	filter, err := parser.parseAnd()
+	if nil != err {
+		return nil, err
+	}
+
+	for parser.keyword("OR") {
+		right, err := parser.parseAnd()
+		if nil != err {
+			return nil, err
+		}
+		filter = orFilter{filter, right}
+	}
+	return filter, nil
+}
+
+// parseAnd parses: unary (AND unary)*
+func (parser *queryParser) parseAnd() (Filter, error) {
+	filter, err := parser.parseUnary()
+	if nil != err {
+		return nil, err
+	}
+
+	for parser.keyword("AND") {
+		right, err := parser.parseUnary()
+		if nil != err {
+			return nil, err
+		}
+		filter = andFilter{filter, right}
+	}
+	return filter, nil
+}
+
+// parseUnary parses: NOT unary | ( or ) | comparison
+func (parser *queryParser) parseUnary() (Filter, error) {
+	if parser.keyword("NOT") {
+		filter, err := parser.parseUnary()
+		if nil != err {
+			return nil, err
+		}
+		return notFilter{filter}, nil
+	}
+
+	if parser.keyword("(") {
+		filter, err := parser.parseOr()
+		if nil != err {
+			return nil, err
+		}
+		if !parser.keyword(")") {
+			return nil, fmt.Errorf("Invalid query, missing )")
+		}
+		return filter, nil
+	}
+
+	return parser.parseComparison()
+}
+
+// parseComparison parses: field operator value
+func (parser *queryParser) parseComparison() (Filter, error) {
+	field, err := parser.next()
+	if nil != err {
+		return nil, err
+	}
+	operator, err := parser.next()
+	if nil != err {
+		return nil, err
+	}
+	value, err := parser.next()
+	if nil != err {
+		return nil, err
+	}
+
+	op := strings.ToUpper(operator.text)
+	switch strings.ToLower(field.text) {
+	case "level":
+		level := LevelFromString(value.text)
+		if !level.valid() {
+			return nil, fmt.Errorf("Invalid query, unknown level %s", value.text)
+		}
+
+		switch op {
+		case "=", "!=", ">", ">=", "<", "<=":
+			return levelFilter{op, level}, nil
+		}
+	case "msg", "caller":
+		caller := "caller" == strings.ToLower(field.text)
+		switch op {
+		case "=", "!=", "CONTAINS":
+			return messageFilter{op: op, value: value.text, caller: caller}, nil
+		case "MATCHES":
+			re, err := regexp.Compile(value.text)
+			if nil != err {
+				return nil, fmt.Errorf("Invalid query, bad regexp %s", value.text)
+			}
+			return messageFilter{op: op, re: re, caller: caller}, nil
+		}
+	case TagsKey:
+		if "CONTAINS" == op {
+			return tagFilter{value.text}, nil
+		}
+	default:
+		return nil, fmt.Errorf("Invalid query, unknown field %s", field.text)
+	}
+
+	return nil, fmt.Errorf("Invalid query, unknown operator %s for %s", operator.text, field.text)
+}
+
+// levelFilter compares level of entry
+type levelFilter struct {
+	op    string
+	level LevelType
+}
+
+func (filter levelFilter) Match(entry *Entry) bool {
+	switch filter.op {
+	case "=":
+		return entry.Level == filter.level
+	case "!=":
+		return entry.Level != filter.level
+	case ">":
+		return entry.Level > filter.level
+	case ">=":
+		return entry.Level >= filter.level
+	case "<":
+		return entry.Level < filter.level
+	default:
+		return entry.Level <= filter.level
+	}
+}
+
+// messageFilter compares message of entry, or caller of entry if caller
+type messageFilter struct {
+	op    string
+	value string
+	re    *regexp.Regexp
+
+	caller bool
+}
+
+func (filter messageFilter) Match(entry *Entry) bool {
+	text := entry.Message
+	if filter.caller {
+		text = entry.Caller
+	}
+
+	switch filter.op {
+	case "=":
+		return text == filter.value
+	case "!=":
+		return text != filter.value
+	case "CONTAINS":
+		return strings.Contains(text, filter.value)
+	default:
+		return filter.re.MatchString(text)
+	}
+}
+
+// tagFilter determines whether entry has the tag
+type tagFilter struct {
+	tag string
+}
+
+func (filter tagFilter) Match(entry *Entry) bool {
+	for _, tag := range messageTags(entry.Message) {
+		if tag == filter.tag {
+			return true
+		}
+	}
+	return false
+}
+
+// messageTags return tags in the last tags field of message
+func messageTags(message string) []string {
+	begin := strings.LastIndex(message, " "+TagsKey+"=[")
+	if begin < 0 {
+		return nil
+	}
+
+	tags := message[begin+len(TagsKey)+3:]
+	end := strings.IndexByte(tags, ']')
+	if end < 0 {
+		return nil
+	}
+	return strings.Split(tags[:end], ",")
+}
+
+type andFilter struct {
+	left, right Filter
+}
+
+func (filter andFilter) Match(entry *Entry) bool {
+	return filter.left.Match(entry) && filter.right.Match(entry)
+}
+
+type orFilter struct {
+	left, right Filter
+}
+
+func (filter orFilter) Match(entry *Entry) bool {
+	return filter.left.Match(entry) || filter.right.Match(entry)
+}
+
+type notFilter struct {
+	filter Filter
+}
+
+func (filter notFilter) Match(entry *Entry) bool {
+	return !filter.filter.Match(entry)
+}
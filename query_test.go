@@ -0,0 +1,84 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	entries := []*Entry{
+		{Level: INFO, Message: "db connected", Caller: "/src/app/db/conn.go:12"},
+		{Level: ERROR, Message: "db timeout after 3s", Caller: "/src/app/db/query.go:40"},
+		{Level: CRITICAL, Message: "cache timeout", Caller: "/src/app/cache.go:7"},
+	}
+
+	cases := []struct {
+		query   string
+		matches []bool
+	}{
+		{`level >= ERROR`, []bool{false, true, true}},
+		{`level = info`, []bool{true, false, false}},
+		{`msg CONTAINS "db" AND msg MATCHES "timeout.*"`, []bool{false, true, false}},
+		{`level < ERROR OR msg contains cache`, []bool{true, false, true}},
+		{`NOT (level >= ERROR AND msg CONTAINS "db")`, []bool{true, false, true}},
+		{`msg = "db connected"`, []bool{true, false, false}},
+		{`caller CONTAINS "db"`, []bool{true, true, false}},
+		{`caller MATCHES "cache\\.go:[0-9]+$" OR caller = "/src/app/db/conn.go:12"`, []bool{true, false, true}},
+	}
+
+	for _, c := range cases {
+		filter, err := ParseQuery(c.query)
+		if nil != err {
+			t.Errorf("parse query failed. query: %s, err: %s", c.query, err.Error())
+			continue
+		}
+
+		for i, entry := range entries {
+			if c.matches[i] != filter.Match(entry) {
+				t.Errorf("query matched wrong. query: %s, message: %s", c.query, entry.Message)
+			}
+		}
+	}
+
+	for _, query := range []string{`level >= SOMETHING`, `file CONTAINS "db"`, `caller > "db"`, `msg MATCHES "("`, `level >=`, `(level = INFO`, `msg = "unterminated`, `level = INFO INFO`} {
+		if _, err := ParseQuery(query); nil == err {
+			t.Errorf("invalid query should fail. query: %s", query)
+		}
+	}
+}
+
+func TestRingBufferWriterQuery(t *testing.T) {
+	writer, err := newRingBufferWriter(10)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	writer.Info("db connected")
+	writer.Error("db timeout")
+	writer.Error("cache timeout")
+
+	recorder := httptest.NewRecorder()
+	writer.ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/logs?q="+url.QueryEscape(`level >= ERROR AND msg CONTAINS "db"`), nil))
+	if body := recorder.Body.String(); 1 != strings.Count(body, "\n") || !strings.Contains(body, "db timeout") {
+		t.Errorf("ring buffer served wrong messages. body: %s", body)
+	}
+
+	if callerEnabled {
+		recorder = httptest.NewRecorder()
+		writer.ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/logs?q="+url.QueryEscape(`caller CONTAINS "query_test.go"`), nil))
+		if body := recorder.Body.String(); 3 != strings.Count(body, "\n") {
+			t.Errorf("ring buffer served wrong messages by caller. body: %s", body)
+		}
+	}
+
+	recorder = httptest.NewRecorder()
+	writer.ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/logs?q="+url.QueryEscape(`level >=`), nil))
+	if http.StatusBadRequest != recorder.Code {
+		t.Error("invalid query should be bad request")
+	}
+}
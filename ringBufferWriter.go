@@ -0,0 +1,473 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrInvalidCapacity invalid capacity
+	ErrInvalidCapacity = errors.New("Capacity must be greater than 0")
+)
+
+// RingBufferWriter is a memory logger keeping the last messages written.
+// It implements http.Handler to serve messages kept, as plain text or json
+// according to the Accept header. Query parameters supported:
+// level, only messages exceed the level are returned, like ?level=ERROR
+// n, only the last n messages are returned, like ?n=50
+// q, only messages matching the query are returned, see ParseQuery
+type RingBufferWriter struct {
+	writerBase
+
+	level LevelType
+
+	closed bool
+
+	// log hook
+	hook      Hook
+	hookLevel LevelType
+	hookAsync bool
+
+	// middlewares applied to message before written
+	middlewareList
+
+	// ring buffer
+	entries []*Entry
+	// position of the oldest entry
+	head int
+	// number of entries kept
+	count int
+
+	lock *sync.Mutex
+}
+
+// NewRingBufferWriter creates a ring buffer writer keeping the last capacity
+// messages, singlton. The returned handler serves messages kept.
+func NewRingBufferWriter(capacity int) (ringBufferWriter *RingBufferWriter, handler http.Handler, err error) {
+	singltonLock.Lock()
+	defer singltonLock.Unlock()
+	if nil != blog {
+		return nil, nil, ErrAlreadyInit
+	}
+
+	ringBufferWriter, err = newRingBufferWriter(capacity)
+	if nil != err {
+		return nil, nil, err
+	}
+
+	blog = ringBufferWriter
+	return ringBufferWriter, ringBufferWriter, nil
+}
+
+// newRingBufferWriter creates a ring buffer writer, not singlton
+func newRingBufferWriter(capacity int) (ringBufferWriter *RingBufferWriter, err error) {
+	if capacity < 1 {
+		return nil, ErrInvalidCapacity
+	}
+
+	ringBufferWriter = new(RingBufferWriter)
+	ringBufferWriter.self = ringBufferWriter
+	ringBufferWriter.level = DEBUG
+	ringBufferWriter.closed = false
+	ringBufferWriter.lock = new(sync.Mutex)
+	ringBufferWriter.entries = make([]*Entry, capacity)
+
+	// log hook
+	ringBufferWriter.hook = nil
+	ringBufferWriter.hookLevel = DEBUG
+	ringBufferWriter.hookAsync = true
+
+	return ringBufferWriter, nil
+}
+
+// push appends message to the ring buffer, the oldest one is dropped if full
+func (writer *RingBufferWriter) push(level LevelType, message string) {
+	message = writer.applyMiddlewares(level, message)
+
+	entry := &Entry{Time: timeCache.Now(), Level: level, Message: message}
+	if callerEnabled {
+		frame := callerFrame()
+		entry.Caller = fmt.Sprintf("%s:%d", frame.File, frame.Line)
+	}
+	if writer.count < len(writer.entries) {
+		writer.entries[(writer.head+writer.count)%len(writer.entries)] = entry
+		writer.count++
+		return
+	}
+
+	writer.entries[writer.head] = entry
+	writer.head = (writer.head + 1) % len(writer.entries)
+}
+
+// Entries return entries kept in chronological order
+func (writer *RingBufferWriter) Entries() []*Entry {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	entries := make([]*Entry, 0, writer.count)
+	for i := 0; i < writer.count; i++ {
+		entries = append(entries, writer.entries[(writer.head+i)%len(writer.entries)])
+	}
+	return entries
+}
+
+// Lines return messages kept in chronological order, formatted as the way
+// file writer does
+func (writer *RingBufferWriter) Lines() []string {
+	entries := writer.Entries()
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		lines = append(lines, formatEntry(entry))
+	}
+	return lines
+}
+
+// formatEntry formats entry as a line without EOL
+func formatEntry(entry *Entry) string {
+	return entry.Time.Format(PrefixTimeFormat) + fmt.Sprintf(PrefixFormat, entry.Level.String()) + entry.Message
+}
+
+// ServeHTTP serves messages kept
+func (writer *RingBufferWriter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	level := TRACE
+	if str := query.Get("level"); "" != str {
+		if level = LevelFromString(str); !level.valid() {
+			http.Error(w, ErrInvalidLevel.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	n := -1
+	if str := query.Get("n"); "" != str {
+		var err error
+		if n, err = strconv.Atoi(str); nil != err || n < 0 {
+			http.Error(w, "Invalid n", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var filter Filter
+	if str := query.Get("q"); "" != str {
+		var err error
+		if filter, err = ParseQuery(str); nil != err {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var entries []*Entry
+	for _, entry := range writer.Entries() {
+		if !(entry.Level < level) && (nil == filter || filter.Match(entry)) {
+			entries = append(entries, entry)
+		}
+	}
+	if n >= 0 && n < len(entries) {
+		entries = entries[len(entries)-n:]
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		out := make([]jsonEntry, 0, len(entries))
+		for _, entry := range entries {
+			out = append(out, newJSONEntry(entry))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(out)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	for _, entry := range entries {
+		io.WriteString(w, formatEntry(entry))
+		w.Write(EOLUnix)
+	}
+}
+
+func (writer *RingBufferWriter) write(level LevelType, args ...interface{}) {
+	message := fmt.Sprint(args...)
+
+	writer.lock.Lock()
+	if writer.closed {
+		writer.lock.Unlock()
+		return
+	}
+	writer.push(level, message)
+	hook, async := writer.hookOf(level)
+	writer.lock.Unlock()
+
+	// call log hook without lock, hook may log to this writer
+	if nil == hook {
+		return
+	}
+	if async {
+		go hook.Fire(level, args...)
+	} else {
+		hook.Fire(level, args...)
+	}
+}
+
+func (writer *RingBufferWriter) writef(level LevelType, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+
+	writer.lock.Lock()
+	if writer.closed {
+		writer.lock.Unlock()
+		return
+	}
+	writer.push(level, message)
+	hook, async := writer.hookOf(level)
+	writer.lock.Unlock()
+
+	// call log hook without lock, hook may log to this writer
+	if nil == hook {
+		return
+	}
+	if async {
+		go hook.Fire(level, message)
+	} else {
+		hook.Fire(level, message)
+	}
+}
+
+// hookOf return hook to call for message with level, nil if not called.
+// writer.lock must be held
+func (writer *RingBufferWriter) hookOf(level LevelType) (hook Hook, async bool) {
+	if nil == writer.hook || level < writer.hookLevel {
+		return nil, false
+	}
+	return writer.hook, writer.hookAsync
+}
+
+// Level get level
+func (writer *RingBufferWriter) Level() LevelType {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	return writer.level
+}
+
+// SetLevel set logger level
+func (writer *RingBufferWriter) SetLevel(level LevelType) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.level = level
+}
+
+// SetHook set hook for logging action
+func (writer *RingBufferWriter) SetHook(hook Hook) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.hook = hook
+}
+
+// SetHookAsync set hook async for ring buffer writer
+func (writer *RingBufferWriter) SetHookAsync(async bool) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.hookAsync = async
+}
+
+// SetHookLevel set when hook will be called
+func (writer *RingBufferWriter) SetHookLevel(level LevelType) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.hookLevel = level
+}
+
+// TimeRotated do nothing
+func (writer *RingBufferWriter) TimeRotated() bool {
+	return false
+}
+
+// SetTimeRotated do nothing
+func (writer *RingBufferWriter) SetTimeRotated(timeRotated bool) {
+	return
+}
+
+// Retentions do nothing
+func (writer *RingBufferWriter) Retentions() int64 {
+	return 0
+}
+
+// SetRetentions do nothing
+func (writer *RingBufferWriter) SetRetentions(retentions int64) {
+	return
+}
+
+// RotateSize do nothing
+func (writer *RingBufferWriter) RotateSize() int64 {
+	return 0
+}
+
+// SetRotateSize do nothing
+func (writer *RingBufferWriter) SetRotateSize(rotateSize int64) {
+	return
+}
+
+// RotateLines do nothing
+func (writer *RingBufferWriter) RotateLines() int {
+	return 0
+}
+
+// SetRotateLines do nothing
+func (writer *RingBufferWriter) SetRotateLines(rotateLines int) {
+	return
+}
+
+// Colored do nothing
+func (writer *RingBufferWriter) Colored() bool {
+	return false
+}
+
+// SetColored do nothing
+func (writer *RingBufferWriter) SetColored(colored bool) {
+	return
+}
+
+// PipeFromWithContext logs every line read from r with given level until r
+// reaches EOF or ctx is done
+func (writer *RingBufferWriter) PipeFromWithContext(ctx context.Context, r io.Reader, level LevelType) error {
+	if writer.closed {
+		return ErrWriterClosed
+	}
+
+	go pipe(ctx, writer, r, level)
+	return nil
+}
+
+// Close will close the writer, messages kept are still served
+func (writer *RingBufferWriter) Close() {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.closed = true
+}
+
+// Closed get writer status
+func (writer *RingBufferWriter) Closed() bool {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	return writer.closed
+}
+
+// flush do nothing
+func (writer *RingBufferWriter) flush() {
+	return
+}
+
+// Trace trace
+func (writer *RingBufferWriter) Trace(args ...interface{}) {
+	if TRACE < writer.Level() {
+		return
+	}
+
+	writer.write(TRACE, args...)
+}
+
+// Tracef tracef
+func (writer *RingBufferWriter) Tracef(format string, args ...interface{}) {
+	if TRACE < writer.Level() {
+		return
+	}
+
+	writer.writef(TRACE, format, args...)
+}
+
+// Debug debug
+func (writer *RingBufferWriter) Debug(args ...interface{}) {
+	if DEBUG < writer.Level() {
+		return
+	}
+
+	writer.write(DEBUG, args...)
+}
+
+// Debugf debugf
+func (writer *RingBufferWriter) Debugf(format string, args ...interface{}) {
+	if DEBUG < writer.Level() {
+		return
+	}
+
+	writer.writef(DEBUG, format, args...)
+}
+
+// Info info
+func (writer *RingBufferWriter) Info(args ...interface{}) {
+	if INFO < writer.Level() {
+		return
+	}
+
+	writer.write(INFO, args...)
+}
+
+// Infof infof
+func (writer *RingBufferWriter) Infof(format string, args ...interface{}) {
+	if INFO < writer.Level() {
+		return
+	}
+
+	writer.writef(INFO, format, args...)
+}
+
+// Warn warn
+func (writer *RingBufferWriter) Warn(args ...interface{}) {
+	if WARNING < writer.Level() {
+		return
+	}
+
+	writer.write(WARNING, args...)
+}
+
+// Warnf warnf
+func (writer *RingBufferWriter) Warnf(format string, args ...interface{}) {
+	if WARNING < writer.Level() {
+		return
+	}
+
+	writer.writef(WARNING, format, args...)
+}
+
+// Error error
+func (writer *RingBufferWriter) Error(args ...interface{}) {
+	if ERROR < writer.Level() {
+		return
+	}
+
+	writer.write(ERROR, args...)
+}
+
+// Errorf error
+func (writer *RingBufferWriter) Errorf(format string, args ...interface{}) {
+	if ERROR < writer.Level() {
+		return
+	}
+
+	writer.writef(ERROR, format, args...)
+}
+
+// Critical critical
+func (writer *RingBufferWriter) Critical(args ...interface{}) {
+	if CRITICAL < writer.Level() {
+		return
+	}
+
+	writer.write(CRITICAL, args...)
+}
+
+// Criticalf criticalf
+func (writer *RingBufferWriter) Criticalf(format string, args ...interface{}) {
+	if CRITICAL < writer.Level() {
+		return
+	}
+
+	writer.writef(CRITICAL, format, args...)
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRingBufferWriterBasicOperation(t *testing.T) {
+	writer, handler, err := NewRingBufferWriter(3)
+	defer Close()
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if _, _, err = NewRingBufferWriter(3); ErrAlreadyInit != err {
+		t.Error("duplicate init check fail")
+	}
+
+	Debug("1")
+	Infof("%d", 2)
+	Warn("3")
+	Error("4")
+	Criticalf("%d", 5)
+
+	if writer != blog {
+		t.Error("writer returned should be the singleton")
+	}
+	lines := writer.Lines()
+	if 3 != len(lines) || !strings.HasSuffix(lines[0], "] 3") || !strings.HasSuffix(lines[2], "] 5") {
+		t.Errorf("ring buffer kept wrong messages. lines: %v", lines)
+	}
+
+	// plain text
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/logs?level=ERROR&n=1", nil))
+	if body := recorder.Body.String(); 1 != strings.Count(body, "\n") || !strings.Contains(body, "[CRITICAL] 5") {
+		t.Errorf("ring buffer served wrong plain text. body: %s", body)
+	}
+
+	// json
+	recorder = httptest.NewRecorder()
+	request := httptest.NewRequest("GET", "/debug/logs?level=error", nil)
+	request.Header.Set("Accept", "application/json")
+	handler.ServeHTTP(recorder, request)
+
+	var entries []struct {
+		Level   string `json:"level"`
+		Message string `json:"message"`
+	}
+	if err = json.Unmarshal(recorder.Body.Bytes(), &entries); nil != err {
+		t.Fatalf("ring buffer served invalid json. err: %s", err.Error())
+	}
+	if 2 != len(entries) || "ERROR" != entries[0].Level || "5" != entries[1].Message {
+		t.Errorf("ring buffer served wrong json. body: %s", recorder.Body.String())
+	}
+
+	// bad request
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/logs?n=-1", nil))
+	if http.StatusBadRequest != recorder.Code {
+		t.Error("invalid n should be bad request")
+	}
+}
+
+func TestRingBufferWriterInvalidCapacity(t *testing.T) {
+	if _, err := newRingBufferWriter(0); ErrInvalidCapacity != err {
+		t.Error("invalid capacity should fail")
+	}
+}
+
+func TestRingBufferWriterOperations(t *testing.T) {
+	writer, err := newRingBufferWriter(10)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	hook := NewMyHook()
+	writer.SetHook(hook)
+	writer.SetHookAsync(false)
+	writer.SetHookLevel(WARNING)
+	writer.AddMiddleware(func(level LevelType, message string) string {
+		return strings.ToUpper(message)
+	})
+
+	// do nothing operations
+	writer.SetEOL(EOLWindows)
+	writer.SetColored(true)
+	writer.SetTimeRotated(true)
+	writer.SetRetentions(7)
+	writer.SetRotateSize(1024)
+	writer.SetRotateLines(100)
+	writer.BeginShutdown()
+	writer.flush()
+	if writer.Colored() || writer.TimeRotated() || 0 != writer.Retentions() || 0 != writer.RotateSize() || 0 != writer.RotateLines() {
+		t.Error("ring buffer writer should ignore file settings")
+	}
+
+	writer.SetLevel(INFO)
+	if INFO != writer.Level() {
+		t.Error("ring buffer writer level wrong")
+	}
+
+	writer.Trace("trace")
+	writer.Tracef("%s", "trace")
+	writer.Debug("debug")
+	writer.Debugf("%s", "debug")
+	writer.Info("info")
+	writer.Warnf("%s", "warn")
+	writer.Errorf("%s", "error")
+	if 2 != hook.Cnt() || "error" != hook.Message() {
+		t.Errorf("hook called wrong. count: %d, message: %s", hook.Cnt(), hook.Message())
+	}
+
+	if err = writer.PipeFrom(strings.NewReader("piped\n"), ERROR); nil != err {
+		t.Errorf("pipe from failed. err: %s", err.Error())
+	}
+	// wait for pipe
+	time.Sleep(10 * time.Millisecond)
+
+	lines := writer.Lines()
+	if 4 != len(lines) || !strings.HasSuffix(lines[0], "] INFO") || !strings.HasSuffix(lines[3], "] PIPED") {
+		t.Errorf("ring buffer kept wrong messages. lines: %v", lines)
+	}
+
+	writer.Close()
+	if ErrWriterClosed != writer.PipeFrom(strings.NewReader(""), INFO) {
+		t.Error("pipe from closed writer should fail")
+	}
+}
+
+// loggingHook logs to writer when fired
+type loggingHook struct {
+	writer Writer
+}
+
+func (hook *loggingHook) Fire(level LevelType, args ...interface{}) {
+	hook.writer.Info("hooked")
+}
+
+func TestRingBufferWriterHookLogging(t *testing.T) {
+	writer, err := newRingBufferWriter(10)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	writer.SetHook(&loggingHook{writer: writer})
+	writer.SetHookAsync(false)
+	writer.SetHookLevel(ERROR)
+
+	done := make(chan bool)
+	go func() {
+		writer.Error("error")
+		writer.Errorf("%s", "error")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("hook logging to the writer should not deadlock")
+	}
+
+	if lines := writer.Lines(); 4 != len(lines) || !strings.HasSuffix(lines[1], "] hooked") {
+		t.Errorf("hook logged wrong. lines: %v", lines)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrInvalidRotateAt invalid hour or minute of daily logrotate
+	ErrInvalidRotateAt = errors.New("Hour must be between 0 and 23, minute between 0 and 59")
+
+	// RotateAtWindow is the drift allowed between daemon tick and wall clock
+	// time of daily logrotate
+	RotateAtWindow = 30 * time.Second
+)
+
+// wallClock is the local time of a day
+type wallClock struct {
+	hour   int
+	minute int
+}
+
+// SetRotateAt do logrotate once a day at hour:minute local time, like 3:00
+// to avoid traffic at midnight. Files are renamed like Rotate does, so
+// retentions or RotateNamer should be set. It is not supported if file
+// descriptor inherited.
+func (writer *baseFileWriter) SetRotateAt(hour, minute int) error {
+	if hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return ErrInvalidRotateAt
+	}
+
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if writer.inherited {
+		return ErrNotSupported
+	}
+	writer.rotateAt = &wallClock{hour: hour, minute: minute}
+	return nil
+}
+
+// rotateAtDue return true if now is within RotateAtWindow of time of daily
+// logrotate and logrotate is not done in that window, time of the last
+// logrotate is updated then
+func (writer *baseFileWriter) rotateAtDue(now time.Time) bool {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	if nil == writer.rotateAt {
+		return false
+	}
+
+	target := time.Date(now.Year(), now.Month(), now.Day(), writer.rotateAt.hour, writer.rotateAt.minute, 0, 0, now.Location())
+	// target may be in the day before or after, like 23:59:45 for 00:00
+	diff := now.Sub(target)
+	if diff > 12*time.Hour {
+		diff -= 24 * time.Hour
+	} else if diff < -12*time.Hour {
+		diff += 24 * time.Hour
+	}
+	if diff < -RotateAtWindow || diff > RotateAtWindow {
+		return false
+	}
+
+	if !writer.lastRotateAt.IsZero() && now.Sub(writer.lastRotateAt) <= 2*RotateAtWindow {
+		return false
+	}
+	writer.lastRotateAt = now
+	return true
+}
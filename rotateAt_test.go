@@ -0,0 +1,49 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRotateAt(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/rotateAt.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/rotateAt.log")
+	}()
+
+	if writer.rotateAtDue(time.Now()) {
+		t.Error("daily logrotate should be disabled by default")
+	}
+	if ErrInvalidRotateAt != writer.SetRotateAt(24, 0) || ErrInvalidRotateAt != writer.SetRotateAt(3, -1) {
+		t.Error("invalid time of daily logrotate should fail")
+	}
+	if err := writer.SetRotateAt(0, 0); nil != err {
+		t.Fatalf("set daily logrotate failed. err: %s", err.Error())
+	}
+
+	midnight := time.Date(2016, 1, 2, 0, 0, 0, 0, time.Local)
+	checks := []struct {
+		now time.Time
+		due bool
+	}{
+		{midnight.Add(-time.Minute), false},
+		// drift before midnight of the next day
+		{midnight.Add(-20 * time.Second), true},
+		// rotated in the same window
+		{midnight.Add(10 * time.Second), false},
+		{midnight.Add(24*time.Hour + 25*time.Second), true},
+		{midnight.Add(48*time.Hour + time.Minute), false},
+	}
+	for _, check := range checks {
+		if due := writer.rotateAtDue(check.now); check.due != due {
+			t.Errorf("daily logrotate due wrong. now: %s, due: %t", check.now, due)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"fmt"
+	"time"
+)
+
+// RotationInfo is the state of the file written, passed to RotationPolicy
+type RotationInfo struct {
+	// current file name of the writer
+	FileName string
+	// size && lines written since the file opened
+	Size  int64
+	Lines int
+	// time the file opened
+	Opened time.Time
+	// time of the check
+	Now time.Time
+}
+
+// RotationPolicy decides when the file written is rotated and the name of
+// the file rotated. ShouldRotate is checked after every write and every
+// second by daemon of writer. RotatedName is called with base name of the
+// file, time the rotated file opened and count of logrotate starting from 1.
+type RotationPolicy interface {
+	ShouldRotate(info RotationInfo) bool
+	RotatedName(baseName string, t time.Time, count int) string
+}
+
+// SizePolicy rotates the file when size written reaches Size, file rotated
+// is named like xxx.1, xxx.2
+type SizePolicy struct {
+	Size int64
+}
+
+// ShouldRotate return true if size written reaches Size
+func (policy SizePolicy) ShouldRotate(info RotationInfo) bool {
+	return policy.Size > 0 && info.Size >= policy.Size
+}
+
+// RotatedName return name like xxx.1
+func (policy SizePolicy) RotatedName(baseName string, t time.Time, count int) string {
+	return fmt.Sprintf("%s.%d", baseName, count)
+}
+
+// LinePolicy rotates the file when lines written reaches Lines, file rotated
+// is named like xxx.1, xxx.2
+type LinePolicy struct {
+	Lines int
+}
+
+// ShouldRotate return true if lines written reaches Lines
+func (policy LinePolicy) ShouldRotate(info RotationInfo) bool {
+	return policy.Lines > 0 && info.Lines >= policy.Lines
+}
+
+// RotatedName return name like xxx.1
+func (policy LinePolicy) RotatedName(baseName string, t time.Time, count int) string {
+	return fmt.Sprintf("%s.%d", baseName, count)
+}
+
+// TimePolicy rotates the file when time formatted with Layout changes, file
+// rotated is named with time the file opened formatted, like xxx.2015-01-02.
+// Layout is DateFormat if empty, which rotates daily.
+type TimePolicy struct {
+	Layout string
+}
+
+// ShouldRotate return true if now and time the file opened are formatted
+// differently
+func (policy TimePolicy) ShouldRotate(info RotationInfo) bool {
+	layout := policy.layout()
+	return info.Now.Format(layout) != info.Opened.Format(layout)
+}
+
+// RotatedName return name like xxx.2015-01-02
+func (policy TimePolicy) RotatedName(baseName string, t time.Time, count int) string {
+	return fmt.Sprintf("%s.%s", baseName, t.Format(policy.layout()))
+}
+
+func (policy TimePolicy) layout() string {
+	if "" == policy.Layout {
+		return DateFormat
+	}
+	return policy.Layout
+}
+
+// AndPolicy rotates the file when all of the policies agree, file rotated
+// is named by the first policy
+type AndPolicy []RotationPolicy
+
+// ShouldRotate return true if all of the policies return true
+func (policy AndPolicy) ShouldRotate(info RotationInfo) bool {
+	if 0 == len(policy) {
+		return false
+	}
+
+	for _, p := range policy {
+		if !p.ShouldRotate(info) {
+			return false
+		}
+	}
+	return true
+}
+
+// RotatedName return name given by the first policy
+func (policy AndPolicy) RotatedName(baseName string, t time.Time, count int) string {
+	return rotatedName(policy, baseName, t, count)
+}
+
+// OrPolicy rotates the file when any of the policies agrees, file rotated
+// is named by the first policy
+type OrPolicy []RotationPolicy
+
+// ShouldRotate return true if any of the policies returns true
+func (policy OrPolicy) ShouldRotate(info RotationInfo) bool {
+	for _, p := range policy {
+		if p.ShouldRotate(info) {
+			return true
+		}
+	}
+	return false
+}
+
+// RotatedName return name given by the first policy
+func (policy OrPolicy) RotatedName(baseName string, t time.Time, count int) string {
+	return rotatedName(policy, baseName, t, count)
+}
+
+// rotatedName return name given by the first of policies, like xxx.1 if
+// policies is empty
+func rotatedName(policies []RotationPolicy, baseName string, t time.Time, count int) string {
+	if 0 == len(policies) {
+		return fmt.Sprintf("%s.%d", baseName, count)
+	}
+	return policies[0].RotatedName(baseName, t, count)
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotationPolicy(t *testing.T) {
+	opened := time.Date(2015, 1, 2, 23, 59, 0, 0, time.Local)
+	info := RotationInfo{Size: 100, Lines: 10, Opened: opened, Now: opened.Add(time.Minute)}
+
+	if !(SizePolicy{Size: 100}).ShouldRotate(info) || (SizePolicy{Size: 101}).ShouldRotate(info) || (SizePolicy{}).ShouldRotate(info) {
+		t.Error("size policy wrong")
+	}
+	if !(LinePolicy{Lines: 10}).ShouldRotate(info) || (LinePolicy{Lines: 11}).ShouldRotate(info) {
+		t.Error("line policy wrong")
+	}
+	if !(TimePolicy{}).ShouldRotate(info) || (TimePolicy{Layout: "2006-01"}).ShouldRotate(info) {
+		t.Error("time policy wrong")
+	}
+
+	if !(OrPolicy{SizePolicy{Size: 101}, LinePolicy{Lines: 10}}).ShouldRotate(info) || (OrPolicy{}).ShouldRotate(info) {
+		t.Error("or policy wrong")
+	}
+	if (AndPolicy{TimePolicy{}, SizePolicy{Size: 101}}).ShouldRotate(info) || !(AndPolicy{TimePolicy{}, SizePolicy{Size: 100}}).ShouldRotate(info) || (AndPolicy{}).ShouldRotate(info) {
+		t.Error("and policy wrong")
+	}
+
+	if name := (AndPolicy{TimePolicy{}, SizePolicy{}}).RotatedName("/tmp/x.log", opened, 3); "/tmp/x.log.2015-01-02" != name {
+		t.Errorf("and policy should be named by the first policy. name: %s", name)
+	}
+	if name := (OrPolicy{SizePolicy{}, TimePolicy{}}).RotatedName("/tmp/x.log", opened, 3); "/tmp/x.log.3" != name {
+		t.Errorf("or policy should be named by the first policy. name: %s", name)
+	}
+}
+
+func TestBaseFileWriterRotationPolicy(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/policy.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/policy.log")
+		os.Remove("/tmp/policy.log.1")
+	}()
+
+	writer.SetRotationPolicy(OrPolicy{SizePolicy{Size: MB}, LinePolicy{Lines: 2}})
+	writer.Info("first")
+	writer.Info("second")
+
+	for i := 0; i < 100; i++ {
+		if _, err = os.Stat("/tmp/policy.log.1"); nil == err {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	writer.Info("third")
+	writer.flush()
+	if content, err := ioutil.ReadFile("/tmp/policy.log.1"); nil != err || 2 != strings.Count(string(content), "\n") {
+		t.Errorf("file should be rotated by line policy. content: %q", content)
+	}
+	if content, err := ioutil.ReadFile("/tmp/policy.log"); nil != err || !strings.HasSuffix(string(content), "] third\n") {
+		t.Errorf("message should be written to the new file. content: %q", content)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// SchemaHeaderFormat is the format of the header line with schema
+	// version at the beginning of log files
+	SchemaHeaderFormat = "# blog4go schema v%d"
+)
+
+// schemaHeaderPrefix is the text ahead of version in the header line
+var schemaHeaderPrefix = SchemaHeaderFormat[:strings.Index(SchemaHeaderFormat, "%d")]
+
+// parseSchemaHeader return schema version of a header line, false if line
+// is not a header line
+func parseSchemaHeader(line string) (int, bool) {
+	line = strings.TrimRight(line, "\r\n")
+	if !strings.HasPrefix(line, schemaHeaderPrefix) {
+		return 0, false
+	}
+
+	version, err := strconv.Atoi(line[len(schemaHeaderPrefix):])
+	if nil != err || version < 1 {
+		return 0, false
+	}
+	return version, true
+}
+
+// DetectSchema return schema version in the header line of a log file read
+// from r, 0 if the first line is not a header line, like files written by
+// older versions. The first line of r is consumed.
+func DetectSchema(r io.Reader) (int, error) {
+	line, err := bufio.NewReader(r).ReadString(EOL)
+	if nil != err && io.EOF != err {
+		return 0, err
+	}
+
+	version, _ := parseSchemaHeader(line)
+	return version, nil
+}
+
+// SetSchemaVersion set schema version written in the header line like
+// "# blog4go schema v2" at the beginning of every new log file, including
+// files opened by logrotate. The header is written to the current file at
+// once if it is empty. Header is disabled if version is not positive.
+func (writer *baseFileWriter) SetSchemaVersion(version int) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	writer.schemaVersion = version
+	writer.writeSchemaHeader(writer.file)
+}
+
+// writeSchemaHeader writes the header line to file if it is empty, it must
+// be called with writer.lock held
+func (writer *baseFileWriter) writeSchemaHeader(file *os.File) {
+	if writer.schemaVersion < 1 || writer.inherited || nil == file {
+		return
+	}
+	if info, err := file.Stat(); nil != err || info.Size() > 0 || writer.blog.buffered() > 0 {
+		return
+	}
+
+	header := append([]byte(fmt.Sprintf(SchemaHeaderFormat, writer.schemaVersion)), writer.blog.EOL()...)
+	n, _ := writer.blog.writeRaw(header)
+	writer.currentSize += int64(n)
+}
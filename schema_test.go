@@ -0,0 +1,79 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDetectSchema(t *testing.T) {
+	cases := map[string]int{
+		"# blog4go schema v2\n[2017/06/30:12:00:00] [INFO] hello\n": 2,
+		"# blog4go schema v10\r\n":                                  10,
+		"# blog4go schema v2":                                       2,
+		"[2017/06/30:12:00:00] [INFO] hello\n":                      0,
+		"# blog4go schema vx\n":                                     0,
+		"":                                                          0,
+	}
+
+	for content, expected := range cases {
+		if version, err := DetectSchema(strings.NewReader(content)); nil != err || expected != version {
+			t.Errorf("schema detected wrong. content: %q, version: %d", content, version)
+		}
+	}
+}
+
+func TestBaseFileWriterSchemaVersion(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/schema.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/schema.log")
+		os.Remove("/tmp/schema.log.1")
+	}()
+
+	writer.SetSchemaVersion(2)
+	writer.Info("first")
+	// not the beginning of the file
+	writer.SetSchemaVersion(3)
+
+	writer.SetRetentions(1)
+	writer.Rotate()
+	writer.Info("second")
+	writer.flush()
+
+	for name, expected := range map[string]struct {
+		message string
+		version int
+	}{"/tmp/schema.log.1": {"first", 2}, "/tmp/schema.log": {"second", 3}} {
+		file, err := os.Open(name)
+		if nil != err {
+			t.Fatalf("open log failed. err: %s", err.Error())
+		}
+		defer file.Close()
+
+		parser := NewParser(file)
+		entry, err := parser.Next()
+		if nil != err || expected.message != entry.Message || expected.version != parser.SchemaVersion() {
+			t.Errorf("header line should be skipped by parser. file: %s, entry: %+v", name, entry)
+		}
+	}
+
+	content, _ := ioutil.ReadFile("/tmp/schema.log.1")
+	if !strings.HasPrefix(string(content), "# blog4go schema v2\n") || 1 != strings.Count(string(content), "schema") {
+		t.Errorf("header should be written at the beginning of the file. content: %q", content)
+	}
+	content, _ = ioutil.ReadFile("/tmp/schema.log")
+	if !strings.HasPrefix(string(content), "# blog4go schema v3\n") {
+		t.Errorf("header should be written to the file rotated. content: %q", content)
+	}
+
+	if report, err := ScanIntegrity("/tmp/schema.log"); nil != err || 0 != report.LinesCorrupt {
+		t.Errorf("header line should not be corrupt. report: %+v", report)
+	}
+}
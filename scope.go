@@ -0,0 +1,228 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+const (
+	// DefaultScopeFormat is the default format of scope names ahead of
+	// messages written by ScopedLogger
+	DefaultScopeFormat = "[%s]"
+)
+
+// Fields is a set of key value pairs bound to a scope
+type Fields map[string]interface{}
+
+// ScopedLogger wraps a writer, names of scopes and fields bound are
+// prepended to every message, like Scope("request", Fields{"id": 1}) writes
+// "[request] id=1 message". Scopes can be nested, fields of inner scope
+// override fields of outer scope with the same key.
+type ScopedLogger struct {
+	writerWrapper
+
+	names  []string
+	fields Fields
+	format string
+
+	// names and fields formatted
+	prefix string
+}
+
+// Scope create a ScopedLogger wrapping the file writer
+func (writer *baseFileWriter) Scope(name string, fields Fields) *ScopedLogger {
+	return newScopedLogger(writer, nil, nil, DefaultScopeFormat).Scope(name, fields)
+}
+
+// Scope create a ScopedLogger wrapping the singleton writer
+func Scope(name string, fields Fields) *ScopedLogger {
+	return newScopedLogger(blog, nil, nil, DefaultScopeFormat).Scope(name, fields)
+}
+
+// newScopedLogger create a ScopedLogger with names and fields bound
+func newScopedLogger(writer Writer, names []string, fields Fields, format string) *ScopedLogger {
+	scoped := new(ScopedLogger)
+	scoped.Writer = writer
+	scoped.self = scoped
+	scoped.names = names
+	scoped.fields = fields
+	scoped.format = format
+	scoped.prefix = formatScope(names, fields, format)
+	return scoped
+}
+
+// Scope create a ScopedLogger nested in scoped
+func (scoped *ScopedLogger) Scope(name string, fields Fields) *ScopedLogger {
+	names := append(append([]string(nil), scoped.names...), name)
+
+	merged := make(Fields, len(scoped.fields)+len(fields))
+	for key, val := range scoped.fields {
+		merged[key] = val
+	}
+	for key, val := range fields {
+		merged[key] = val
+	}
+	return newScopedLogger(scoped.Writer, names, merged, scoped.format)
+}
+
+// SetScopeFormat set format of every scope name, format must have exactly
+// one %s, default DefaultScopeFormat. Scopes nested later inherit it.
+func (scoped *ScopedLogger) SetScopeFormat(format string) error {
+	if !validAnnotationFormat(format) {
+		return ErrInvalidFormat
+	}
+
+	scoped.format = format
+	scoped.prefix = formatScope(scoped.names, scoped.fields, format)
+	return nil
+}
+
+// formatScope formats names and fields sorted by key, ended with a space
+func formatScope(names []string, fields Fields, format string) string {
+	prefix := make([]byte, 0, 64)
+	for _, name := range names {
+		prefix = append(prefix, fmt.Sprintf(format, name)...)
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		val := fmt.Sprint(fields[key])
+		prefix = append(prefix, ' ')
+		prefix = append(prefix, key...)
+		prefix = append(prefix, '=')
+		if needsQuote(val) {
+			prefix = strconv.AppendQuote(prefix, val)
+		} else {
+			prefix = append(prefix, val...)
+		}
+	}
+
+	if len(prefix) > 0 {
+		prefix = append(prefix, ' ')
+	}
+	return string(prefix)
+}
+
+func (scoped *ScopedLogger) write(level LevelType, args ...interface{}) {
+	scoped.Writer.write(level, scoped.prefix+fmt.Sprint(args...))
+}
+
+func (scoped *ScopedLogger) writef(level LevelType, format string, args ...interface{}) {
+	scoped.Writer.write(level, scoped.prefix+fmt.Sprintf(format, args...))
+}
+
+// Trace trace
+func (scoped *ScopedLogger) Trace(args ...interface{}) {
+	if TRACE < scoped.Level() {
+		return
+	}
+
+	scoped.write(TRACE, args...)
+}
+
+// Tracef tracef
+func (scoped *ScopedLogger) Tracef(format string, args ...interface{}) {
+	if TRACE < scoped.Level() {
+		return
+	}
+
+	scoped.writef(TRACE, format, args...)
+}
+
+// Debug debug
+func (scoped *ScopedLogger) Debug(args ...interface{}) {
+	if DEBUG < scoped.Level() {
+		return
+	}
+
+	scoped.write(DEBUG, args...)
+}
+
+// Debugf debugf
+func (scoped *ScopedLogger) Debugf(format string, args ...interface{}) {
+	if DEBUG < scoped.Level() {
+		return
+	}
+
+	scoped.writef(DEBUG, format, args...)
+}
+
+// Info info
+func (scoped *ScopedLogger) Info(args ...interface{}) {
+	if INFO < scoped.Level() {
+		return
+	}
+
+	scoped.write(INFO, args...)
+}
+
+// Infof infof
+func (scoped *ScopedLogger) Infof(format string, args ...interface{}) {
+	if INFO < scoped.Level() {
+		return
+	}
+
+	scoped.writef(INFO, format, args...)
+}
+
+// Warn warn
+func (scoped *ScopedLogger) Warn(args ...interface{}) {
+	if WARNING < scoped.Level() {
+		return
+	}
+
+	scoped.write(WARNING, args...)
+}
+
+// Warnf warnf
+func (scoped *ScopedLogger) Warnf(format string, args ...interface{}) {
+	if WARNING < scoped.Level() {
+		return
+	}
+
+	scoped.writef(WARNING, format, args...)
+}
+
+// Error error
+func (scoped *ScopedLogger) Error(args ...interface{}) {
+	if ERROR < scoped.Level() {
+		return
+	}
+
+	scoped.write(ERROR, args...)
+}
+
+// Errorf error
+func (scoped *ScopedLogger) Errorf(format string, args ...interface{}) {
+	if ERROR < scoped.Level() {
+		return
+	}
+
+	scoped.writef(ERROR, format, args...)
+}
+
+// Critical critical
+func (scoped *ScopedLogger) Critical(args ...interface{}) {
+	if CRITICAL < scoped.Level() {
+		return
+	}
+
+	scoped.write(CRITICAL, args...)
+}
+
+// Criticalf criticalf
+func (scoped *ScopedLogger) Criticalf(format string, args ...interface{}) {
+	if CRITICAL < scoped.Level() {
+		return
+	}
+
+	scoped.writef(CRITICAL, format, args...)
+}
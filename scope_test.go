@@ -0,0 +1,57 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestScopedLogger(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/scope.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/scope.log")
+	}()
+
+	tx := writer.Scope("tx:12345", nil)
+	tx.Info("begin")
+
+	db := writer.Scope("request", Fields{"user": "tom", "id": 1}).Scope("db", Fields{"id": 2, "sql": "select 1"})
+	db.Warnf("slow %dms", 300)
+
+	if ErrInvalidFormat != db.SetScopeFormat("<%d>") {
+		t.Error("scope format with other placeholders should fail")
+	}
+	db.SetScopeFormat("<%s>")
+	db.Scope("row", nil).Error("missing")
+
+	writer.SetLevel(ERROR)
+	tx.Info("filtered")
+	writer.flush()
+
+	data, err := ioutil.ReadFile("/tmp/scope.log")
+	if nil != err {
+		t.Fatalf("read log failed. err: %s", err.Error())
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	expected := []string{
+		"] [tx:12345] begin",
+		"] [request][db] id=2 sql=\"select 1\" user=tom slow 300ms",
+		"] <request><db><row> id=2 sql=\"select 1\" user=tom missing",
+	}
+	if len(expected) != len(lines) {
+		t.Fatalf("lines written wrong. lines: %v", lines)
+	}
+	for i, line := range lines {
+		if !strings.HasSuffix(line, expected[i]) {
+			t.Errorf("scoped message wrong. line: %s", line)
+		}
+	}
+}
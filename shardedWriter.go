@@ -0,0 +1,288 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+)
+
+var (
+	// ErrInvalidShards show that number of shards is not positive
+	ErrInvalidShards = errors.New("Shards must be greater than 0")
+)
+
+// ShardedWriter distributes messages across multiple files by hash of the
+// caller's source file, messages from the same source file always go to the
+// same shard. Every shard is a file writer with its own lock and logrotate.
+// Messages all go to the first shard if caller lookup is disabled by
+// -tags nocaller.
+type ShardedWriter struct {
+	writerBase
+
+	shards []*baseFileWriter
+}
+
+// NewShardedWriter create a ShardedWriter writing to files named like
+// basePattern.0.log, basePattern.1.log and so on
+func NewShardedWriter(basePattern string, shards int) (*ShardedWriter, error) {
+	if shards < 1 {
+		return nil, ErrInvalidShards
+	}
+
+	sharded := new(ShardedWriter)
+	sharded.self = sharded
+	for i := 0; i < shards; i++ {
+		shard, err := newBaseFileWriter(fmt.Sprintf("%s.%d.log", basePattern, i), false)
+		if nil != err {
+			sharded.Close()
+			return nil, err
+		}
+		sharded.shards = append(sharded.shards, shard)
+	}
+	return sharded, nil
+}
+
+// shard return the shard for the caller
+func (sharded *ShardedWriter) shard() *baseFileWriter {
+	if !callerEnabled || 1 == len(sharded.shards) {
+		return sharded.shards[0]
+	}
+
+	h := fnv.New32a()
+	io.WriteString(h, callerFile())
+	return sharded.shards[h.Sum32()%uint32(len(sharded.shards))]
+}
+
+// each calls fn with every shard
+func (sharded *ShardedWriter) each(fn func(w *baseFileWriter)) {
+	for _, shard := range sharded.shards {
+		fn(shard)
+	}
+}
+
+func (sharded *ShardedWriter) write(level LevelType, args ...interface{}) {
+	sharded.shard().write(level, args...)
+}
+
+func (sharded *ShardedWriter) writef(level LevelType, format string, args ...interface{}) {
+	sharded.shard().writef(level, format, args...)
+}
+
+// Level get level of the first shard
+func (sharded *ShardedWriter) Level() LevelType {
+	return sharded.shards[0].Level()
+}
+
+// SetLevel set logging level of shards
+func (sharded *ShardedWriter) SetLevel(level LevelType) {
+	sharded.each(func(w *baseFileWriter) { w.SetLevel(level) })
+}
+
+// SetHook set hook of shards, it is called by the shard written
+func (sharded *ShardedWriter) SetHook(hook Hook) {
+	sharded.each(func(w *baseFileWriter) { w.SetHook(hook) })
+}
+
+// SetHookAsync set hook async of shards
+func (sharded *ShardedWriter) SetHookAsync(async bool) {
+	sharded.each(func(w *baseFileWriter) { w.SetHookAsync(async) })
+}
+
+// SetHookLevel set when hook will be called of shards
+func (sharded *ShardedWriter) SetHookLevel(level LevelType) {
+	sharded.each(func(w *baseFileWriter) { w.SetHookLevel(level) })
+}
+
+// TimeRotated get timeRotated of the first shard
+func (sharded *ShardedWriter) TimeRotated() bool {
+	return sharded.shards[0].TimeRotated()
+}
+
+// SetTimeRotated toggle time base logrotate of shards
+func (sharded *ShardedWriter) SetTimeRotated(timeRotated bool) {
+	sharded.each(func(w *baseFileWriter) { w.SetTimeRotated(timeRotated) })
+}
+
+// Retentions get retentions of the first shard
+func (sharded *ShardedWriter) Retentions() int64 {
+	return sharded.shards[0].Retentions()
+}
+
+// SetRetentions set how many logs shards keep after logrotate
+func (sharded *ShardedWriter) SetRetentions(retentions int64) {
+	sharded.each(func(w *baseFileWriter) { w.SetRetentions(retentions) })
+}
+
+// RotateSize get rotateSize of the first shard
+func (sharded *ShardedWriter) RotateSize() int64 {
+	return sharded.shards[0].RotateSize()
+}
+
+// SetRotateSize set size when every shard logrotate
+func (sharded *ShardedWriter) SetRotateSize(rotateSize int64) {
+	sharded.each(func(w *baseFileWriter) { w.SetRotateSize(rotateSize) })
+}
+
+// RotateLines get rotateLines of the first shard
+func (sharded *ShardedWriter) RotateLines() int {
+	return sharded.shards[0].RotateLines()
+}
+
+// SetRotateLines set line number when every shard logrotate
+func (sharded *ShardedWriter) SetRotateLines(rotateLines int) {
+	sharded.each(func(w *baseFileWriter) { w.SetRotateLines(rotateLines) })
+}
+
+// Colored get colored of the first shard
+func (sharded *ShardedWriter) Colored() bool {
+	return sharded.shards[0].Colored()
+}
+
+// SetColored set logging color of shards
+func (sharded *ShardedWriter) SetColored(colored bool) {
+	sharded.each(func(w *baseFileWriter) { w.SetColored(colored) })
+}
+
+// SetEOL set end of every line of shards
+func (sharded *ShardedWriter) SetEOL(eol []byte) {
+	sharded.each(func(w *baseFileWriter) { w.SetEOL(eol) })
+}
+
+// AddMiddleware add a middleware to shards
+func (sharded *ShardedWriter) AddMiddleware(middleware Middleware) {
+	sharded.each(func(w *baseFileWriter) { w.AddMiddleware(middleware) })
+}
+
+// PipeFromWithContext logs every line read from r with given level until r
+// reaches EOF or ctx is done, lines piped always go to the same shard
+func (sharded *ShardedWriter) PipeFromWithContext(ctx context.Context, r io.Reader, level LevelType) error {
+	go pipe(ctx, sharded, r, level)
+	return nil
+}
+
+// Close close every shard
+func (sharded *ShardedWriter) Close() {
+	sharded.each(func(w *baseFileWriter) { w.Close() })
+}
+
+// BeginShutdown begin shutdown of shards
+func (sharded *ShardedWriter) BeginShutdown() {
+	sharded.each(func(w *baseFileWriter) { w.BeginShutdown() })
+}
+
+// flush flush shards
+func (sharded *ShardedWriter) flush() {
+	sharded.each(func(w *baseFileWriter) { w.flush() })
+}
+
+// Trace trace
+func (sharded *ShardedWriter) Trace(args ...interface{}) {
+	if TRACE < sharded.Level() {
+		return
+	}
+
+	sharded.write(TRACE, args...)
+}
+
+// Tracef tracef
+func (sharded *ShardedWriter) Tracef(format string, args ...interface{}) {
+	if TRACE < sharded.Level() {
+		return
+	}
+
+	sharded.writef(TRACE, format, args...)
+}
+
+// Debug debug
+func (sharded *ShardedWriter) Debug(args ...interface{}) {
+	if DEBUG < sharded.Level() {
+		return
+	}
+
+	sharded.write(DEBUG, args...)
+}
+
+// Debugf debugf
+func (sharded *ShardedWriter) Debugf(format string, args ...interface{}) {
+	if DEBUG < sharded.Level() {
+		return
+	}
+
+	sharded.writef(DEBUG, format, args...)
+}
+
+// Info info
+func (sharded *ShardedWriter) Info(args ...interface{}) {
+	if INFO < sharded.Level() {
+		return
+	}
+
+	sharded.write(INFO, args...)
+}
+
+// Infof infof
+func (sharded *ShardedWriter) Infof(format string, args ...interface{}) {
+	if INFO < sharded.Level() {
+		return
+	}
+
+	sharded.writef(INFO, format, args...)
+}
+
+// Warn warn
+func (sharded *ShardedWriter) Warn(args ...interface{}) {
+	if WARNING < sharded.Level() {
+		return
+	}
+
+	sharded.write(WARNING, args...)
+}
+
+// Warnf warnf
+func (sharded *ShardedWriter) Warnf(format string, args ...interface{}) {
+	if WARNING < sharded.Level() {
+		return
+	}
+
+	sharded.writef(WARNING, format, args...)
+}
+
+// Error error
+func (sharded *ShardedWriter) Error(args ...interface{}) {
+	if ERROR < sharded.Level() {
+		return
+	}
+
+	sharded.write(ERROR, args...)
+}
+
+// Errorf error
+func (sharded *ShardedWriter) Errorf(format string, args ...interface{}) {
+	if ERROR < sharded.Level() {
+		return
+	}
+
+	sharded.writef(ERROR, format, args...)
+}
+
+// Critical critical
+func (sharded *ShardedWriter) Critical(args ...interface{}) {
+	if CRITICAL < sharded.Level() {
+		return
+	}
+
+	sharded.write(CRITICAL, args...)
+}
+
+// Criticalf criticalf
+func (sharded *ShardedWriter) Criticalf(format string, args ...interface{}) {
+	if CRITICAL < sharded.Level() {
+		return
+	}
+
+	sharded.writef(CRITICAL, format, args...)
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestShardedWriter(t *testing.T) {
+	if _, err := NewShardedWriter("/tmp/sharded", 0); ErrInvalidShards != err {
+		t.Error("shards not positive should fail")
+	}
+
+	sharded, err := NewShardedWriter("/tmp/sharded", 3)
+	if nil != err {
+		t.Fatalf("initialize sharded writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		for i := 0; i < 3; i++ {
+			os.Remove(fmt.Sprintf("/tmp/sharded.%d.log", i))
+		}
+	}()
+
+	sharded.SetLevel(INFO)
+	sharded.Debug("filtered")
+	for i := 0; i < 10; i++ {
+		sharded.Infof("message %d", i)
+	}
+	sharded.Close()
+
+	// messages from the same source file go to the same shard
+	written := 0
+	for i := 0; i < 3; i++ {
+		content, err := ioutil.ReadFile(fmt.Sprintf("/tmp/sharded.%d.log", i))
+		if nil != err {
+			t.Fatalf("shard should be created. err: %s", err.Error())
+		}
+		if len(content) > 0 {
+			written++
+			if 10 != strings.Count(string(content), "] message ") {
+				t.Errorf("every message should be written to the same shard. content: %q", content)
+			}
+		}
+	}
+	if 1 != written {
+		t.Errorf("messages should be written to a single shard. shards written: %d", written)
+	}
+}
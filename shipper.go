@@ -0,0 +1,318 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// PositionSuffix is the suffix of sidecar file which stores the file
+	// shipped and the position in it
+	PositionSuffix = ".pos"
+
+	// DefaultShipInterval is the default interval between shippings
+	DefaultShipInterval = 1 * time.Second
+)
+
+var (
+	// ErrInvalidBatchSize invalid batch size
+	ErrInvalidBatchSize = errors.New("Batch size must be greater than 0")
+)
+
+// shipper tails the log file and posts new lines to endpoint as json lines,
+// one entry in json form every line. Position shipped is stored in the
+// sidecar file so that shipping continues after restart.
+type shipper struct {
+	writer *baseFileWriter
+
+	endpoint  string
+	batchSize int
+	client    *http.Client
+
+	// sidecar file stores "<fileName> <offset>"
+	positionFile string
+	// file shipped
+	fileName string
+	// position shipped in the file
+	offset int64
+	// file shipped opened, kept open to finish it after logrotate
+	file *os.File
+
+	// parser decodes lines into entries
+	parser *Parser
+}
+
+// StartShipper starts a goroutine shipping new lines of the log file to the
+// http endpoint every interval, in batches of at most batchSize entries
+// posted as json lines. Position shipped is stored in the sidecar file named
+// with PositionSuffix. Calling stop ships lines left and waits the goroutine
+// to exit. Batch failed is retried in the next interval. It is not supported
+// if file descriptor inherited.
+func (writer *baseFileWriter) StartShipper(endpoint string, batchSize int, interval time.Duration) (stop func(), err error) {
+	if writer.inherited {
+		return nil, ErrNotSupported
+	}
+	if batchSize < 1 {
+		return nil, ErrInvalidBatchSize
+	}
+	if interval <= 0 {
+		interval = DefaultShipInterval
+	}
+
+	s := new(shipper)
+	s.writer = writer
+	s.endpoint = endpoint
+	s.batchSize = batchSize
+	s.client = &http.Client{Timeout: 10 * time.Second}
+	s.positionFile = writer.fileName + PositionSuffix
+	s.parser = &Parser{}
+	s.parser.SetAnnotationFormat(writer.annotationFormat)
+	s.loadPosition()
+
+	stopSig := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				s.ship()
+			case <-stopSig:
+				s.ship()
+				s.close()
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(stopSig)
+			<-done
+		})
+	}, nil
+}
+
+// shipperPosition is the file shipped and the position in it, stored in the
+// sidecar file as json, so that file names with spaces are kept as they are
+type shipperPosition struct {
+	FileName string `json:"file_name"`
+	Offset   int64  `json:"offset"`
+}
+
+// loadPosition loads the file shipped and position from the sidecar file
+func (s *shipper) loadPosition() {
+	data, err := ioutil.ReadFile(s.positionFile)
+	if nil != err {
+		return
+	}
+
+	var position shipperPosition
+	if err = json.Unmarshal(data, &position); nil == err {
+		s.fileName = position.FileName
+		s.offset = position.Offset
+	}
+}
+
+// savePosition stores the file shipped and position to the sidecar file
+func (s *shipper) savePosition() error {
+	data, err := json.Marshal(shipperPosition{FileName: s.fileName, Offset: s.offset})
+	if nil != err {
+		return err
+	}
+	return ioutil.WriteFile(s.positionFile, append(data, '\n'), os.FileMode(0644))
+}
+
+// ship posts new lines of the log file
+func (s *shipper) ship() {
+	s.writer.lock.RLock()
+	closed := s.writer.closed
+	current := s.writer.currentFileName
+	s.writer.lock.RUnlock()
+
+	if !closed {
+		s.writer.flush()
+	}
+
+	if nil == s.file {
+		if "" == s.fileName {
+			s.fileName = current
+		}
+		err := s.open()
+		if os.IsNotExist(err) && s.fileName != current {
+			// file shipped before restart is gone
+			s.switchTo(current)
+			err = s.open()
+		}
+		if nil != err {
+			return
+		}
+	}
+
+	if err := s.shipFile(); nil != err {
+		return
+	}
+
+	if s.rotated(current) {
+		// lines written to the file shipped before logrotate
+		if err := s.shipFile(); nil != err {
+			return
+		}
+		s.switchTo(current)
+		if nil == s.open() {
+			s.shipFile()
+		}
+	}
+}
+
+// open opens the file shipped
+func (s *shipper) open() error {
+	file, err := os.Open(s.fileName)
+	if nil != err {
+		return err
+	}
+
+	s.file = file
+	return nil
+}
+
+// close closes the file shipped
+func (s *shipper) close() {
+	if nil != s.file {
+		s.file.Close()
+		s.file = nil
+	}
+}
+
+// switchTo ships file from the beginning instead of the file shipped
+func (s *shipper) switchTo(fileName string) {
+	s.close()
+	s.fileName = fileName
+	s.offset = 0
+}
+
+// rotated determines whether the file shipped is renamed by logrotate or
+// another file is written instead
+func (s *shipper) rotated(current string) bool {
+	if s.fileName != current {
+		return true
+	}
+
+	info, err := os.Stat(current)
+	if nil != err {
+		// not created yet after renamed
+		return false
+	}
+	opened, err := s.file.Stat()
+	if nil != err {
+		return false
+	}
+	return !os.SameFile(opened, info)
+}
+
+// shipFile posts new lines of the file shipped in batches
+func (s *shipper) shipFile() error {
+	info, err := s.file.Stat()
+	if nil != err {
+		return err
+	}
+
+	// file truncated
+	if info.Size() < s.offset {
+		s.offset = 0
+	}
+
+	// line not ended read last time is read again
+	if _, err = s.file.Seek(s.offset, io.SeekStart); nil != err {
+		return err
+	}
+
+	reader := bufio.NewReader(s.file)
+	for {
+		entries, size := s.readBatch(reader)
+		if 0 == len(entries) {
+			return nil
+		}
+
+		if err = s.post(entries); nil != err {
+			return err
+		}
+
+		s.offset += size
+		if err = s.savePosition(); nil != err {
+			return err
+		}
+	}
+}
+
+// readBatch reads at most batchSize entries of complete lines, continuation
+// lines are joined to the entry before. It returns size of lines read.
+func (s *shipper) readBatch(reader *bufio.Reader) (entries []*Entry, size int64) {
+	for {
+		// continuation lines of the last entry may follow
+		if len(entries) >= s.batchSize {
+			// color may be ahead of timestamp
+			next, _ := reader.Peek(maxColorSize + len(PrefixTimeFormat))
+			if len(next) < len(PrefixTimeFormat) {
+				return entries, size
+			}
+			if _, ok := lineTime(next); ok {
+				return entries, size
+			}
+		}
+
+		line, err := reader.ReadBytes(EOL)
+		if nil != err {
+			// line not ended is shipped next time
+			return entries, size
+		}
+		size += int64(len(line))
+
+		text := string(bytes.TrimRight(line, "\r\n"))
+		if entry, ok := s.parser.parseLine(text); ok {
+			entries = append(entries, entry)
+		} else if 0 != len(entries) {
+			entries[len(entries)-1].appendLine(text)
+		} else {
+			entries = append(entries, &Entry{Level: LevelType(-1), Message: text})
+		}
+	}
+}
+
+// post posts entries as json lines
+func (s *shipper) post(entries []*Entry) error {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	for _, entry := range entries {
+		if err := encoder.Encode(newJSONEntry(entry)); nil != err {
+			return err
+		}
+	}
+
+	resp, err := s.client.Post(s.endpoint, "application/x-ndjson", &buffer)
+	if nil != err {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Ship failed, status %s", resp.Status)
+	}
+	return nil
+}
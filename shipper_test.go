@@ -0,0 +1,170 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestShipper(t *testing.T) {
+	if _, err := StartShipper("http://127.0.0.1/", 10, time.Second); ErrNotSupported != err {
+		t.Error("shipper without file writer should fail")
+	}
+
+	var lock sync.Mutex
+	var entries []*Entry
+	var batches int
+	fail := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		// the first batch fails and is retried
+		if fail {
+			fail = false
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		batches++
+		reader := NewJSONLReader(r.Body)
+		for {
+			entry, err := reader.Next()
+			if nil != err {
+				break
+			}
+			entries = append(entries, entry)
+		}
+	}))
+	defer server.Close()
+
+	err := NewBaseFileWriter("/tmp/shipper.log", false)
+	defer func() {
+		Close()
+		os.Remove("/tmp/shipper.log")
+		os.Remove("/tmp/shipper.log" + PositionSuffix)
+	}()
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+
+	if _, err = StartShipper(server.URL, 0, time.Second); ErrInvalidBatchSize != err {
+		t.Error("zero batch size should fail")
+	}
+
+	stop, err := StartShipper(server.URL, 2, 10*time.Millisecond)
+	if nil != err {
+		t.Fatalf("start shipper failed. err: %s", err.Error())
+	}
+
+	SetColored(false)
+	Info("first")
+	Error("second\nstack trace")
+	Warn("third")
+	time.Sleep(100 * time.Millisecond)
+	Info("last")
+	stop()
+	stop()
+
+	lock.Lock()
+	if 4 != len(entries) || "first" != entries[0].Message || ERROR != entries[1].Level ||
+		"second\nstack trace" != entries[1].Message || "last" != entries[3].Message || batches < 3 {
+		t.Errorf("entries shipped wrong. batches: %d, entries: %v", batches, entries)
+	}
+	lock.Unlock()
+
+	content, _ := ioutil.ReadFile("/tmp/shipper.log")
+	data, _ := ioutil.ReadFile("/tmp/shipper.log" + PositionSuffix)
+	var position shipperPosition
+	if err = json.Unmarshal(data, &position); nil != err || "/tmp/shipper.log" != position.FileName || int64(len(content)) != position.Offset {
+		t.Errorf("position stored wrong. position: %s, size: %d", data, len(content))
+	}
+
+	// shipping continues from position stored
+	stop, _ = StartShipper(server.URL, 2, 10*time.Millisecond)
+	stop()
+
+	lock.Lock()
+	defer lock.Unlock()
+	if 4 != len(entries) {
+		t.Errorf("entries should not be shipped again. entries: %v", entries)
+	}
+}
+
+func TestShipperLogrotate(t *testing.T) {
+	var lock sync.Mutex
+	var entries []*Entry
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		reader := NewJSONLReader(r.Body)
+		for {
+			entry, err := reader.Next()
+			if nil != err {
+				break
+			}
+			entries = append(entries, entry)
+		}
+	}))
+	defer server.Close()
+
+	writer, err := newBaseFileWriter("/tmp/shipperRotate.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/shipperRotate.log")
+		os.Remove("/tmp/shipperRotate.log.1")
+		os.Remove("/tmp/shipperRotate.log" + PositionSuffix)
+	}()
+	writer.SetRotateLines(2)
+	writer.SetRetentions(3)
+
+	s := &shipper{writer: writer, endpoint: server.URL, batchSize: 10, client: http.DefaultClient,
+		positionFile: "/tmp/shipperRotate.log" + PositionSuffix, parser: &Parser{}}
+	defer s.close()
+	s.ship()
+
+	writer.Info("first")
+	writer.Info("second")
+	// wait for lines base logrotate
+	for i := 0; i < 100; i++ {
+		if _, err = os.Stat("/tmp/shipperRotate.log.1"); nil == err {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	writer.Info("third")
+	s.ship()
+
+	lock.Lock()
+	defer lock.Unlock()
+	if 3 != len(entries) || "first" != entries[0].Message || "second" != entries[1].Message || "third" != entries[2].Message {
+		t.Errorf("lines left before logrotate should be shipped. entries: %v", entries)
+	}
+}
+
+func TestShipperPositionWithSpace(t *testing.T) {
+	positionFile := "/tmp/shipper position.log" + PositionSuffix
+	defer os.Remove(positionFile)
+
+	s := &shipper{positionFile: positionFile, fileName: "/tmp/shipper position.log.1", offset: 42}
+	if err := s.savePosition(); nil != err {
+		t.Fatalf("save position failed. err: %s", err.Error())
+	}
+
+	loaded := &shipper{positionFile: positionFile}
+	loaded.loadPosition()
+	if "/tmp/shipper position.log.1" != loaded.fileName || 42 != loaded.offset {
+		t.Errorf("position loaded wrong. file: %s, offset: %d", loaded.fileName, loaded.offset)
+	}
+}
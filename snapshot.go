@@ -0,0 +1,115 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrSnapshotEnabled snapshot already enabled
+	ErrSnapshotEnabled = errors.New("Snapshot already enabled")
+
+	// DefaultSnapshotCapacity is the default number of the latest lines kept
+	// by snapshot
+	DefaultSnapshotCapacity = 10000
+)
+
+// snapshotBuffer keeps the latest lines written in a ring
+type snapshotBuffer struct {
+	lines []string
+	// position of the oldest line
+	head int
+	// number of lines kept
+	count int
+
+	lock *sync.Mutex
+}
+
+// newSnapshotBuffer create a snapshotBuffer keeping capacity lines
+func newSnapshotBuffer(capacity int) *snapshotBuffer {
+	snapshot := new(snapshotBuffer)
+	snapshot.lines = make([]string, capacity)
+	snapshot.lock = new(sync.Mutex)
+	return snapshot
+}
+
+// add appends line, the oldest one is dropped if full
+func (snapshot *snapshotBuffer) add(line string) {
+	snapshot.lock.Lock()
+	defer snapshot.lock.Unlock()
+
+	if snapshot.count < len(snapshot.lines) {
+		snapshot.lines[(snapshot.head+snapshot.count)%len(snapshot.lines)] = line
+		snapshot.count++
+		return
+	}
+
+	snapshot.lines[snapshot.head] = line
+	snapshot.head = (snapshot.head + 1) % len(snapshot.lines)
+}
+
+// collect return lines kept in order they are written
+func (snapshot *snapshotBuffer) collect() []string {
+	snapshot.lock.Lock()
+	defer snapshot.lock.Unlock()
+
+	lines := make([]string, 0, snapshot.count)
+	for i := 0; i < snapshot.count; i++ {
+		lines = append(lines, snapshot.lines[(snapshot.head+i)%len(snapshot.lines)])
+	}
+	return lines
+}
+
+// SetSnapshotCapacity set number of the latest lines kept by snapshots
+// enabled later, default DefaultSnapshotCapacity
+func (writer *baseFileWriter) SetSnapshotCapacity(n int) error {
+	if n < 1 {
+		return ErrInvalidCapacity
+	}
+
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.snapshotCapacity = n
+	return nil
+}
+
+// EnableSnapshot starts collecting lines written, as bytes written to the
+// file without EOL, without reading the file. Calling the returned
+// function stops collecting and returns lines collected, like in tests
+// asserting on output of a code block. Only the latest lines are kept, see
+// SetSnapshotCapacity.
+func (writer *baseFileWriter) EnableSnapshot() (func() []string, error) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	if writer.closed {
+		return nil, ErrWriterClosed
+	}
+	if nil != writer.snapshot {
+		return nil, ErrSnapshotEnabled
+	}
+
+	capacity := writer.snapshotCapacity
+	if capacity < 1 {
+		capacity = DefaultSnapshotCapacity
+	}
+	snapshot := newSnapshotBuffer(capacity)
+	writer.snapshot = snapshot
+	// bytes written are collected, after middlewares, truncation and line
+	// wrap
+	writer.blog.setTee(snapshot.add)
+
+	return func() []string {
+		writer.lock.Lock()
+		if snapshot == writer.snapshot {
+			writer.snapshot = nil
+			if nil != writer.blog {
+				writer.blog.setTee(nil)
+			}
+		}
+		writer.lock.Unlock()
+		return snapshot.collect()
+	}, nil
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/snapshot.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/snapshot.log")
+	}()
+
+	writer.Info("before")
+	if ErrInvalidCapacity != writer.SetSnapshotCapacity(0) {
+		t.Error("non-positive capacity should fail")
+	}
+	writer.SetSnapshotCapacity(3)
+
+	snapshot, err := writer.EnableSnapshot()
+	if nil != err {
+		t.Fatalf("enable snapshot failed. err: %s", err.Error())
+	}
+	if _, err := writer.EnableSnapshot(); ErrSnapshotEnabled != err {
+		t.Error("snapshot enabled twice should fail")
+	}
+
+	writer.Info("dropped")
+	writer.Warnf("code %d", 1)
+	writer.WriteBatch([]LogEntry{{Level: ERROR, Message: "batch"}})
+	writer.Critical("last")
+
+	lines := snapshot()
+	writer.Info("after")
+	expected := []string{"[WARN] code 1", "[ERROR] batch", "[CRITICAL] last"}
+	if len(expected) != len(lines) {
+		t.Fatalf("lines collected wrong. lines: %v", lines)
+	}
+	for i, line := range lines {
+		if !strings.HasSuffix(line, expected[i]) {
+			t.Errorf("line collected wrong. line: %s", line)
+		}
+	}
+
+	if lines = snapshot(); 3 != len(lines) {
+		t.Errorf("lines should not be collected after stopped. lines: %v", lines)
+	}
+	if _, err := writer.EnableSnapshot(); nil != err {
+		t.Errorf("enable snapshot again failed. err: %s", err.Error())
+	}
+}
+
+func TestSnapshotBytesWritten(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/snapshot.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/snapshot.log")
+	}()
+
+	writer.AddMiddleware(func(level LevelType, message string) string {
+		return strings.Replace(message, "secret", "***", -1)
+	})
+	writer.SetMaxMessageSize(24)
+
+	snapshot, err := writer.EnableSnapshot()
+	if nil != err {
+		t.Fatalf("enable snapshot failed. err: %s", err.Error())
+	}
+
+	writer.Info("password secret")
+	writer.Infof("token %s", "secret")
+	writer.Info(strings.Repeat("x", 30))
+	writer.flush()
+
+	lines := snapshot()
+	content, err := ioutil.ReadFile("/tmp/snapshot.log")
+	if nil != err {
+		t.Fatalf("read log failed. err: %s", err.Error())
+	}
+	if written := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n"); strings.Join(written, "|") != strings.Join(lines, "|") {
+		t.Errorf("lines collected should be bytes written. lines: %v, written: %v", lines, written)
+	}
+	if 3 != len(lines) || !strings.HasSuffix(lines[0], "] password ***") || !strings.HasSuffix(lines[2], MessageTruncateMarker) {
+		t.Errorf("lines collected wrong. lines: %v", lines)
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrDisconnected show that the connection is broken and not redialed yet
+	ErrDisconnected = errors.New("Socket disconnected")
+
+	// RedialTimeout is the timeout of redialing a connection broken
+	RedialTimeout = 1 * time.Second
+)
+
+// socketConn is a connection of socket writer. It redials every second in
+// daemon once disconnected, messages are kept in offline buffer while
+// disconnected if enabled, otherwise dropped. Messages are written to the
+// connection at once, or buffered and flushed every second if buffered.
+type socketConn struct {
+	network string
+	address string
+
+	// nil if disconnected
+	conn net.Conn
+	// where messages are written, the connection or buffer of it
+	out    io.Writer
+	writer *bufio.Writer
+
+	// messages kept while disconnected, disabled if nil
+	offline *offlineBuffer
+
+	closed bool
+
+	lock *sync.Mutex
+}
+
+// dialSocketConn creates a connection and starts daemon
+func dialSocketConn(network string, address string, buffered bool) (c *socketConn, err error) {
+	conn, err := net.Dial(network, address)
+	if nil != err {
+		return nil, err
+	}
+
+	c = new(socketConn)
+	c.network = network
+	c.address = address
+	c.conn = conn
+	c.out = conn
+	if buffered {
+		c.writer = bufio.NewWriterSize(conn, DefaultBufferSize)
+		c.out = c.writer
+	}
+	c.closed = false
+	c.lock = new(sync.Mutex)
+
+	go c.daemon()
+	return c, nil
+}
+
+// streamNetwork determines whether network is stream oriented, messages in
+// datagrams can not be buffered
+func streamNetwork(network string) bool {
+	return strings.HasPrefix(network, "tcp") || "unix" == network
+}
+
+// daemon flushes buffer every second, and redials if disconnected
+func (c *socketConn) daemon() {
+	f := time.NewTicker(1 * time.Second)
+	defer f.Stop()
+
+DaemonLoop:
+	for {
+		select {
+		case <-f.C:
+			c.lock.Lock()
+			if c.closed {
+				c.lock.Unlock()
+				break DaemonLoop
+			}
+
+			disconnected := nil == c.conn
+			if !disconnected {
+				c.flushLocked()
+			}
+			c.lock.Unlock()
+
+			if disconnected {
+				c.redial()
+			}
+		}
+	}
+}
+
+// redial reconnects and writes messages in offline buffer first. It dials
+// without lock held, so that writes are not blocked while dialing.
+func (c *socketConn) redial() {
+	conn, err := net.DialTimeout(c.network, c.address, RedialTimeout)
+	if nil != err {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed || nil != c.conn {
+		conn.Close()
+		return
+	}
+
+	c.conn = conn
+	c.out = conn
+	if nil != c.writer {
+		c.writer.Reset(conn)
+		c.out = c.writer
+	}
+
+	if nil == c.offline {
+		return
+	}
+	for p := c.offline.peek(); nil != p; p = c.offline.peek() {
+		if _, err := c.out.Write(p); nil != err {
+			c.disconnect()
+			return
+		}
+		c.offline.pop()
+	}
+}
+
+// write writes message, kept in offline buffer or dropped if disconnected
+func (c *socketConn) write(p []byte) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return
+	}
+
+	if nil == c.conn {
+		if nil != c.offline {
+			c.offline.push(p)
+		}
+		return
+	}
+
+	if _, err := c.out.Write(p); nil != err {
+		c.disconnect()
+		if nil != c.offline {
+			c.offline.push(p)
+		}
+	}
+}
+
+// tryWrite writes message, ErrDisconnected is returned if disconnected
+func (c *socketConn) tryWrite(p []byte) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return ErrWriterClosed
+	}
+
+	if nil == c.conn {
+		return ErrDisconnected
+	}
+
+	if _, err := c.out.Write(p); nil != err {
+		c.disconnect()
+		return err
+	}
+	return nil
+}
+
+// setOfflineBuffer set offline buffer, messages kept in the buffer before
+// are dropped
+func (c *socketConn) setOfflineBuffer(offline *offlineBuffer) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.offline = offline
+}
+
+// offlineStats get number of messages in offline buffer and number of
+// messages dropped since buffer full
+func (c *socketConn) offlineStats() (buffered int, dropped int64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if nil == c.offline {
+		return 0, 0
+	}
+	return len(c.offline.entries), c.offline.dropped
+}
+
+// flush flushes buffer to the connection
+func (c *socketConn) flush() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.flushLocked()
+}
+
+// flushLocked flushes buffer with lock held
+func (c *socketConn) flushLocked() {
+	if nil == c.conn || nil == c.writer {
+		return
+	}
+
+	if err := c.writer.Flush(); nil != err {
+		c.disconnect()
+	}
+}
+
+// disconnect closes the broken connection, messages buffered are dropped
+func (c *socketConn) disconnect() {
+	c.conn.Close()
+	c.conn = nil
+	c.out = nil
+	if nil != c.writer {
+		c.writer.Reset(nil)
+	}
+}
+
+// close flushes buffer and closes the connection
+func (c *socketConn) close() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.closed {
+		return
+	}
+
+	c.flushLocked()
+	if nil != c.conn {
+		c.conn.Close()
+		c.conn = nil
+	}
+	c.closed = true
+}
+
+// offlineBuffer keeps at most max messages in chronological order, the
+// oldest one is dropped if full
+type offlineBuffer struct {
+	entries [][]byte
+	max     int
+	// messages dropped since buffer full
+	dropped int64
+}
+
+// newOfflineBuffer create an offlineBuffer keeping at most max messages
+func newOfflineBuffer(max int) *offlineBuffer {
+	return &offlineBuffer{max: max}
+}
+
+// push appends a copy of p, drops the oldest one if full
+func (buffer *offlineBuffer) push(p []byte) {
+	if len(buffer.entries) >= buffer.max {
+		buffer.entries = buffer.entries[1:]
+		buffer.dropped++
+	}
+	buffer.entries = append(buffer.entries, append([]byte(nil), p...))
+}
+
+// peek return the oldest message, nil if empty
+func (buffer *offlineBuffer) peek() []byte {
+	if 0 == len(buffer.entries) {
+		return nil
+	}
+	return buffer.entries[0]
+}
+
+// pop removes the oldest message
+func (buffer *offlineBuffer) pop() {
+	buffer.entries[0] = nil
+	buffer.entries = buffer.entries[1:]
+}
@@ -4,13 +4,17 @@ package blog4go
 
 import (
 	"bytes"
+	"context"
 	"fmt"
-	"net"
+	"io"
 	"sync"
+	"sync/atomic"
 )
 
 // SocketWriter is a socket logger
 type SocketWriter struct {
+	writerBase
+
 	level LevelType
 
 	closed bool
@@ -21,9 +25,27 @@ type SocketWriter struct {
 	hookAsync bool
 
 	// socket
-	writer net.Conn
+	writer  *socketConn
+	network string
+	address string
+
+	// buffered connections messages distributed across in round-robin
+	// order, the socket is used if empty
+	pool []*socketConn
+	// sequence of the next message in round-robin order
+	next uint32
+
+	// max number of messages kept by every connection while disconnected,
+	// offline buffer is disabled if not positive
+	offlineMax int
 
-	lock *sync.Mutex
+	// end of every message, default none
+	eol []byte
+
+	// middlewares applied to message before written
+	middlewareList
+
+	lock *sync.RWMutex
 }
 
 // NewSocketWriter creates a socket writer, singlton
@@ -46,27 +68,30 @@ func NewSocketWriter(network string, address string) (err error) {
 // newSocketWriter creates a socket writer, not singlton
 func newSocketWriter(network string, address string) (socketWriter *SocketWriter, err error) {
 	socketWriter = new(SocketWriter)
+	socketWriter.self = socketWriter
 	socketWriter.level = DEBUG
 	socketWriter.closed = false
-	socketWriter.lock = new(sync.Mutex)
+	socketWriter.lock = new(sync.RWMutex)
 
 	// log hook
 	socketWriter.hook = nil
 	socketWriter.hookLevel = DEBUG
 
-	conn, err := net.Dial(network, address)
+	conn, err := dialSocketConn(network, address, false)
 	if nil != err {
 		return nil, err
 	}
 	socketWriter.writer = conn
+	socketWriter.network = network
+	socketWriter.address = address
 
 	blog = socketWriter
 	return socketWriter, nil
 }
 
 func (writer *SocketWriter) write(level LevelType, args ...interface{}) {
-	writer.lock.Lock()
-	defer writer.lock.Unlock()
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
 
 	if writer.closed {
 		return
@@ -89,13 +114,14 @@ func (writer *SocketWriter) write(level LevelType, args ...interface{}) {
 
 	buffer := bytes.NewBuffer(timeCache.Format())
 	buffer.WriteString(level.prefix())
-	buffer.WriteString(fmt.Sprint(args...))
-	writer.writer.Write(buffer.Bytes())
+	buffer.WriteString(writer.applyMiddlewares(level, fmt.Sprint(args...)))
+	buffer.Write(writer.eol)
+	writer.send(buffer.Bytes())
 }
 
 func (writer *SocketWriter) writef(level LevelType, format string, args ...interface{}) {
-	writer.lock.Lock()
-	defer writer.lock.Unlock()
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
 
 	if writer.closed {
 		return
@@ -118,8 +144,150 @@ func (writer *SocketWriter) writef(level LevelType, format string, args ...inter
 
 	buffer := bytes.NewBuffer(timeCache.Format())
 	buffer.WriteString(level.prefix())
-	buffer.WriteString(fmt.Sprintf(format, args...))
-	writer.writer.Write(buffer.Bytes())
+	buffer.WriteString(writer.applyMiddlewares(level, fmt.Sprintf(format, args...)))
+	buffer.Write(writer.eol)
+	writer.send(buffer.Bytes())
+}
+
+// writeErr writes message and reports failure instead of keeping it in
+// offline buffer, so that caller can write it elsewhere
+func (writer *SocketWriter) writeErr(level LevelType, message string) error {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+
+	if writer.closed {
+		return ErrWriterClosed
+	}
+
+	buffer := bytes.NewBuffer(timeCache.Format())
+	buffer.WriteString(level.prefix())
+	buffer.WriteString(writer.applyMiddlewares(level, message))
+	buffer.Write(writer.eol)
+	if err := writer.conn().tryWrite(buffer.Bytes()); nil != err {
+		return err
+	}
+
+	// call log hook
+	if nil != writer.hook && !(level < writer.hookLevel) {
+		if writer.hookAsync {
+			go writer.hook.Fire(level, message)
+		} else {
+			writer.hook.Fire(level, message)
+		}
+	}
+	return nil
+}
+
+// send sends message through the socket, or the next connection in the pool
+func (writer *SocketWriter) send(p []byte) {
+	writer.conn().write(p)
+}
+
+// conn return the socket, or the next connection in the pool
+func (writer *SocketWriter) conn() *socketConn {
+	if 0 == len(writer.pool) {
+		return writer.writer
+	}
+
+	return writer.pool[atomic.AddUint32(&writer.next, 1)%uint32(len(writer.pool))]
+}
+
+// SetConnectionPoolSize distributes messages across n buffered connections
+// in round-robin order, so that throughput scales with many goroutines
+// logging concurrently. Every connection redials by itself once
+// disconnected, messages are dropped while disconnected. It is supported
+// only on stream networks like tcp, as messages buffered can not be kept in
+// separate datagrams. n less than 2 sends messages through the socket only.
+func (writer *SocketWriter) SetConnectionPoolSize(n int) error {
+	if n > 1 && !streamNetwork(writer.network) {
+		return ErrNotSupported
+	}
+
+	var pool []*socketConn
+	for i := 0; i < n && n > 1; i++ {
+		c, err := dialSocketConn(writer.network, writer.address, true)
+		if nil != err {
+			for _, c := range pool {
+				c.close()
+			}
+			return err
+		}
+		pool = append(pool, c)
+	}
+
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if writer.closed {
+		for _, c := range pool {
+			c.close()
+		}
+		return ErrWriterClosed
+	}
+
+	for _, c := range writer.pool {
+		c.close()
+	}
+	for _, c := range pool {
+		if writer.offlineMax > 0 {
+			c.setOfflineBuffer(newOfflineBuffer(writer.offlineMax))
+		}
+	}
+	writer.pool = pool
+	return nil
+}
+
+// SetOfflineBuffer keeps at most maxEntries messages for every connection
+// while disconnected, the oldest one is dropped if full. Messages kept are
+// written first once reconnected, in chronological order. Messages buffered
+// in connection pool when the connection breaks are still lost. Not positive
+// maxEntries disables offline buffer and drops messages kept.
+func (writer *SocketWriter) SetOfflineBuffer(maxEntries int) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	if writer.closed {
+		return
+	}
+
+	writer.offlineMax = maxEntries
+	for _, c := range append([]*socketConn{writer.writer}, writer.pool...) {
+		if maxEntries > 0 {
+			c.setOfflineBuffer(newOfflineBuffer(maxEntries))
+		} else {
+			c.setOfflineBuffer(nil)
+		}
+	}
+}
+
+// BufferedCount get number of messages kept in offline buffer
+func (writer *SocketWriter) BufferedCount() (count int) {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+
+	if writer.closed {
+		return 0
+	}
+	for _, c := range append([]*socketConn{writer.writer}, writer.pool...) {
+		buffered, _ := c.offlineStats()
+		count += buffered
+	}
+	return count
+}
+
+// DroppedDueToBufferFull get number of messages dropped since offline buffer
+// full
+func (writer *SocketWriter) DroppedDueToBufferFull() (count int64) {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+
+	if writer.closed {
+		return 0
+	}
+	for _, c := range append([]*socketConn{writer.writer}, writer.pool...) {
+		_, dropped := c.offlineStats()
+		count += dropped
+	}
+	return count
 }
 
 // Level get level
@@ -197,6 +365,13 @@ func (writer *SocketWriter) SetColored(colored bool) {
 	return
 }
 
+// SetEOL set end of every message, messages are sent without EOL by default
+func (writer *SocketWriter) SetEOL(eol []byte) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.eol = append([]byte(nil), eol...)
+}
+
 // Close will close the writer
 func (writer *SocketWriter) Close() {
 	writer.lock.Lock()
@@ -205,14 +380,33 @@ func (writer *SocketWriter) Close() {
 		return
 	}
 
-	writer.writer.Close()
+	for _, c := range writer.pool {
+		c.close()
+	}
+	writer.pool = nil
+	writer.writer.close()
 	writer.writer = nil
 	writer.closed = true
 }
 
-// flush do nothing
+// PipeFromWithContext logs every line read from r with given level until r
+// reaches EOF or ctx is done
+func (writer *SocketWriter) PipeFromWithContext(ctx context.Context, r io.Reader, level LevelType) error {
+	if writer.closed {
+		return ErrWriterClosed
+	}
+
+	go pipe(ctx, writer, r, level)
+	return nil
+}
+
+// flush flushes buffer of connections in the pool
 func (writer *SocketWriter) flush() {
-	return
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	for _, c := range writer.pool {
+		c.flush()
+	}
 }
 
 // Trace trace
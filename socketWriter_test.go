@@ -3,6 +3,7 @@
 package blog4go
 
 import (
+	"bufio"
 	"fmt"
 	"net"
 	"strings"
@@ -178,3 +179,168 @@ func BenchmarkSocketWriter(b *testing.B) {
 		blog.Debugf("haha %s. en\\en, always %d and %f", "eddie", 18, 3.1415)
 	}
 }
+
+func TestSocketWriterConnectionPool(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatalf("listen failed. err: %s", err.Error())
+	}
+	defer listener.Close()
+
+	var lock sync.Mutex
+	var lines []string
+	conns := 0
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if nil != err {
+				return
+			}
+
+			lock.Lock()
+			conns++
+			lock.Unlock()
+			go func(conn net.Conn) {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					lock.Lock()
+					lines = append(lines, scanner.Text())
+					lock.Unlock()
+				}
+			}(conn)
+		}
+	}()
+
+	writer, err := newSocketWriter("tcp", listener.Addr().String())
+	if nil != err {
+		t.Fatalf("initialize socket writer failed. err: %s", err.Error())
+	}
+	blog = nil
+	writer.SetEOL(EOLUnix)
+	if err = writer.SetConnectionPoolSize(4); nil != err {
+		t.Fatalf("set connection pool size failed. err: %s", err.Error())
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				writer.Infof("goroutine %d message %d", i, j)
+			}
+		}(i)
+	}
+	wg.Wait()
+	writer.flush()
+
+	// broken connection redials in daemon
+	c := writer.pool[0]
+	c.lock.Lock()
+	c.conn.Close()
+	c.lock.Unlock()
+	c.write([]byte("dropped\n"))
+	c.flush()
+	time.Sleep(1500 * time.Millisecond)
+	c.lock.Lock()
+	if nil == c.conn {
+		t.Error("broken connection should be redialed")
+	}
+	c.lock.Unlock()
+
+	writer.Close()
+	if ErrWriterClosed != writer.SetConnectionPoolSize(2) {
+		t.Error("set connection pool size of closed writer should fail")
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	lock.Lock()
+	defer lock.Unlock()
+	if 100 != len(lines) || conns < 5 {
+		t.Errorf("messages sent through pool wrong. conns: %d, lines: %d", conns, len(lines))
+	}
+
+	udp, err := newSocketWriter("udp", "127.0.0.1:12124")
+	if nil != err {
+		t.Fatalf("initialize socket writer failed. err: %s", err.Error())
+	}
+	blog = nil
+	defer udp.Close()
+	if ErrNotSupported != udp.SetConnectionPoolSize(2) {
+		t.Error("connection pool should not be supported on udp")
+	}
+}
+
+func TestSocketWriterOfflineBuffer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatalf("listen failed. err: %s", err.Error())
+	}
+	defer listener.Close()
+
+	lines := make(chan string, 100)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if nil != err {
+				return
+			}
+
+			go func(conn net.Conn) {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					lines <- scanner.Text()
+				}
+			}(conn)
+		}
+	}()
+
+	writer, err := newSocketWriter("tcp", listener.Addr().String())
+	if nil != err {
+		t.Fatalf("initialize socket writer failed. err: %s", err.Error())
+	}
+	blog = nil
+	defer writer.Close()
+
+	initPrefix(false)
+	writer.SetEOL(EOLUnix)
+	writer.SetOfflineBuffer(2)
+
+	// disconnected
+	c := writer.writer
+	c.lock.Lock()
+	c.disconnect()
+	c.lock.Unlock()
+
+	writer.Info("dropped")
+	writer.Info("first")
+	writer.Info("second")
+	if 2 != writer.BufferedCount() || 1 != writer.DroppedDueToBufferFull() {
+		t.Errorf("offline buffer wrong. buffered: %d, dropped: %d", writer.BufferedCount(), writer.DroppedDueToBufferFull())
+	}
+
+	// wait for redialed
+	for i := 0; i < 30 && 0 != writer.BufferedCount(); i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	writer.Info("third")
+
+	var messages []string
+	for i := 0; i < 3; i++ {
+		select {
+		case line := <-lines:
+			messages = append(messages, line[strings.LastIndex(line, "] ")+2:])
+		case <-time.After(time.Second):
+		}
+	}
+	if "first,second,third" != strings.Join(messages, ",") {
+		t.Errorf("messages kept should be sent first once reconnected. messages: %v", messages)
+	}
+
+	writer.SetOfflineBuffer(0)
+	if 0 != writer.BufferedCount() || 0 != writer.DroppedDueToBufferFull() {
+		t.Error("offline buffer should be disabled")
+	}
+}
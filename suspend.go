@@ -0,0 +1,73 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrTimeout show that the operation is not done in time
+	ErrTimeout = errors.New("Timeout")
+	// ErrSuspended show that the writer is already suspended
+	ErrSuspended = errors.New("Writer has been already suspended")
+)
+
+// Suspend blocks writes until Resume, like while taking a backup of log
+// files. It returns after writes in progress complete and the buffer is
+// flushed.
+func (writer *baseFileWriter) Suspend() error {
+	return writer.suspend(nil)
+}
+
+// SuspendTimeout is Suspend, but ErrTimeout is returned if writes in
+// progress do not complete within d, writer is not suspended then
+func (writer *baseFileWriter) SuspendTimeout(d time.Duration) error {
+	return writer.suspend(time.After(d))
+}
+
+// suspend acquires the suspension exclusively, it waits forever if timeout
+// is nil
+func (writer *baseFileWriter) suspend(timeout <-chan time.Time) error {
+	writer.suspendLock.Lock()
+	defer writer.suspendLock.Unlock()
+
+	if writer.suspended {
+		return ErrSuspended
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		writer.suspension.Lock()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+	case <-timeout:
+		// release as soon as writes in progress complete
+		go func() {
+			<-acquired
+			writer.suspension.Unlock()
+		}()
+		return ErrTimeout
+	}
+
+	writer.blog.flush()
+	writer.suspended = true
+	return nil
+}
+
+// Resume unblocks writes blocked by Suspend, nothing is done if not
+// suspended
+func (writer *baseFileWriter) Resume() {
+	writer.suspendLock.Lock()
+	defer writer.suspendLock.Unlock()
+
+	if !writer.suspended {
+		return
+	}
+	writer.suspended = false
+	writer.suspension.Unlock()
+}
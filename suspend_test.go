@@ -0,0 +1,92 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBaseFileWriterSuspend(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/suspend.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/suspend.log")
+	}()
+
+	writer.Info("before suspend")
+	if err = writer.Suspend(); nil != err {
+		t.Fatalf("suspend failed. err: %s", err.Error())
+	}
+	if ErrSuspended != writer.Suspend() {
+		t.Error("suspend twice should fail")
+	}
+
+	// buffer is flushed when suspended
+	if content, err := ioutil.ReadFile("/tmp/suspend.log"); nil != err || !strings.HasSuffix(string(content), "] before suspend\n") {
+		t.Errorf("buffer should be flushed. content: %q", content)
+	}
+
+	written := make(chan struct{})
+	go func() {
+		writer.Infof("while %s", "suspended")
+		close(written)
+	}()
+
+	select {
+	case <-written:
+		t.Fatal("write should be blocked while suspended")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	writer.Resume()
+	select {
+	case <-written:
+	case <-time.After(time.Second):
+		t.Fatal("write should be unblocked after resume")
+	}
+
+	writer.flush()
+	if content, err := ioutil.ReadFile("/tmp/suspend.log"); nil != err || !strings.HasSuffix(string(content), "] while suspended\n") {
+		t.Errorf("message blocked should be written after resume. content: %q", content)
+	}
+}
+
+func TestBaseFileWriterSuspendTimeout(t *testing.T) {
+	writer, err := newBaseFileWriter("/tmp/suspend.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+		os.Remove("/tmp/suspend.log")
+	}()
+
+	// a write in progress
+	release := make(chan struct{})
+	locked := make(chan struct{})
+	go writer.WriteLocked(func(w io.Writer) {
+		close(locked)
+		<-release
+	})
+	<-locked
+
+	if ErrTimeout != writer.SuspendTimeout(10*time.Millisecond) {
+		t.Error("suspend should time out while write in progress")
+	}
+	close(release)
+
+	// writer is not suspended after time out
+	if err = writer.SuspendTimeout(time.Second); nil != err {
+		t.Errorf("suspend failed. err: %s", err.Error())
+	}
+	writer.Resume()
+	writer.Info("after resume")
+}
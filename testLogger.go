@@ -0,0 +1,53 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+const (
+	// TestKey is the key of field holding test name
+	TestKey = "test"
+
+	// TestLoggerCapacity is the number of the last messages kept by
+	// TestLogger
+	TestLoggerCapacity = 1000
+)
+
+// TestingT is the part of *testing.T used by TestLogger
+type TestingT interface {
+	Name() string
+	Failed() bool
+	Log(args ...interface{})
+}
+
+// TestLogger is a memory logger for tests, every message has the test name
+// appended as field, like "message test=TestFoo". Messages kept are passed
+// to t.Log by flush returned from ForTest if the test failed, so that they
+// appear along with the test output.
+type TestLogger struct {
+	*RingBufferWriter
+}
+
+// ForTest create a TestLogger for test t, not singlton. flush should be
+// deferred when the test starts, like
+//
+//	logger, flush := ForTest(t)
+//	defer flush()
+func ForTest(t TestingT) (logger *TestLogger, flush func()) {
+	ringBufferWriter, _ := newRingBufferWriter(TestLoggerCapacity)
+	logger = &TestLogger{RingBufferWriter: ringBufferWriter}
+
+	field := " " + TestKey + "=" + t.Name()
+	logger.AddMiddleware(func(level LevelType, message string) string {
+		return message + field
+	})
+
+	flush = func() {
+		if !t.Failed() {
+			return
+		}
+
+		for _, line := range logger.Lines() {
+			t.Log(line)
+		}
+	}
+	return logger, flush
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"strings"
+	"testing"
+)
+
+// fakeT records calls from TestLogger
+type fakeT struct {
+	failed bool
+	logs   []string
+}
+
+func (t *fakeT) Name() string {
+	return "TestFake"
+}
+
+func (t *fakeT) Failed() bool {
+	return t.failed
+}
+
+func (t *fakeT) Log(args ...interface{}) {
+	t.logs = append(t.logs, args[0].(string))
+}
+
+func TestTestLogger(t *testing.T) {
+	passed := new(fakeT)
+	logger, flush := ForTest(passed)
+	logger.Info("passed")
+	flush()
+	if 0 != len(passed.logs) {
+		t.Error("messages should not be logged if test passed")
+	}
+
+	failed := new(fakeT)
+	logger, flush = ForTest(failed)
+	logger.Infof("user %s", "tom")
+	logger.Error("failed")
+	failed.failed = true
+	flush()
+
+	if 2 != len(failed.logs) {
+		t.Fatalf("messages should be logged if test failed. logs: %v", failed.logs)
+	}
+	if !strings.HasSuffix(failed.logs[0], "] user tom test=TestFake") || !strings.HasSuffix(failed.logs[1], "] failed test=TestFake") {
+		t.Errorf("messages logged wrong. logs: %v", failed.logs)
+	}
+
+	// works with *testing.T
+	logger, flush = ForTest(t)
+	defer flush()
+	logger.Debug("debug")
+}
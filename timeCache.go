@@ -26,15 +26,22 @@ type timeFormatCacheType struct {
 	// yesterdate
 	dateYesterday string
 
+	// time zone and layout of formated time
+	location *time.Location
+	layout   string
+
 	// lock for read && write
 	lock *sync.RWMutex
 }
 
-// global time cache instance used for every log writer
+// global time cache instance, used by writers without a time cache of
+// their own
 var timeCache = timeFormatCacheType{}
 
 func init() {
 	timeCache.lock = new(sync.RWMutex)
+	timeCache.location = time.Local
+	timeCache.layout = PrefixTimeFormat
 	timeCache.now = time.Now()
 	timeCache.date = timeCache.now.Format(DateFormat)
 	timeCache.format = []byte(timeCache.now.Format(PrefixTimeFormat))
@@ -55,6 +62,15 @@ func init() {
 	}()
 }
 
+// newTimeFormatCache create a time cache in local time and
+// PrefixTimeFormat, it should be freshed by its owner every second
+func newTimeFormatCache() *timeFormatCacheType {
+	cache := new(timeFormatCacheType)
+	cache.lock = new(sync.RWMutex)
+	cache.setFormat(PrefixTimeFormat, time.Local)
+	return cache
+}
+
 // Now now
 func (timeCache *timeFormatCacheType) Now() time.Time {
 	timeCache.lock.RLock()
@@ -83,18 +99,49 @@ func (timeCache *timeFormatCacheType) Format() []byte {
 	return timeCache.format
 }
 
+// setFormat set layout and time zone of formated time, date is in the time
+// zone as well. Yesterdate is reset.
+func (timeCache *timeFormatCacheType) setFormat(layout string, location *time.Location) {
+	timeCache.lock.Lock()
+	defer timeCache.lock.Unlock()
+
+	timeCache.layout = layout
+	timeCache.location = location
+	now := time.Now().In(location)
+	timeCache.now = now
+	timeCache.format = []byte(now.Format(layout))
+	timeCache.date = now.Format(DateFormat)
+	timeCache.dateYesterday = now.Add(-24 * time.Hour).Format(DateFormat)
+}
+
 // fresh data in timeCache
 func (timeCache *timeFormatCacheType) fresh() {
 	timeCache.lock.Lock()
 	defer timeCache.lock.Unlock()
 
 	// get current time and update timeCache
-	now := time.Now()
+	now := time.Now().In(timeCache.location)
 	timeCache.now = now
-	timeCache.format = []byte(now.Format(PrefixTimeFormat))
+	timeCache.format = []byte(now.Format(timeCache.layout))
 	date := now.Format(DateFormat)
 	if date != timeCache.date {
 		timeCache.dateYesterday = timeCache.date
 		timeCache.date = now.Format(DateFormat)
 	}
 }
+
+// SetTimeFormat set layout and time zone of timestamps written by the file
+// writer, like SetTimeFormat(time.RFC3339, time.UTC). Date of time base
+// logrotate is in the time zone as well. Local time is used if location is
+// nil. Parser and ScanIntegrity only read timestamps in PrefixTimeFormat.
+func (writer *baseFileWriter) SetTimeFormat(layout string, location *time.Location) error {
+	if "" == layout {
+		return ErrInvalidFormat
+	}
+	if nil == location {
+		location = time.Local
+	}
+
+	writer.timeCache.setFormat(layout, location)
+	return nil
+}
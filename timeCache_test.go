@@ -3,6 +3,9 @@
 package blog4go
 
 import (
+	"io/ioutil"
+	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -37,3 +40,65 @@ func TestTimeCacheUpdate(t *testing.T) {
 		t.Error("time cache not correct when updated, dateYesterday wrong")
 	}
 }
+
+func TestWriterTimeFormat(t *testing.T) {
+	utc, err := newBaseFileWriter("/tmp/timeFormat.utc.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	zoned, err := newBaseFileWriter("/tmp/timeFormat.zoned.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		utc.Close()
+		zoned.Close()
+		os.Remove("/tmp/timeFormat.utc.log")
+		os.Remove("/tmp/timeFormat.zoned.log")
+	}()
+
+	if ErrInvalidFormat != utc.SetTimeFormat("", nil) {
+		t.Error("empty layout should fail")
+	}
+	utc.SetTimeFormat(time.RFC3339, time.UTC)
+	zoned.SetTimeFormat(time.RFC3339, time.FixedZone("CST", 8*3600))
+
+	// freshed by daemon
+	time.Sleep(1500 * time.Millisecond)
+	utc.Info("message")
+	zoned.Info("message")
+	utc.flush()
+	zoned.flush()
+
+	checks := []struct {
+		fileName string
+		offset   int
+	}{
+		{"/tmp/timeFormat.utc.log", 0},
+		{"/tmp/timeFormat.zoned.log", 8 * 3600},
+	}
+	for _, check := range checks {
+		data, err := ioutil.ReadFile(check.fileName)
+		if nil != err {
+			t.Fatalf("read log failed. err: %s", err.Error())
+		}
+
+		line := string(data)
+		timestamp := line[:strings.Index(line, " ")]
+		written, err := time.Parse(time.RFC3339, timestamp)
+		if nil != err {
+			t.Fatalf("parse timestamp failed. line: %s, err: %s", line, err.Error())
+		}
+		if _, offset := written.Zone(); check.offset != offset {
+			t.Errorf("time zone wrong. line: %s", line)
+		}
+		if d := time.Since(written); d < -time.Second || d > 3*time.Second {
+			t.Errorf("timestamp wrong. line: %s", line)
+		}
+	}
+
+	// other writers still use the global time cache
+	if &timeCache == utc.blog.timeCache || &timeCache != NewBLog(ioutil.Discard).timeCache {
+		t.Error("time caches of writers wrong")
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// writerBase implements methods of Writer shared by writers, embedded by
+// writer types. self is the writer embedding it, to which logging actions
+// are dispatched.
+// Writers override SetEOL and BeginShutdown when they support them.
+type writerBase struct {
+	self Writer
+}
+
+// SetEOL do nothing by default
+func (base *writerBase) SetEOL(eol []byte) {
+	return
+}
+
+// BeginShutdown do nothing by default
+func (base *writerBase) BeginShutdown() {
+	return
+}
+
+// Build return a LogBuilder of the message with level
+func (base *writerBase) Build(level LevelType) *LogBuilder {
+	return newLogBuilder(base.self, level)
+}
+
+// PipeFrom logs every line read from r with given level until r reaches EOF
+func (base *writerBase) PipeFrom(r io.Reader, level LevelType) error {
+	return base.self.PipeFromWithContext(context.Background(), r, level)
+}
+
+// writerWrapper implements methods of Writer shared by writers wrapping
+// another one. Methods not overridden are delegated to Writer, except
+// Build, PipeFrom and PipeFromWithContext, which dispatch to self so that
+// messages pass through the wrapper.
+type writerWrapper struct {
+	Writer
+	self Writer
+}
+
+// Build return a LogBuilder of the message with level
+func (wrapper *writerWrapper) Build(level LevelType) *LogBuilder {
+	return newLogBuilder(wrapper.self, level)
+}
+
+// PipeFrom logs every line read from r with given level until r reaches EOF
+func (wrapper *writerWrapper) PipeFrom(r io.Reader, level LevelType) error {
+	return wrapper.self.PipeFromWithContext(context.Background(), r, level)
+}
+
+// PipeFromWithContext logs every line read from r with given level until r
+// reaches EOF or ctx is done
+func (wrapper *writerWrapper) PipeFromWithContext(ctx context.Context, r io.Reader, level LevelType) error {
+	go pipe(ctx, wrapper.self, r, level)
+	return nil
+}
+
+// middlewareList holds middlewares applied to every message before written,
+// embedded by writers formatting messages themselves
+type middlewareList struct {
+	lock        sync.RWMutex
+	middlewares []Middleware
+}
+
+// AddMiddleware add a middleware applied to every message before written
+func (list *middlewareList) AddMiddleware(middleware Middleware) {
+	list.lock.Lock()
+	defer list.lock.Unlock()
+	list.middlewares = append(list.middlewares, middleware)
+}
+
+// applyMiddlewares applies middlewares to message in order
+func (list *middlewareList) applyMiddlewares(level LevelType, message string) string {
+	list.lock.RLock()
+	defer list.lock.RUnlock()
+	for _, middleware := range list.middlewares {
+		message = middleware(level, message)
+	}
+	return message
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriterBaseBuild(t *testing.T) {
+	writer, err := newRingBufferWriter(10)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	writer.AddMiddleware(func(level LevelType, message string) string {
+		return "[first] " + message
+	})
+	writer.AddMiddleware(func(level LevelType, message string) string {
+		return "[second] " + message
+	})
+
+	writer.Build(INFO).Str("user", "alice").Msg("login")
+
+	entries := writer.Entries()
+	if 1 != len(entries) {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	if "[second] [first] login user=alice" != entries[0].Message {
+		t.Errorf("middlewares not applied in order to built message: %q", entries[0].Message)
+	}
+}
+
+func TestWriterWrapperBuild(t *testing.T) {
+	writer, err := newRingBufferWriter(10)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	chain := NewWriterChain(writer).
+		Use(func(level LevelType, message string) (LevelType, string, bool) {
+			return level, strings.Replace(message, "secret", "******", -1), true
+		})
+
+	chain.Build(INFO).Str("token", "secret").Msg("login")
+	if err := chain.PipeFrom(strings.NewReader("piped secret\n"), INFO); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for len(writer.Entries()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	entries := writer.Entries()
+	if 2 != len(entries) {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	for _, entry := range entries {
+		if strings.Contains(entry.Message, "secret") {
+			t.Errorf("message bypassed the wrapper: %q", entry.Message)
+		}
+	}
+}
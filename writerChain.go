@@ -0,0 +1,202 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ChainFunc post-processes a message before written. It may transform the
+// level and the message, or return false to drop the message.
+type ChainFunc func(level LevelType, message string) (LevelType, string, bool)
+
+// WriterChain wraps a writer, every message goes through functions added by
+// Use in order before written to the writer. Messages transformed to a
+// level lower than logging level are dropped.
+type WriterChain struct {
+	writerWrapper
+
+	funcs []ChainFunc
+
+	lock *sync.RWMutex
+}
+
+// NewWriterChain create a WriterChain wrapping writer
+func NewWriterChain(writer Writer) *WriterChain {
+	chain := new(WriterChain)
+	chain.Writer = writer
+	chain.self = chain
+	chain.lock = new(sync.RWMutex)
+	return chain
+}
+
+// Use add fn to the end of the chain
+func (chain *WriterChain) Use(fn ChainFunc) *WriterChain {
+	chain.lock.Lock()
+	defer chain.lock.Unlock()
+	chain.funcs = append(chain.funcs, fn)
+	return chain
+}
+
+// Use add fn to the chain wrapping the singleton writer, do nothing if not initialized
+func Use(fn ChainFunc) {
+	singltonLock.Lock()
+	defer singltonLock.Unlock()
+
+	if nil == blog {
+		return
+	}
+
+	chain, ok := blog.(*WriterChain)
+	if !ok {
+		chain = NewWriterChain(blog)
+		blog = chain
+	}
+	chain.Use(fn)
+}
+
+// unwrap return the writer wrapped by chains
+func unwrap(writer Writer) Writer {
+	for {
+		chain, ok := writer.(*WriterChain)
+		if !ok {
+			return writer
+		}
+		writer = chain.Writer
+	}
+}
+
+// process passes message through the chain, false is returned if dropped
+func (chain *WriterChain) process(level LevelType, message string) (LevelType, string, bool) {
+	chain.lock.RLock()
+	defer chain.lock.RUnlock()
+
+	ok := true
+	for _, fn := range chain.funcs {
+		if level, message, ok = fn(level, message); !ok {
+			return level, message, false
+		}
+	}
+	return level, message, !(level < chain.Level())
+}
+
+func (chain *WriterChain) write(level LevelType, args ...interface{}) {
+	if level, message, ok := chain.process(level, fmt.Sprint(args...)); ok {
+		chain.Writer.write(level, message)
+	}
+}
+
+func (chain *WriterChain) writef(level LevelType, format string, args ...interface{}) {
+	if level, message, ok := chain.process(level, fmt.Sprintf(format, args...)); ok {
+		chain.Writer.write(level, message)
+	}
+}
+
+// Trace trace
+func (chain *WriterChain) Trace(args ...interface{}) {
+	if TRACE < chain.Level() {
+		return
+	}
+
+	chain.write(TRACE, args...)
+}
+
+// Tracef tracef
+func (chain *WriterChain) Tracef(format string, args ...interface{}) {
+	if TRACE < chain.Level() {
+		return
+	}
+
+	chain.writef(TRACE, format, args...)
+}
+
+// Debug debug
+func (chain *WriterChain) Debug(args ...interface{}) {
+	if DEBUG < chain.Level() {
+		return
+	}
+
+	chain.write(DEBUG, args...)
+}
+
+// Debugf debugf
+func (chain *WriterChain) Debugf(format string, args ...interface{}) {
+	if DEBUG < chain.Level() {
+		return
+	}
+
+	chain.writef(DEBUG, format, args...)
+}
+
+// Info info
+func (chain *WriterChain) Info(args ...interface{}) {
+	if INFO < chain.Level() {
+		return
+	}
+
+	chain.write(INFO, args...)
+}
+
+// Infof infof
+func (chain *WriterChain) Infof(format string, args ...interface{}) {
+	if INFO < chain.Level() {
+		return
+	}
+
+	chain.writef(INFO, format, args...)
+}
+
+// Warn warn
+func (chain *WriterChain) Warn(args ...interface{}) {
+	if WARNING < chain.Level() {
+		return
+	}
+
+	chain.write(WARNING, args...)
+}
+
+// Warnf warnf
+func (chain *WriterChain) Warnf(format string, args ...interface{}) {
+	if WARNING < chain.Level() {
+		return
+	}
+
+	chain.writef(WARNING, format, args...)
+}
+
+// Error error
+func (chain *WriterChain) Error(args ...interface{}) {
+	if ERROR < chain.Level() {
+		return
+	}
+
+	chain.write(ERROR, args...)
+}
+
+// Errorf errorf
+func (chain *WriterChain) Errorf(format string, args ...interface{}) {
+	if ERROR < chain.Level() {
+		return
+	}
+
+	chain.writef(ERROR, format, args...)
+}
+
+// Critical critical
+func (chain *WriterChain) Critical(args ...interface{}) {
+	if CRITICAL < chain.Level() {
+		return
+	}
+
+	chain.write(CRITICAL, args...)
+}
+
+// Criticalf criticalf
+func (chain *WriterChain) Criticalf(format string, args ...interface{}) {
+	if CRITICAL < chain.Level() {
+		return
+	}
+
+	chain.writef(CRITICAL, format, args...)
+}
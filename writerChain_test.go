@@ -0,0 +1,118 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriterChain(t *testing.T) {
+	writer, err := newRingBufferWriter(10)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	writer.SetLevel(INFO)
+
+	chain := NewWriterChain(writer).
+		Use(func(level LevelType, message string) (LevelType, string, bool) {
+			// drop health checks
+			return level, message, !strings.HasPrefix(message, "health")
+		}).
+		Use(func(level LevelType, message string) (LevelType, string, bool) {
+			// escalate timeouts
+			if strings.Contains(message, "timeout") {
+				return ERROR, message, true
+			}
+			return level, message, true
+		}).
+		Use(func(level LevelType, message string) (LevelType, string, bool) {
+			return level, strings.Replace(message, "secret", "******", -1), true
+		}).
+		Use(func(level LevelType, message string) (LevelType, string, bool) {
+			// demote noisy messages below logging level
+			if strings.Contains(message, "noisy") {
+				return DEBUG, message, true
+			}
+			return level, message, true
+		})
+
+	chain.Debug("debug")
+	chain.Info("health check ok")
+	chain.Infof("password is %s", "secret")
+	chain.Warnf("db %s", "timeout")
+	chain.Error("noisy error")
+	if err = chain.PipeFrom(strings.NewReader("piped secret\n"), WARNING); nil != err {
+		t.Errorf("pipe from failed. err: %s", err.Error())
+	}
+	// wait for pipe
+	time.Sleep(10 * time.Millisecond)
+
+	entries := writer.Entries()
+	if 3 != len(entries) {
+		t.Fatalf("chain wrote wrong messages. count: %d", len(entries))
+	}
+	if INFO != entries[0].Level || "password is ******" != entries[0].Message {
+		t.Errorf("message transformed wrong. entry: %+v", entries[0])
+	}
+	if ERROR != entries[1].Level || "db timeout" != entries[1].Message {
+		t.Errorf("level transformed wrong. entry: %+v", entries[1])
+	}
+	if WARNING != entries[2].Level || "piped ******" != entries[2].Message {
+		t.Errorf("piped message transformed wrong. entry: %+v", entries[2])
+	}
+
+	// settings are passed to the writer wrapped
+	chain.SetLevel(WARNING)
+	if WARNING != writer.Level() {
+		t.Error("level should be set to the writer wrapped")
+	}
+}
+
+func TestUseNotInitialized(t *testing.T) {
+	Use(func(level LevelType, message string) (LevelType, string, bool) {
+		return level, message, true
+	})
+	if nil != blog {
+		t.Error("singleton should not be installed when not initialized")
+	}
+}
+
+func TestUse(t *testing.T) {
+	err := NewBaseFileWriter("/tmp/chain.log", false)
+	defer func() {
+		Close()
+		os.Remove("/tmp/chain.log")
+	}()
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+
+	Use(func(level LevelType, message string) (LevelType, string, bool) {
+		return level, strings.ToUpper(message), true
+	})
+	Use(func(level LevelType, message string) (LevelType, string, bool) {
+		return level, message + "!", true
+	})
+
+	chain, ok := blog.(*WriterChain)
+	if !ok || 2 != len(chain.funcs) {
+		t.Fatal("singleton should be wrapped by one chain")
+	}
+	if 1 != len(fileWriters()) {
+		t.Error("file writers should be found through chain")
+	}
+
+	Info("hello")
+	Flush()
+	content, err := ioutil.ReadFile("/tmp/chain.log")
+	if nil != err {
+		t.Fatalf("read log failed. err: %s", err.Error())
+	}
+	if !strings.HasSuffix(string(content), "] HELLO!\n") {
+		t.Errorf("message should go through chain. content: %s", content)
+	}
+}